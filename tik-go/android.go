@@ -0,0 +1,153 @@
+package tik
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrAndroidInvalidName     = errors.New("invalid Android resource name")
+	ErrAndroidMultiplePlurals = errors.New(
+		"Android resources can't represent multiple cardinal pluralizations")
+	ErrAndroidUnsupported = errors.New("construct not representable in Android resources")
+)
+
+// AndroidTranslator is a reusable TIK to Android string resource translator.
+//
+// Placeholders are translated to explicit positional arguments (`%1$s`, `%2$d`)
+// in the order they appear in the TIK. A TIK without a cardinal pluralization
+// becomes a `<string>` element, a TIK with exactly one cardinal pluralization
+// becomes a `<plurals>` element with an `<item quantity="other">` scaffold.
+// The context becomes an XML comment preceding the element.
+type AndroidTranslator struct {
+	icu ICUTranslator
+	v   androidVisitor
+}
+
+func NewAndroidTranslator(conf Config) *AndroidTranslator {
+	return &AndroidTranslator{icu: ICUTranslator{conf: conf}}
+}
+
+// TIK2Android translates tik into a strings.xml resource element named name.
+// Returns ErrAndroidInvalidName if name isn't a valid resource name,
+// ErrAndroidMultiplePlurals if tik contains more than one cardinal
+// pluralization and ErrAndroidUnsupported for pluralization arms and
+// message references.
+func (a *AndroidTranslator) TIK2Android(tik TIK, name string) (string, error) {
+	if !isAndroidResourceName(name) {
+		return "", ErrAndroidInvalidName
+	}
+	a.v.b.Reset()
+	a.v.plurals = 0
+	if err := a.icu.Visit(tik, &a.v); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if c := tik.Context(); c != "" {
+		// "--" isn't allowed inside of XML comments.
+		b.WriteString("<!-- ")
+		b.WriteString(strings.ReplaceAll(c, "--", "- -"))
+		b.WriteString(" -->\n")
+	}
+	if a.v.plurals == 0 {
+		b.WriteString(`<string name="` + name + `">`)
+		b.WriteString(a.v.b.String())
+		b.WriteString("</string>")
+		return b.String(), nil
+	}
+	b.WriteString(`<plurals name="` + name + `">` + "\n")
+	b.WriteString(`    <item quantity="other">`)
+	b.WriteString(a.v.b.String())
+	b.WriteString("</item>\n</plurals>")
+	return b.String(), nil
+}
+
+// isAndroidResourceName returns true for `[a-zA-Z_][a-zA-Z0-9_.]*`.
+func isAndroidResourceName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case i > 0 && (c >= '0' && c <= '9' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+var replacerEscapeAndroid = strings.NewReplacer(
+	`\`, `\\`, `'`, `\'`, `"`, `\"`, "<", "&lt;", "&", "&amp;",
+	"\n", `\n`, "\t", `\t`, "%", "%%",
+)
+
+type androidVisitor struct {
+	b       strings.Builder
+	plurals int
+}
+
+func (v *androidVisitor) Literal(s string) error {
+	s = replacerEscapeAndroid.Replace(s)
+	if v.b.Len() == 0 && (strings.HasPrefix(s, "@") || strings.HasPrefix(s, "?")) {
+		// A leading @ or ? would make the value a resource reference.
+		v.b.WriteByte('\\')
+	}
+	v.b.WriteString(s)
+	return nil
+}
+
+func (v *androidVisitor) writeArgument(index int, verb byte) {
+	v.b.WriteString("%" + strconv.Itoa(index+1) + "$" + string(verb))
+}
+
+func (v *androidVisitor) Argument(index int, kind FormatKind) error {
+	verb, _, ok := printfVerb(kind)
+	if !ok {
+		return ErrAndroidUnsupported
+	}
+	v.writeArgument(index, verb)
+	return nil
+}
+
+func (v *androidVisitor) Currency(index int, _ string) error {
+	return v.Argument(index, FormatKindCurrency)
+}
+
+func (v *androidVisitor) Ordinal(index int, _ string) error {
+	return v.Argument(index, FormatKindOrdinal)
+}
+
+func (v *androidVisitor) Unit(index int, _ string) error {
+	return v.Argument(index, FormatKindUnit)
+}
+
+func (v *androidVisitor) NumberSkeleton(index int, _ string) error {
+	return v.Argument(index, FormatKindNumber)
+}
+
+func (v *androidVisitor) PluralStart(index int) error {
+	if v.plurals++; v.plurals > 1 {
+		return ErrAndroidMultiplePlurals
+	}
+	v.writeArgument(index, 'd')
+	return nil
+}
+
+func (v *androidVisitor) PluralOffset(int) error                 { return ErrAndroidUnsupported }
+func (v *androidVisitor) PluralExactMatch(int) error             { return ErrAndroidUnsupported }
+func (v *androidVisitor) PluralOther() error                     { return nil }
+func (v *androidVisitor) PluralEnd() error                       { return nil }
+func (v *androidVisitor) MessageRef(string) error                { return ErrAndroidUnsupported }
+func (v *androidVisitor) Select(int, string, []SelectCase) error { return ErrAndroidUnsupported }
+func (v *androidVisitor) RawICU(int, string) error               { return ErrAndroidUnsupported }
+
+// Tag writes the markup tag as is since string resources support
+// HTML-like styling markup.
+func (v *androidVisitor) Tag(_ int, name string, tag TokenType) error {
+	v.b.WriteString(markupTag(name, tag))
+	return nil
+}
@@ -0,0 +1,66 @@
+package tik_test
+
+import (
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestAndroidTranslator(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewAndroidTranslator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		actual, err := translator.TIK2Android(tk, "msg")
+		requireNoErr(t, err)
+		requireEqual(t, expect, actual)
+	}
+
+	f(t, `<string name="msg">hello world</string>`, `hello world`)
+	f(t, "<!-- greeting -->\n<string name=\"msg\">hello world</string>",
+		`[greeting] hello world`)
+	f(t, "<!-- a- -b -->\n<string name=\"msg\">text</string>", `[a--b] text`)
+	f(t, `<string name="msg">it\'s \"5 &lt; 6\" &amp; 100%% \\ ok\nnext</string>`,
+		"it's \"5 < 6\" & 100% \\\\ ok\nnext")
+	f(t, `<string name="msg">\@home and ?attr</string>`, `@home and ?attr`)
+	f(t, `<string name="msg">\?attr</string>`, `?attr`)
+	f(t, `<string name="msg">e-mail me@home</string>`, `e-mail me@home`)
+
+	f(t, `<string name="msg">%1$s and %2$s have %3$d items worth %4$s</string>`,
+		`{name} and {text} have {integer} items worth {currency}`)
+	f(t, `<string name="msg">%1$s %2$s %3$s %4$s %5$s %6$s %7$s %8$s %9$s %10$s</string>`,
+		`{number} {ordinal} {date-full} {date-long} {date-medium} {date-short} `+
+			`{time-full} {time-long} {time-medium} {time-short}`)
+
+	f(t, "<plurals name=\"msg\">\n"+
+		"    <item quantity=\"other\">%1$s had %2$d messages from %3$s.</item>\n"+
+		"</plurals>", `{name} had {# messages from {text}}.`)
+}
+
+func TestAndroidTranslatorErr(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewAndroidTranslator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect error, name, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		actual, err := translator.TIK2Android(tk, name)
+		requireErrIs(t, expect, err)
+		requireEqual(t, "", actual)
+	}
+
+	f(t, tik.ErrAndroidInvalidName, "", `text`)
+	f(t, tik.ErrAndroidInvalidName, "0msg", `text`)
+	f(t, tik.ErrAndroidInvalidName, "my-msg", `text`)
+	f(t, tik.ErrAndroidMultiplePlurals, "msg", `{# messages} in {# folders}`)
+	f(t, tik.ErrAndroidUnsupported, "msg", `{# |=0 none | messages}`)
+	f(t, tik.ErrAndroidUnsupported, "msg", `see {@help}`)
+}
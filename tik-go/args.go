@@ -0,0 +1,123 @@
+package tik
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrArgCount = errors.New("wrong number of arguments")
+	ErrArgType  = errors.New("wrong argument type")
+)
+
+// ArgError is returned by TIK.CheckArgs for an argument of the wrong type.
+type ArgError struct {
+	// Index is the position of the mismatching argument.
+	Index int
+
+	// Type is the type of the placeholder the argument belongs to.
+	Type TokenType
+
+	// Expect is the kind of value the placeholder requires, such as "integer".
+	Expect string
+
+	// Received is the Go type of the received value, such as "string".
+	Received string
+}
+
+func (e *ArgError) Error() string {
+	return fmt.Sprintf("%v: argument %d (%s) must be %s, received %s",
+		ErrArgType, e.Index, e.Type, e.Expect, e.Received)
+}
+
+func (e *ArgError) Unwrap() error { return ErrArgType }
+
+// CheckArgs returns an error if args don't match t.Args() in number and kind:
+//
+//   - text, name, string, phone, email, URL and enum select placeholders
+//     require a string.
+//   - integer, ordinal and spellout placeholders and cardinal plurals
+//     require an integer.
+//   - number, unit and permille placeholders require an integer or a float,
+//     number ranges require two.
+//   - date and time placeholders require a time.Time.
+//   - relative time placeholders require a time.Duration.
+//   - boolean placeholders require a bool.
+//   - currency, raw ICU and skip placeholders accept any value.
+//     The type of currency amounts depends on the formatting library,
+//     package render requires a currency.Amount.
+//
+// Returns ErrArgCount if the number of args doesn't match and
+// an *ArgError for the first argument of the wrong type.
+func (t TIK) CheckArgs(args ...any) error { return checkArgs(t.Args(), args) }
+
+// CheckArgs is similar to TIK.CheckArgs but checks args against
+// i.Args(t), which includes the gender arguments of text with gender
+// placeholders if Config.GenderSelect is enabled.
+// Gender arguments require a string.
+func (i *ICUTranslator) CheckArgs(t TIK, args ...any) error {
+	return checkArgs(i.Args(t), args)
+}
+
+func checkArgs(expect []Arg, args []any) error {
+	if len(args) != len(expect) {
+		return fmt.Errorf("%w: expected %d, received %d",
+			ErrArgCount, len(expect), len(args))
+	}
+	for _, a := range expect {
+		if kind, ok := checkArg(a.Type, args[a.Index]); !ok {
+			return &ArgError{
+				Index:    a.Index,
+				Type:     a.Type,
+				Expect:   kind,
+				Received: fmt.Sprintf("%T", args[a.Index]),
+			}
+		}
+	}
+	return nil
+}
+
+// checkArg returns the kind of value placeholders of type t require
+// and whether v is of that kind.
+func checkArg(t TokenType, v any) (kind string, ok bool) {
+	switch {
+	case t == TokenTypeBoolean:
+		_, ok = v.(bool)
+		return "bool", ok
+	case t == TokenTypeText, t == TokenTypeTextWithGender,
+		t == TokenTypeStringPlaceholder, t == TokenTypeSelect, t == TokenTypePhone,
+		t == TokenTypeEmail, t == TokenTypeURL, t.IsCustom():
+		_, ok = v.(string)
+		return "string", ok
+	case t == TokenTypeNumber, t == TokenTypeNumberRange, t == TokenTypeUnit,
+		t == TokenTypePermille, t == TokenTypeNumberSkeleton:
+		switch v.(type) {
+		case float32, float64:
+			ok = true
+		default:
+			ok = isInteger(v)
+		}
+		return "integer or float", ok
+	case t.IsCurrency(), t == TokenTypeRawICUArgument, t == TokenTypeSkip:
+		return "any", true
+	case t == TokenTypeRelativeTime:
+		_, ok = v.(time.Duration)
+		return "time.Duration", ok
+	case t.IsDate(), t.IsTime():
+		_, ok = v.(time.Time)
+		return "time.Time", ok
+	}
+	// Integer, ordinal, spellout and cardinal plural.
+	return "integer", isInteger(v)
+}
+
+// isInteger returns true if v is of an integer type.
+func isInteger(v any) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package tik_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestCheckArgs(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, input string, args ...any) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireNoErr(t, tk.CheckArgs(args...))
+	}
+
+	f(t, `hello world`)
+	f(t, `see {@help}`)
+	f(t, `{text} {name} {"John"} {select:status(a,b)}`, "a", "b", "c", "a")
+	f(t, `{integer} {ordinal} {spellout} {# items}`,
+		1, int8(2), uint64(3), int32(4))
+	f(t, `{number} {number}`, 1.5, 2)
+	f(t, `{currency} {currency:EUR}`, "$1", 2.5) // Any amount type.
+	f(t, `{date-full} {time-short} {time-zone}`,
+		time.Time{}, time.Time{}, time.Time{})
+	f(t, `Week {week-of-year} of {quarter}`, time.Time{}, time.Time{})
+	f(t, `{relative-time} {bool:on/off}`, -time.Hour, true)
+	f(t, `{# |=0 none | by {name}} {text}`, 0, "Alice", "Bob")
+}
+
+func TestCheckArgsErr(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect *tik.ArgError, expectMsg, input string, args ...any) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		err = tk.CheckArgs(args...)
+		requireEqual(t, expectMsg, err.Error())
+		if expect == nil {
+			requireErrIs(t, tik.ErrArgCount, err)
+			return
+		}
+		requireErrIs(t, tik.ErrArgType, err)
+		var actual *tik.ArgError
+		if !errors.As(err, &actual) {
+			t.Fatalf("expected *tik.ArgError, received: %#v", err)
+		}
+		requireDeepEqual(t, expect, actual)
+	}
+
+	f(t, nil, "wrong number of arguments: expected 2, received 1",
+		`{name} {integer}`, "Alice")
+	f(t, nil, "wrong number of arguments: expected 0, received 1",
+		`hello`, "Alice")
+	f(t, &tik.ArgError{
+		Index: 1, Type: tik.TokenTypeInteger, Expect: "integer", Received: "string",
+	}, "wrong argument type: argument 1 (integer) must be integer, received string",
+		`{name} {integer}`, "Alice", "5")
+	f(t, &tik.ArgError{
+		Index: 0, Type: tik.TokenTypeCardinalPluralStart,
+		Expect: "integer", Received: "float64",
+	}, "wrong argument type: argument 0 (pluralization) must be integer, received float64",
+		`{# items} {integer}`, 1.5, "x")
+	f(t, &tik.ArgError{
+		Index: 0, Type: tik.TokenTypeNumber,
+		Expect: "integer or float", Received: "string",
+	}, "wrong argument type: argument 0 (number) must be integer or float, received string",
+		`{number}`, "1.5")
+	f(t, &tik.ArgError{
+		Index: 0, Type: tik.TokenTypeRelativeTime,
+		Expect: "time.Duration", Received: "int",
+	}, "wrong argument type: argument 0 (relative time) must be time.Duration, received int",
+		`{relative-time}`, 5)
+	f(t, &tik.ArgError{
+		Index: 0, Type: tik.TokenTypeDateShort,
+		Expect: "time.Time", Received: "<nil>",
+	}, "wrong argument type: argument 0 (date short) must be time.Time, received <nil>",
+		`{date-short}`, nil)
+	f(t, &tik.ArgError{
+		Index: 0, Type: tik.TokenTypeSelect, Expect: "string", Received: "bool",
+	}, "wrong argument type: argument 0 (select) must be string, received bool",
+		`{select:status(a,b)}`, true)
+}
+
+func TestICUTranslatorCheckArgsGender(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.GenderSelect = true
+	tk, err := tik.NewParser(conf).Parse(`{name} met {text} and {name} {# times}`)
+	requireNoErr(t, err)
+	translator := tik.NewICUTranslator(conf)
+
+	args := translator.Args(tk)
+	requireDeepEqual(t, tk.Args(), args[:4])
+	requireEqual(t, 6, len(args))
+	for i, name := range []string{"var0_gender", "var2_gender"} {
+		a := args[4+i]
+		requireEqual(t, 4+i, a.Index)
+		requireEqual(t, tik.TokenTypeTextWithGender, a.Type)
+		requireEqual(t, tik.FormatKind(0), a.Kind)
+		requireEqual(t, true, a.Gender)
+		requireEqual(t, name, a.Name())
+	}
+
+	requireNoErr(t, translator.CheckArgs(tk, "Alice", "Bob", "Carol", 2, "female", "other"))
+	requireErrIs(t, tik.ErrArgCount, translator.CheckArgs(tk, "Alice", "Bob", "Carol", 2))
+	requireErrIs(t, tik.ErrArgType,
+		translator.CheckArgs(tk, "Alice", "Bob", "Carol", 2, "female", 1))
+
+	// Without GenderSelect there are no gender arguments.
+	requireNoErr(t, tik.NewICUTranslator(tik.DefaultConfig).CheckArgs(tk, "Alice", "Bob", "Carol", 2))
+	requireNoErr(t, tk.CheckArgs("Alice", "Bob", "Carol", 2))
+
+	conf.ICUVarPrefix = "arg"
+	args = tik.NewICUTranslator(conf).Args(tk)
+	for i, name := range []string{"arg0", "arg1", "arg2", "arg3", "arg0_gender", "arg2_gender"} {
+		requireEqual(t, name, args[i].Name())
+	}
+}
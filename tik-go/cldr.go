@@ -0,0 +1,66 @@
+package tik
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pluralCategoryNames lists all CLDR plural categories in canonical order.
+var pluralCategoryNames = [...]string{"zero", "one", "two", "few", "many", "other"}
+
+// SortPluralCategories sorts plural arm selectors in canonical order:
+// exact matches (=0, =1, ...) by value first, followed by
+// zero, one, two, few, many and other.
+// Unknown selectors are placed before other in lexical order.
+func SortPluralCategories(categories []string) {
+	rank := func(c string) (group int, value int) {
+		if v, ok := strings.CutPrefix(c, "="); ok {
+			n, _ := strconv.Atoi(v)
+			return 0, n
+		}
+		for i, name := range pluralCategoryNames {
+			if name == c {
+				if name == "other" {
+					return 3, 0
+				}
+				return 1, i
+			}
+		}
+		return 2, 0
+	}
+	slices.SortStableFunc(categories, func(a, b string) int {
+		ga, va := rank(a)
+		gb, vb := rank(b)
+		if c := cmp.Compare(ga, gb); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(va, vb); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+}
+
+// SortGenderCategories sorts gender arm selectors in canonical order:
+// female, male, followed by any other selectors in lexical order and other last.
+func SortGenderCategories(categories []string) {
+	rank := func(c string) int {
+		switch c {
+		case "female":
+			return 0
+		case "male":
+			return 1
+		case "other":
+			return 3
+		}
+		return 2
+	}
+	slices.SortStableFunc(categories, func(a, b string) int {
+		if c := cmp.Compare(rank(a), rank(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+}
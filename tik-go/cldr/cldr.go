@@ -0,0 +1,170 @@
+// Package cldr provides the CLDR plural categories and locale presets
+// of TIKs using golang.org/x/text.
+package cldr
+
+import (
+	"slices"
+	"sync"
+
+	tik "github.com/romshark/tik/tik-go"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+// pluralForms lists all CLDR plural forms in canonical order.
+var pluralForms = [...]struct {
+	form plural.Form
+	name string
+}{
+	{plural.Zero, "zero"},
+	{plural.One, "one"},
+	{plural.Two, "two"},
+	{plural.Few, "few"},
+	{plural.Many, "many"},
+	{plural.Other, "other"},
+}
+
+// pluralCategories returns the names of the CLDR plural categories
+// that rules define for locale in canonical order.
+// The categories are determined by sampling integers and decimals
+// since x/text doesn't expose the rule definitions.
+func pluralCategories(rules *plural.Rules, locale language.Tag) []string {
+	var found [len(pluralForms)]bool
+	mark := func(i, v, w, f int) {
+		found[rules.MatchPlural(locale, i, v, w, f, f)] = true
+	}
+	for i := range 1000 {
+		mark(i, 0, 0, 0)
+	}
+	mark(1_000_000, 0, 0, 0)
+	for i := range 20 {
+		mark(i, 1, 0, 0)
+		for f := 1; f < 10; f++ {
+			mark(i, 1, 1, f)
+		}
+	}
+
+	categories := make([]string, 0, len(pluralForms))
+	for _, f := range pluralForms {
+		if found[f.form] {
+			categories = append(categories, f.name)
+		}
+	}
+	return categories
+}
+
+// cardinalCategories caches the cardinal plural categories by locale
+// since sampling the rules is expensive.
+var cardinalCategories sync.Map // language.Tag -> []string
+
+// cardinal returns the cached cardinal plural categories of locale,
+// which must not be modified.
+func cardinal(locale language.Tag) []string {
+	if c, ok := cardinalCategories.Load(locale); ok {
+		return c.([]string)
+	}
+	c, _ := cardinalCategories.LoadOrStore(
+		locale, pluralCategories(plural.Cardinal, locale))
+	return c.([]string)
+}
+
+// PluralCategories returns the CLDR cardinal plural categories of locale
+// in canonical order, such as one, few, many and other for Polish.
+func PluralCategories(locale language.Tag) []string {
+	return slices.Clone(cardinal(locale))
+}
+
+// TIK2ICUForLocale is similar to tik.ICUTranslator.TIK2ICU but scaffolds
+// cardinal plurals with empty branches for every CLDR plural category
+// locale requires, such as
+// `{var0, plural, one {} few {} many {} other {# messages}}` for Polish.
+func TIK2ICUForLocale(i *tik.ICUTranslator, t tik.TIK, locale language.Tag) string {
+	return i.TIK2ICUWithPluralCategories(t, cardinal(locale))
+}
+
+// ExtraArmsNeeded returns for each of targets the CLDR plural categories,
+// in canonical order, that translators must supply in addition to the
+// `other` arm generated for the cardinal and ordinal plurals in t.
+// Since a TIK is always written in CLDR plural category `other`, any
+// category other than `other` is considered extra.
+// The result contains an entry for every target, which is empty if the TIK
+// has no plurals or the target only requires `other`.
+// Gender categories aren't covered since CLDR provides no data on them.
+func ExtraArmsNeeded(t tik.TIK, targets []language.Tag) map[language.Tag][]string {
+	var cardinalPlural, ordinalPlural bool
+	for _, tok := range t.Tokens {
+		switch tok.Type {
+		case tik.TokenTypeCardinalPluralStart:
+			cardinalPlural = true
+		case tik.TokenTypeOrdinalPlural:
+			ordinalPlural = true
+		}
+	}
+
+	m := make(map[language.Tag][]string, len(targets))
+	for _, target := range targets {
+		extra := []string{}
+		add := func(categories []string) {
+			for _, c := range categories {
+				if c != "other" && !slices.Contains(extra, c) {
+					extra = append(extra, c)
+				}
+			}
+		}
+		if cardinalPlural {
+			add(cardinal(target))
+		}
+		if ordinalPlural {
+			add(pluralCategories(plural.Ordinal, target))
+		}
+		tik.SortPluralCategories(extra)
+		m[target] = extra
+	}
+	return m
+}
+
+// RequiredPluralCategories maps the positional argument index of each
+// cardinal pluralization in t to the CLDR plural categories, in canonical
+// order and including `other`, that a translation into locale must cover,
+// such as one, few, many and other for Polish.
+// Returns nil if t has no cardinal pluralizations.
+func RequiredPluralCategories(t tik.TIK, locale language.Tag) map[int][]string {
+	var m map[int][]string
+	for i, p := range t.Placeholders() {
+		if p.Type != tik.TokenTypeCardinalPluralStart {
+			continue
+		}
+		if m == nil {
+			m = map[int][]string{}
+		}
+		m[i] = PluralCategories(locale)
+	}
+	return m
+}
+
+// ordinalSuffixes maps base languages to the suffix
+// of the `other` ordinal plural category.
+var ordinalSuffixes = map[language.Base]string{
+	language.MustParseBase("de"): ".",
+	language.MustParseBase("fr"): "e",
+	language.MustParseBase("es"): "º",
+	language.MustParseBase("it"): "º",
+	language.MustParseBase("pt"): "º",
+	language.MustParseBase("nl"): "e",
+	language.MustParseBase("ja"): "番目",
+}
+
+// ConfigForLocale returns a preset of tik.DefaultConfig for authors writing
+// source TIKs in the language of locale. The preset adjusts
+// OrdinalPluralOtherSuffix to the language's ordinal suffix
+// such as `4.` for German or `4e` for French, which is used for the
+// `other` category of `{ordinal}`.
+// Unsupported languages get tik.DefaultConfig.
+func ConfigForLocale(locale language.Tag) tik.Config {
+	c := tik.DefaultConfig
+	base, _ := locale.Base()
+	if s, ok := ordinalSuffixes[base]; ok {
+		c.OrdinalPluralOtherSuffix = s
+	}
+	return c
+}
@@ -0,0 +1,161 @@
+package cldr_test
+
+import (
+	"reflect"
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+	"github.com/romshark/tik/tik-go/cldr"
+	"golang.org/x/text/language"
+)
+
+func parse(t *testing.T, conf tik.Config, input string) tik.TIK {
+	t.Helper()
+	tk, err := tik.NewParser(conf).Parse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tk
+}
+
+func requireDeepEqual[T any](t *testing.T, expect, actual T) {
+	t.Helper()
+	if !reflect.DeepEqual(expect, actual) {
+		t.Fatalf("\nexpected: %#v;\nreceived: %#v", expect, actual)
+	}
+}
+
+func TestPluralCategories(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, expect []string, locale language.Tag) {
+		t.Helper()
+		requireDeepEqual(t, expect, cldr.PluralCategories(locale))
+	}
+
+	f(t, []string{"one", "other"}, language.English)
+	f(t, []string{"one", "few", "many", "other"}, language.Polish)
+	f(t, []string{"other"}, language.Japanese)
+	f(t, []string{"zero", "one", "two", "few", "many", "other"}, language.Arabic)
+
+	// The result is a copy.
+	c := cldr.PluralCategories(language.English)
+	c[0] = "x"
+	f(t, []string{"one", "other"}, language.English)
+}
+
+func TestTIK2ICUForLocale(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect, locale, input string) {
+		t.Helper()
+		tk := parse(t, tik.DefaultConfig, input)
+		actual := cldr.TIK2ICUForLocale(translator, tk, language.MustParse(locale))
+		requireDeepEqual(t, expect, actual)
+	}
+
+	f(t, "You have {var0, plural, one {} other {# messages}}",
+		"en", `You have {# messages}`)
+	f(t, "You have {var0, plural, one {} few {} many {} other {# messages}}",
+		"pl", `You have {# messages}`)
+	f(t, "You have {var0, plural, one {} few {} many {} other {# messages}}",
+		"ru", `You have {# messages}`)
+	f(t, "{var0, plural, other {#}}件のメッセージがあります。",
+		"ja", `{#}件のメッセージがあります。`)
+	f(t, "{var0, plural, zero {} one {} two {} few {} many {} other {#}}",
+		"ar", `{#}`)
+	f(t, "{var0} {var1, plural, one {} other {# files}} "+
+		"{var2, plural, one {} other {# folders}}",
+		"en", `{text} {# files} {# folders}`)
+
+	// TIK2ICU remains other-only.
+	tk := parse(t, tik.DefaultConfig, `You have {# messages}`)
+	requireDeepEqual(t, "You have {var0, plural, other {# messages}}",
+		translator.TIK2ICU(tk))
+}
+
+func TestExtraArmsNeeded(t *testing.T) {
+	t.Parallel()
+
+	en, pl, ja := language.English, language.Polish, language.Japanese
+	targets := []language.Tag{en, pl, ja}
+
+	f := func(t *testing.T, expect map[language.Tag][]string, input string) {
+		t.Helper()
+		tk := parse(t, tik.DefaultConfig, input)
+		requireDeepEqual(t, expect, cldr.ExtraArmsNeeded(tk, targets))
+	}
+
+	f(t, map[language.Tag][]string{
+		en: {}, pl: {}, ja: {},
+	}, `Hello {name}`)
+	f(t, map[language.Tag][]string{
+		en: {"one"},
+		pl: {"one", "few", "many"},
+		ja: {},
+	}, `You have {# messages}`)
+	f(t, map[language.Tag][]string{
+		en: {"one", "two", "few"},
+		pl: {},
+		ja: {},
+	}, `You're {ordinal}`)
+	f(t, map[language.Tag][]string{
+		en: {"one", "two", "few"},
+		pl: {"one", "few", "many"},
+		ja: {},
+	}, `{ordinal} of {# contenders}`)
+}
+
+func TestRequiredPluralCategories(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, expect map[int][]string, locale language.Tag, input string) {
+		t.Helper()
+		tk := parse(t, tik.DefaultConfig, input)
+		requireDeepEqual(t, expect, cldr.RequiredPluralCategories(tk, locale))
+	}
+
+	f(t, nil, language.English, `Hello {name}, you're {ordinal}`)
+	f(t, map[int][]string{1: {"one", "other"}},
+		language.English, `{name} has {# messages}`)
+	f(t, map[int][]string{1: {"one", "few", "many", "other"}},
+		language.Polish, `{name} has {# messages}`)
+	f(t, map[int][]string{1: {"other"}},
+		language.Japanese, `{name} has {# messages}`)
+	f(t, map[int][]string{0: {"zero", "one", "two", "few", "many", "other"}},
+		language.Arabic, `{# messages}`)
+
+	// Multiple pluralizations are keyed by their positional argument index.
+	conf := tik.DefaultConfig
+	conf.AllowNestedPlural = true
+	tk := parse(t, conf, `{number-range} {# files in {# folders}} by {text}`)
+	requireDeepEqual(t, map[int][]string{
+		2: {"one", "few", "many", "other"},
+		3: {"one", "few", "many", "other"},
+	}, cldr.RequiredPluralCategories(tk, language.Polish))
+}
+
+func TestConfigForLocale(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, expectICU string, locale language.Tag) {
+		t.Helper()
+		c := cldr.ConfigForLocale(locale)
+		if err := c.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		tk := parse(t, c, `{ordinal}`)
+		requireDeepEqual(t, expectICU, tik.NewICUTranslator(c).TIK2ICU(tk))
+	}
+
+	f(t, "{var0, selectordinal, other {#th}}", language.English)
+	f(t, "{var0, selectordinal, other {#th}}", language.Und)
+	f(t, "{var0, selectordinal, other {#.}}", language.German)
+	f(t, "{var0, selectordinal, other {#.}}", language.MustParse("de-CH"))
+	f(t, "{var0, selectordinal, other {#e}}", language.French)
+	f(t, "{var0, selectordinal, other {#º}}", language.Spanish)
+	f(t, "{var0, selectordinal, other {#番目}}", language.Japanese)
+	requireDeepEqual(t, tik.DefaultConfig, cldr.ConfigForLocale(language.Korean))
+}
@@ -0,0 +1,47 @@
+package tik_test
+
+import (
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestSortPluralCategories(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, expect, input []string) {
+		t.Helper()
+		tik.SortPluralCategories(input)
+		requireDeepEqual(t, expect, input)
+	}
+
+	f(t, []string{}, []string{})
+	f(t, []string{"one", "other"}, []string{"other", "one"})
+	f(t,
+		[]string{"zero", "one", "two", "few", "many", "other"},
+		[]string{"other", "many", "two", "zero", "few", "one"})
+	f(t,
+		[]string{"=0", "=1", "=10", "one", "few", "other"},
+		[]string{"few", "=10", "other", "=1", "one", "=0"})
+	f(t,
+		[]string{"one", "a", "b", "other"},
+		[]string{"b", "other", "a", "one"})
+}
+
+func TestSortGenderCategories(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, expect, input []string) {
+		t.Helper()
+		tik.SortGenderCategories(input)
+		requireDeepEqual(t, expect, input)
+	}
+
+	f(t, []string{}, []string{})
+	f(t,
+		[]string{"female", "male", "other"},
+		[]string{"other", "male", "female"})
+	f(t,
+		[]string{"female", "male", "animate", "neuter", "other"},
+		[]string{"neuter", "other", "male", "animate", "female"})
+}
@@ -0,0 +1,143 @@
+package tik
+
+import "slices"
+
+// ConstraintKind is a structural feature of a TIK that some
+// output formats can't represent. ICU and XLIFF represent all of them.
+type ConstraintKind int8
+
+const (
+	_ ConstraintKind = iota
+
+	// ConstraintMultiplePlurals is more than one cardinal pluralization,
+	// which isn't representable in PO, Android, stringsdict,
+	// Fluent and i18next.
+	ConstraintMultiplePlurals
+
+	// ConstraintNestedPlural is a cardinal pluralization inside of another
+	// enabled by Config.AllowNestedPlural, which additionally
+	// isn't representable in MF2.
+	ConstraintNestedPlural
+
+	// ConstraintPluralOffset is a cardinal pluralization offset,
+	// which isn't representable in PO, Android, stringsdict, Fluent,
+	// i18next and MF2.
+	ConstraintPluralOffset
+
+	// ConstraintPluralExactMatch is an exact match arm of
+	// a cardinal pluralization, which isn't representable in PO, Android,
+	// stringsdict and i18next.
+	ConstraintPluralExactMatch
+
+	// ConstraintSelect is a boolean or an enum select, which isn't
+	// representable in PO, Android, stringsdict and i18next,
+	// nor in MF2 inside of a cardinal pluralization.
+	ConstraintSelect
+
+	// ConstraintMessageRef is a message reference, which isn't
+	// representable in PO, Android, stringsdict and MF2,
+	// nor in Fluent if the key isn't a Fluent message or attribute.
+	ConstraintMessageRef
+
+	// ConstraintRawICU is raw ICU, which is only representable in ICU.
+	ConstraintRawICU
+)
+
+func (k ConstraintKind) String() string {
+	switch k {
+	case ConstraintMultiplePlurals:
+		return "multiple cardinal pluralizations"
+	case ConstraintNestedPlural:
+		return "nested cardinal pluralization"
+	case ConstraintPluralOffset:
+		return "cardinal pluralization offset"
+	case ConstraintPluralExactMatch:
+		return "cardinal pluralization exact match"
+	case ConstraintSelect:
+		return "select"
+	case ConstraintMessageRef:
+		return "message reference"
+	case ConstraintRawICU:
+		return "raw ICU"
+	}
+	return "unknown"
+}
+
+// Constraint is a structural feature of a TIK reported by TIK.Constraints.
+type Constraint struct {
+	Kind ConstraintKind
+
+	// Tokens are the ascending indexes of the involved tokens in TIK.Tokens.
+	// For ConstraintMultiplePlurals these are all cardinal plural starts
+	// and for ConstraintNestedPlural the starts of the nested
+	// pluralizations and of the pluralizations enclosing them.
+	Tokens []int
+}
+
+// Constraints returns the structural features of t that some output formats
+// can't represent, ordered by kind, such that exporters can decide up front
+// whether the structure of t is expressible in a format.
+// Returns nil if t has none of these features.
+//
+// Constraints doesn't report placeholders a format has no formatting for,
+// which the translator of the format rejects: ordinals in i18next,
+// number skeletons in Fluent, MF2 and i18next, relative times, spellouts,
+// weeks of the year, quarters and permille in Fluent, MF2 and i18next,
+// number ranges in PO, Android and stringsdict, and ordinals inside
+// of cardinal pluralizations in MF2.
+func (t TIK) Constraints() []Constraint {
+	var (
+		plurals, nested, offsets, exact []int
+		selects, refs, raw              []int
+		open                            []int // Open cardinal plural starts.
+	)
+	for i, tok := range t.Tokens {
+		switch tok.Type {
+		case TokenTypeCardinalPluralStart:
+			plurals = append(plurals, i)
+			if len(open) > 0 {
+				nested = append(nested, open...)
+				nested = append(nested, i)
+			}
+			open = append(open, i)
+		case TokenTypeCardinalPluralEnd:
+			open = open[:len(open)-1]
+		case TokenTypePluralOffset:
+			offsets = append(offsets, i)
+		case TokenTypePluralExactMatch:
+			exact = append(exact, i)
+		case TokenTypeBoolean, TokenTypeSelect:
+			selects = append(selects, i)
+		case TokenTypeMessageRef:
+			refs = append(refs, i)
+		case TokenTypeRawICU, TokenTypeRawICUArgument:
+			raw = append(raw, i)
+		}
+	}
+	if len(nested) > 0 {
+		slices.Sort(nested)
+		nested = slices.Compact(nested)
+	}
+	if len(plurals) < 2 {
+		plurals = nil
+	}
+
+	var c []Constraint
+	for _, x := range [...]struct {
+		kind   ConstraintKind
+		tokens []int
+	}{
+		{ConstraintMultiplePlurals, plurals},
+		{ConstraintNestedPlural, nested},
+		{ConstraintPluralOffset, offsets},
+		{ConstraintPluralExactMatch, exact},
+		{ConstraintSelect, selects},
+		{ConstraintMessageRef, refs},
+		{ConstraintRawICU, raw},
+	} {
+		if x.tokens != nil {
+			c = append(c, Constraint{Kind: x.kind, Tokens: x.tokens})
+		}
+	}
+	return c
+}
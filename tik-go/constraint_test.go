@@ -0,0 +1,68 @@
+package tik_test
+
+import (
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestTIKConstraints(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.AllowNestedPlural = true
+	p := tik.NewParser(conf)
+
+	f := func(t *testing.T, expect []tik.Constraint, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireDeepEqual(t, expect, tk.Constraints())
+	}
+
+	f(t, nil, `hello {text} on {date-short}`)
+	f(t, nil, `[context] {name} had {# messages}`)
+	// Placeholders without formatting in some formats aren't structural.
+	f(t, nil, `{ordinal} {relative-time} {number::.00} {number-range}`)
+
+	f(t, []tik.Constraint{
+		{Kind: tik.ConstraintMultiplePlurals, Tokens: []int{0, 4}},
+	}, `{# files} in {# folders}`)
+
+	f(t, []tik.Constraint{
+		{Kind: tik.ConstraintMultiplePlurals, Tokens: []int{0, 2}},
+		{Kind: tik.ConstraintNestedPlural, Tokens: []int{0, 2}},
+	}, `{# x {# y}}`)
+
+	f(t, []tik.Constraint{
+		{Kind: tik.ConstraintMultiplePlurals, Tokens: []int{0, 2, 4, 10}},
+		{Kind: tik.ConstraintNestedPlural, Tokens: []int{0, 2, 4}},
+	}, `{# a {# b {# c}}} and {# d}`)
+
+	f(t, []tik.Constraint{
+		{Kind: tik.ConstraintPluralOffset, Tokens: []int{2}},
+		{Kind: tik.ConstraintPluralExactMatch, Tokens: []int{3}},
+		{Kind: tik.ConstraintSelect, Tokens: []int{7, 12}},
+		{Kind: tik.ConstraintMessageRef, Tokens: []int{10}},
+		{Kind: tik.ConstraintRawICU, Tokens: []int{14}},
+	}, `[c] {# offset:1 |=0 none | others {bool:on/off}} see {@help} `+
+		`{select:status(open,closed)} {icu:{var, number, ::percent}}`)
+}
+
+func TestConstraintKindString(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, expect string, k tik.ConstraintKind) {
+		t.Helper()
+		requireEqual(t, expect, k.String())
+	}
+
+	f(t, "unknown", 0)
+	f(t, "multiple cardinal pluralizations", tik.ConstraintMultiplePlurals)
+	f(t, "nested cardinal pluralization", tik.ConstraintNestedPlural)
+	f(t, "cardinal pluralization offset", tik.ConstraintPluralOffset)
+	f(t, "cardinal pluralization exact match", tik.ConstraintPluralExactMatch)
+	f(t, "select", tik.ConstraintSelect)
+	f(t, "message reference", tik.ConstraintMessageRef)
+	f(t, "raw ICU", tik.ConstraintRawICU)
+}
@@ -0,0 +1,151 @@
+package tik
+
+// ChangeOp is the operation of a Change.
+type ChangeOp int8
+
+const (
+	_ ChangeOp = iota
+
+	ChangeOpEqual
+	ChangeOpInsert
+	ChangeOpDelete
+	ChangeOpReplace
+)
+
+func (o ChangeOp) String() string {
+	switch o {
+	case ChangeOpEqual:
+		return "equal"
+	case ChangeOpInsert:
+		return "insert"
+	case ChangeOpDelete:
+		return "delete"
+	case ChangeOpReplace:
+		return "replace"
+	}
+	return "unknown"
+}
+
+// Change is a difference between two TIKs computed by Diff.
+type Change struct {
+	Op ChangeOp
+
+	// A are the affected tokens of the first TIK, nil for ChangeOpInsert.
+	A Tokens
+
+	// B are the affected tokens of the second TIK, nil for ChangeOpDelete.
+	// For ChangeOpEqual, A and B are equal but may differ in their raw
+	// source, such as in whitespace and translator hints.
+	B Tokens
+
+	// Breaking is true if the change adds, removes or retypes a placeholder
+	// or otherwise changes the placeholder structure, which makes
+	// existing translations stale. Changes of literals and the context
+	// aren't breaking.
+	Breaking bool
+}
+
+// Diff compares a and b token by token and returns the sequence of changes
+// turning a into b. Literals are compared the same way TIK.Equal does
+// and translator hints are ignored. A differing context is reported as
+// a leading non-breaking ChangeOpReplace, ChangeOpInsert or ChangeOpDelete.
+// Runs of equal tokens are reported as a single ChangeOpEqual
+// and adjacent deletions and insertions as a single ChangeOpReplace.
+// Diff returns no changes if a.Equal(b).
+func Diff(a, b TIK) []Change {
+	var changes []Change
+	if ca, cb := a.Context(), b.Context(); ca != cb {
+		c := Change{Op: ChangeOpReplace}
+		if ca != "" {
+			c.A = a.Tokens[:1]
+		} else {
+			c.Op = ChangeOpInsert
+		}
+		if cb != "" {
+			c.B = b.Tokens[:1]
+		} else {
+			c.Op = ChangeOpDelete
+		}
+		changes = append(changes, c)
+	}
+
+	na, ia := a.normalized()
+	nb, ib := b.normalized()
+
+	// lcs[i][j] is the length of the longest common subsequence
+	// of na[i:] and nb[j:].
+	lcs := make([][]int, len(na)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(nb)+1)
+	}
+	for i := len(na) - 1; i >= 0; i-- {
+		for j := len(nb) - 1; j >= 0; j-- {
+			if na[i] == nb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var equal, equalB, deleted, inserted Tokens
+	flush := func() {
+		switch {
+		case equal != nil:
+			changes = append(changes, Change{Op: ChangeOpEqual, A: equal, B: equalB})
+		case deleted != nil && inserted != nil:
+			changes = append(changes, Change{
+				Op: ChangeOpReplace, A: deleted, B: inserted,
+				Breaking: !onlyLiterals(deleted) || !onlyLiterals(inserted),
+			})
+		case deleted != nil:
+			changes = append(changes, Change{
+				Op: ChangeOpDelete, A: deleted, Breaking: !onlyLiterals(deleted),
+			})
+		case inserted != nil:
+			changes = append(changes, Change{
+				Op: ChangeOpInsert, B: inserted, Breaking: !onlyLiterals(inserted),
+			})
+		}
+		equal, equalB, deleted, inserted = nil, nil, nil, nil
+	}
+	for i, j := 0, 0; i < len(na) || j < len(nb); {
+		switch {
+		case i < len(na) && j < len(nb) && na[i] == nb[j]:
+			if equal == nil {
+				flush()
+			}
+			equal = append(equal, a.Tokens[ia[i]])
+			equalB = append(equalB, b.Tokens[ib[j]])
+			i, j = i+1, j+1
+		case j < len(nb) && (i == len(na) || lcs[i][j+1] >= lcs[i+1][j]):
+			if equal != nil {
+				flush()
+			}
+			inserted = append(inserted, b.Tokens[ib[j]])
+			j++
+		default:
+			if equal != nil {
+				flush()
+			}
+			deleted = append(deleted, a.Tokens[ia[i]])
+			i++
+		}
+	}
+	flush()
+
+	if len(changes) == 1 && changes[0].Op == ChangeOpEqual {
+		return nil
+	}
+	return changes
+}
+
+// onlyLiterals returns true if all tokens are literals.
+func onlyLiterals(tokens Tokens) bool {
+	for _, t := range tokens {
+		if t.Type != TokenTypeLiteral {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package tik_test
+
+import (
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	type change struct {
+		Op       tik.ChangeOp
+		A, B     []string
+		Breaking bool
+	}
+
+	f := func(t *testing.T, expect []change, a, b string) {
+		t.Helper()
+		ta, err := tik.NewParser(tik.DefaultConfig).Parse(a)
+		requireNoErr(t, err)
+		tb, err := tik.NewParser(tik.DefaultConfig).Parse(b)
+		requireNoErr(t, err)
+		var actual []change
+		for _, c := range tik.Diff(ta, tb) {
+			var ac change
+			ac.Op, ac.Breaking = c.Op, c.Breaking
+			for _, tok := range c.A {
+				ac.A = append(ac.A, ta.Raw[tok.IndexStart:tok.IndexEnd])
+			}
+			for _, tok := range c.B {
+				ac.B = append(ac.B, tb.Raw[tok.IndexStart:tok.IndexEnd])
+			}
+			actual = append(actual, ac)
+		}
+		requireDeepEqual(t, expect, actual)
+	}
+
+	// Equal.
+	f(t, nil, `hello {text}`, `hello {text}`)
+	f(t, nil, "hello\r\n{text}  ", "hello\n{text}")
+
+	// Adding a placeholder.
+	f(t, []change{
+		{
+			Op: tik.ChangeOpEqual,
+			A:  []string{"hello ", "{text}"},
+			B:  []string{"hello ", "{text}"},
+		},
+		{Op: tik.ChangeOpInsert, B: []string{" on ", "{date-short}"}, Breaking: true},
+	}, `hello {text}`, `hello {text} on {date-short}`)
+
+	// Removing a placeholder.
+	f(t, []change{
+		{
+			Op: tik.ChangeOpEqual,
+			A:  []string{"hello ", "{text}"},
+			B:  []string{"hello ", "{text}"},
+		},
+		{Op: tik.ChangeOpDelete, A: []string{" and ", "{name}"}, Breaking: true},
+	}, `hello {text} and {name}`, `hello {text}`)
+
+	// Retyping a placeholder.
+	f(t, []change{
+		{Op: tik.ChangeOpEqual, A: []string{"hello "}, B: []string{"hello "}},
+		{
+			Op: tik.ChangeOpReplace, A: []string{"{text}"}, B: []string{"{name}"},
+			Breaking: true,
+		},
+	}, `hello {text}`, `hello {name}`)
+
+	// Rewording a literal.
+	f(t, []change{
+		{Op: tik.ChangeOpReplace, A: []string{"hello "}, B: []string{"hi "}},
+		{Op: tik.ChangeOpEqual, A: []string{"{text}", "!"}, B: []string{"{text}", "!"}},
+	}, `hello {text}!`, `hi {text}!`)
+
+	// Swapping two placeholders.
+	f(t, []change{
+		{Op: tik.ChangeOpInsert, B: []string{"{integer}", " and "}, Breaking: true},
+		{Op: tik.ChangeOpEqual, A: []string{"{text}"}, B: []string{"{text}"}},
+		{Op: tik.ChangeOpDelete, A: []string{" and ", "{integer}"}, Breaking: true},
+	}, `{text} and {integer}`, `{integer} and {text}`)
+
+	// Changing the context.
+	f(t, []change{
+		{Op: tik.ChangeOpReplace, A: []string{"[a]"}, B: []string{"[b]"}},
+		{Op: tik.ChangeOpEqual, A: []string{"hello"}, B: []string{"hello"}},
+	}, `[a] hello`, `[b] hello`)
+	f(t, []change{
+		{Op: tik.ChangeOpInsert, B: []string{"[b]"}},
+		{Op: tik.ChangeOpEqual, A: []string{"hello"}, B: []string{"hello"}},
+	}, `hello`, `[b] hello`)
+
+	// Equal tokens differing in their hints.
+	f(t, []change{
+		{Op: tik.ChangeOpReplace, A: []string{"hi "}, B: []string{"hello "}},
+		{Op: tik.ChangeOpEqual, A: []string{"{text # a}"}, B: []string{"{text # b}"}},
+	}, `hi {text # a}`, `hello {text # b}`)
+}
+
+func TestChangeOp_String(t *testing.T) {
+	t.Parallel()
+
+	requireEqual(t, "equal", tik.ChangeOpEqual.String())
+	requireEqual(t, "insert", tik.ChangeOpInsert.String())
+	requireEqual(t, "delete", tik.ChangeOpDelete.String())
+	requireEqual(t, "replace", tik.ChangeOpReplace.String())
+	requireEqual(t, "unknown", tik.ChangeOp(0).String())
+}
@@ -2,4 +2,5 @@
 // (see https://github.com/romshark/tik), as well as the TIK to ICU message translator.
 //
 // [Parser] and [ICUTranslator] are reusable but not safe for concurrent use.
+// Use [ParserPool] to parse concurrently.
 package tik
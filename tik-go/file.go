@@ -0,0 +1,57 @@
+package tik
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LineError is an error of a line of input to ParseFile.
+type LineError struct {
+	// Line is the 1-based line number.
+	Line int
+
+	// Err is the ParseError of the line or the error of the reader.
+	Err error
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e LineError) Unwrap() error { return e.Err }
+
+// ParseFile parses the line-delimited TIKs read from r.
+// Blank lines and comment lines starting with `//` are skipped,
+// a trailing carriage return of a line is ignored.
+// Returns the TIKs of all valid lines in order and a LineError for
+// every invalid line. If reading from r fails, the last LineError
+// holds the error of the reader and the number of the line
+// that failed to be read.
+func ParseFile(r io.Reader, conf Config) ([]TIK, []LineError) {
+	p := NewParser(conf)
+	br := bufio.NewReader(r)
+	var tiks []TIK
+	var errs []LineError
+	for line := 1; ; line++ {
+		s, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return tiks, append(errs, LineError{Line: line, Err: err})
+		}
+		s = strings.TrimSuffix(strings.TrimSuffix(s, "\n"), "\r")
+		if trimmed := strings.TrimSpace(s); trimmed != "" &&
+			!strings.HasPrefix(trimmed, "//") {
+			t, errParse := p.Parse(s)
+			if errParse != nil {
+				errs = append(errs, LineError{Line: line, Err: errParse})
+			} else {
+				tiks = append(tiks, t)
+			}
+		}
+		if err != nil {
+			return tiks, errs
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package tik_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestParseFile(t *testing.T) {
+	t.Parallel()
+
+	tiks, errs := tik.ParseFile(strings.NewReader(`// Inbox messages.
+[inbox] Hello {name}
+
+  // Indented comment.
+You have {# messages}
+Unknown {placeholder}
+	
+Sent on {date-short}`+"\r\n"+`Trailing {text}`), tik.DefaultConfig)
+
+	raw := make([]string, len(tiks))
+	for i, tk := range tiks {
+		raw[i] = tk.Raw
+	}
+	requireDeepEqual(t, []string{
+		"[inbox] Hello {name}",
+		"You have {# messages}",
+		"Sent on {date-short}",
+		"Trailing {text}",
+	}, raw)
+	requireDeepEqual(t, []tik.LineError{{
+		Line: 6, Err: tik.ParseError{Index: 8, Err: tik.ErrUnknownPlaceholder},
+	}}, errs)
+	requireErrIs(t, tik.ErrUnknownPlaceholder, errs[0])
+	requireEqual(t, "line 6: at index 8: unknown placeholder", errs[0].Error())
+}
+
+func TestParseFileEmpty(t *testing.T) {
+	t.Parallel()
+
+	tiks, errs := tik.ParseFile(strings.NewReader("\n// Nothing.\n\n"), tik.DefaultConfig)
+	requireDeepEqual(t, []tik.TIK(nil), tiks)
+	requireDeepEqual(t, []tik.LineError(nil), errs)
+}
+
+func TestParseFileErrReader(t *testing.T) {
+	t.Parallel()
+
+	errRead := errors.New("read failed")
+	tiks, errs := tik.ParseFile(io.MultiReader(
+		strings.NewReader("first {text}\nsecond {text}\n"),
+		iotest.ErrReader(errRead),
+	), tik.DefaultConfig)
+	requireEqual(t, 2, len(tiks))
+	requireDeepEqual(t, []tik.LineError{{Line: 3, Err: errRead}}, errs)
+}
@@ -0,0 +1,236 @@
+package tik
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrFluentInvalidID       = errors.New("invalid Fluent message identifier")
+	ErrFluentMultiplePlurals = errors.New(
+		"Fluent output doesn't support multiple cardinal pluralizations")
+	ErrFluentUnsupported = errors.New("construct not representable in Fluent")
+)
+
+// FluentTranslator is a reusable TIK to Mozilla Fluent (FTL) message translator.
+// Placeholders become variable references `{ $var0 }`, numbers and dates use
+// the NUMBER and DATETIME functions and cardinal pluralizations become
+// select expressions with a default `*[other]` variant, as do select
+// placeholders such as booleans.
+// The context becomes the message comment.
+type FluentTranslator struct {
+	icu ICUTranslator
+	v   fluentVisitor
+}
+
+func NewFluentTranslator(conf Config) *FluentTranslator {
+	return &FluentTranslator{icu: ICUTranslator{conf: conf}}
+}
+
+// TIK2Fluent translates tik into a Fluent message with identifier id.
+// Returns ErrFluentInvalidID if id isn't a valid Fluent identifier,
+// ErrFluentMultiplePlurals if tik contains more than one cardinal
+// pluralization and ErrFluentUnsupported for pluralization offsets
+// and number skeletons.
+func (f *FluentTranslator) TIK2Fluent(tik TIK, id string) (string, error) {
+	if !isFluentIdentifier(id) {
+		return "", ErrFluentInvalidID
+	}
+	f.v = fluentVisitor{b: f.v.b, conf: &f.icu.conf}
+	f.v.b.Reset()
+	if c := tik.Context(); c != "" {
+		f.v.b.WriteString("# ")
+		f.v.b.WriteString(c)
+		f.v.b.WriteByte('\n')
+	}
+	f.v.b.WriteString(id)
+	f.v.b.WriteString(" = ")
+	if err := f.icu.Visit(tik, &f.v); err != nil {
+		return "", err
+	}
+	return f.v.b.String(), nil
+}
+
+// isFluentIdentifier returns true for `[a-zA-Z][a-zA-Z0-9_-]*`.
+func isFluentIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '_' || c == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+var replacerEscapeFluent = strings.NewReplacer(
+	"{", `{"{"}`, "}", `{"}"}`, "\n", "\n    ",
+)
+
+type fluentVisitor struct {
+	b           strings.Builder
+	conf        *Config
+	plurals     int
+	pluralIndex int
+	armEmpty    bool
+}
+
+func (v *fluentVisitor) Literal(s string) error {
+	v.b.WriteString(replacerEscapeFluent.Replace(s))
+	v.armEmpty = false
+	return nil
+}
+
+func (v *fluentVisitor) Argument(index int, kind FormatKind) error {
+	v.armEmpty = false
+	name := "$" + v.conf.argName(index)
+	switch kind {
+	case FormatKindText, FormatKindTextWithGender, FormatKindEmail, FormatKindURL:
+		v.b.WriteString("{ " + name + " }")
+	case FormatKindInteger:
+		v.b.WriteString("{ NUMBER(" + name + ", maximumFractionDigits: 0) }")
+	case FormatKindNumber:
+		v.b.WriteString("{ NUMBER(" + name + ") }")
+	case FormatKindNumberRange:
+		v.b.WriteString("{ NUMBER(" + name + ") }–{ NUMBER($" +
+			v.conf.argName(index+1) + ") }")
+	case FormatKindCurrency:
+		v.b.WriteString("{ NUMBER(" + name + `, style: "currency") }`)
+	case FormatKindOrdinal:
+		return v.Ordinal(index, v.conf.OrdinalPluralOtherSuffix)
+	case FormatKindDateFull:
+		v.b.WriteString("{ DATETIME(" + name + `, dateStyle: "full") }`)
+	case FormatKindDateLong:
+		v.b.WriteString("{ DATETIME(" + name + `, dateStyle: "long") }`)
+	case FormatKindDateMedium:
+		v.b.WriteString("{ DATETIME(" + name + `, dateStyle: "medium") }`)
+	case FormatKindDateShort:
+		v.b.WriteString("{ DATETIME(" + name + `, dateStyle: "short") }`)
+	case FormatKindTimeFull:
+		v.b.WriteString("{ DATETIME(" + name + `, timeStyle: "full") }`)
+	case FormatKindTimeLong:
+		v.b.WriteString("{ DATETIME(" + name + `, timeStyle: "long") }`)
+	case FormatKindTimeMedium:
+		v.b.WriteString("{ DATETIME(" + name + `, timeStyle: "medium") }`)
+	case FormatKindTimeShort:
+		v.b.WriteString("{ DATETIME(" + name + `, timeStyle: "short") }`)
+	case FormatKindDateTime:
+		v.b.WriteString("{ DATETIME(" + name + `, dateStyle: "medium", timeStyle: "short") }`)
+	case FormatKindTimeZone:
+		v.b.WriteString("{ DATETIME(" + name + `, timeZoneName: "` +
+			timeZoneNames[v.conf.timeZoneSkeleton()] + `") }`)
+	default:
+		return ErrFluentUnsupported
+	}
+	return nil
+}
+
+func (v *fluentVisitor) Currency(index int, code string) error {
+	v.armEmpty = false
+	v.b.WriteString("{ NUMBER($" + v.conf.argName(index) +
+		`, style: "currency", currency: "` + code + `") }`)
+	return nil
+}
+
+func (v *fluentVisitor) Ordinal(index int, suffix string) error {
+	v.armEmpty = false
+	name := "$" + v.conf.argName(index)
+	v.b.WriteString("{ NUMBER(" + name + `, type: "ordinal") ->` +
+		"\n   *[other] { " + name + " }" + suffix +
+		"\n}")
+	return nil
+}
+
+func (v *fluentVisitor) Unit(index int, unit string) error {
+	v.armEmpty = false
+	v.b.WriteString("{ NUMBER($" + v.conf.argName(index) +
+		`, style: "unit", unit: "` + unit + `") }`)
+	return nil
+}
+
+func (v *fluentVisitor) PluralStart(index int) error {
+	if v.plurals++; v.plurals > 1 {
+		return ErrFluentMultiplePlurals
+	}
+	v.pluralIndex = index
+	v.b.WriteString("{ $" + v.conf.argName(index) + " ->")
+	return nil
+}
+
+// closeArm makes sure the previous variant isn't empty
+// since Fluent requires a non-empty variant value.
+func (v *fluentVisitor) closeArm() {
+	if v.armEmpty {
+		v.b.WriteString(`{""}`)
+	}
+}
+
+func (v *fluentVisitor) PluralOffset(int) error { return ErrFluentUnsupported }
+
+func (v *fluentVisitor) NumberSkeleton(int, string) error { return ErrFluentUnsupported }
+
+func (v *fluentVisitor) RawICU(int, string) error { return ErrFluentUnsupported }
+
+func (v *fluentVisitor) PluralExactMatch(value int) error {
+	v.closeArm()
+	v.b.WriteString("\n    [")
+	v.b.WriteString(strconv.Itoa(value))
+	v.b.WriteString("] ")
+	v.armEmpty = true
+	return nil
+}
+
+func (v *fluentVisitor) PluralOther() error {
+	v.closeArm()
+	v.b.WriteString("\n   *[other] { $" + v.conf.argName(v.pluralIndex) + " }")
+	v.armEmpty = false
+	return nil
+}
+
+func (v *fluentVisitor) PluralEnd() error {
+	v.b.WriteString("\n}")
+	return nil
+}
+
+// Tag writes the markup tag as is since Fluent leaves markup
+// to the runtime, such as DOM overlays.
+func (v *fluentVisitor) Tag(_ int, name string, tag TokenType) error {
+	v.b.WriteString(markupTag(name, tag))
+	v.armEmpty = false
+	return nil
+}
+
+func (v *fluentVisitor) MessageRef(key string) error {
+	// Fluent references attributes as message.attribute.
+	id, attr, hasAttr := strings.Cut(key, ".")
+	if !isFluentIdentifier(id) ||
+		hasAttr && (!isFluentIdentifier(attr) || strings.Contains(attr, ".")) {
+		return ErrFluentUnsupported
+	}
+	v.b.WriteString("{ " + key + " }")
+	v.armEmpty = false
+	return nil
+}
+
+func (v *fluentVisitor) Select(index int, name string, cases []SelectCase) error {
+	v.armEmpty = false
+	if name == "" {
+		name = v.conf.argName(index)
+	}
+	v.b.WriteString("{ $" + name + " ->")
+	for _, c := range cases {
+		v.b.WriteString("\n    [" + c.Key + "] ")
+		if c.Text == "" {
+			v.b.WriteString(`{""}`)
+		} else {
+			v.b.WriteString(replacerEscapeFluent.Replace(c.Text))
+		}
+	}
+	v.b.WriteString("\n   *[other] {\"\"}\n}")
+	return nil
+}
@@ -0,0 +1,114 @@
+package tik_test
+
+import (
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestFluentTranslator(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewFluentTranslator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		actual, err := translator.TIK2Fluent(tk, "msg")
+		requireNoErr(t, err)
+		requireEqual(t, expect, actual)
+	}
+
+	// Literals.
+	f(t, `msg = hello world`, `hello world`)
+	f(t, "# greeting\nmsg = hello world", `[greeting] hello world`)
+	f(t, `msg = {"{"}not a placeholder{"}"}`, `\{not a placeholder\}`)
+	f(t, "msg = first\n    second", "first\nsecond")
+
+	// Text.
+	f(t, `msg = hello { $var0 } and { $var1 }`, `hello {text} and {name}`)
+
+	// Numbers.
+	f(t, `msg = { NUMBER($var0, maximumFractionDigits: 0) } items`,
+		`{integer} items`)
+	f(t, `msg = { NUMBER($var0) } degrees`, `{number} degrees`)
+	f(t, `msg = balance: { NUMBER($var0, style: "currency") }`,
+		`balance: {currency}`)
+	f(t, `msg = balance: { NUMBER($var0, style: "currency", currency: "EUR") }`,
+		`balance: {currency:EUR}`)
+
+	// Dates and times.
+	f(t, `msg = { DATETIME($var0, dateStyle: "full") }, `+
+		`{ DATETIME($var1, dateStyle: "long") }, `+
+		`{ DATETIME($var2, dateStyle: "medium") }, `+
+		`{ DATETIME($var3, dateStyle: "short") }`,
+		`{date-full}, {date-long}, {date-medium}, {date-short}`)
+	f(t, `msg = { DATETIME($var0, timeStyle: "full") }, `+
+		`{ DATETIME($var1, timeStyle: "long") }, `+
+		`{ DATETIME($var2, timeStyle: "medium") }, `+
+		`{ DATETIME($var3, timeStyle: "short") }`,
+		`{time-full}, {time-long}, {time-medium}, {time-short}`)
+	f(t, `msg = { DATETIME($var0, timeZoneName: "long") }`, `{time-zone}`)
+
+	f(t, `msg = { NUMBER($var0) }–{ NUMBER($var1) } of { $var2 }`,
+		`{number-range} of {text}`)
+
+	// Pluralization.
+	f(t, "msg = You're { NUMBER($var0, type: \"ordinal\") ->\n"+
+		"   *[other] { $var0 }th\n"+
+		"}", `You're {ordinal}`)
+	f(t, "msg = { NUMBER($var0, type: \"ordinal\") ->\n"+
+		"   *[other] { $var0 }.\n"+
+		"}", `{ordinal:.}`)
+	f(t, "msg = { $var0 } had { $var1 ->\n"+
+		"   *[other] { $var1 } messages from { $var2 }\n"+
+		"}.", `{name} had {# messages from {text}}.`)
+	f(t, "msg = { $var0 ->\n"+
+		"    [0] no messages\n"+
+		"    [1] {\"\"}\n"+
+		"   *[other] { $var0 } messages\n"+
+		"}", `{# |=1  |=0 no messages | messages}`)
+
+	// Message references.
+	f(t, `msg = see { help } and { help.title }`, `see {@help} and {@help.title}`)
+
+	// Booleans.
+	f(t, "msg = Wi-Fi is { $var0 ->\n"+
+		"    [true] on\n"+
+		"    [false] off\n"+
+		"   *[other] {\"\"}\n"+
+		"}", `Wi-Fi is {bool:on/off}`)
+
+	// Enum selects.
+	f(t, "msg = Order { $status ->\n"+
+		"    [pending] {\"\"}\n"+
+		"    [shipped] {\"\"}\n"+
+		"   *[other] {\"\"}\n"+
+		"}", `Order {select:status(pending,shipped)}`)
+}
+
+func TestFluentTranslatorErr(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewFluentTranslator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect error, id, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		actual, err := translator.TIK2Fluent(tk, id)
+		requireErrIs(t, expect, err)
+		requireEqual(t, "", actual)
+	}
+
+	f(t, tik.ErrFluentInvalidID, "", `text`)
+	f(t, tik.ErrFluentInvalidID, "0msg", `text`)
+	f(t, tik.ErrFluentInvalidID, "my.msg", `text`)
+	f(t, tik.ErrFluentMultiplePlurals, "msg", `{# messages} in {# folders}`)
+	f(t, tik.ErrFluentUnsupported, "msg", `{# offset:1 |=0 nobody | others}`)
+	f(t, tik.ErrFluentUnsupported, "msg", `see {@a.b.c}`)
+	f(t, tik.ErrFluentUnsupported, "msg", `see {@0a}`)
+}
@@ -0,0 +1,151 @@
+package tik
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var ErrReplacementInvalid = errors.New("invalid placeholder replacement")
+
+// Format parses input and returns it in canonical form:
+//
+//   - the context is separated from the body by a single space.
+//   - whitespace in literals is collapsed to a single space
+//     except leading and trailing whitespace if
+//     Config.PreserveEdgeWhitespace is enabled.
+//   - hints are separated from the placeholder by ` # `.
+//   - offsets and arms of cardinal pluralizations are separated
+//     by a single space: `{# offset:1 |=0 nobody | others}`.
+//   - currency codes are uppercase.
+//   - placeholder keywords are lowercase if
+//     Config.CaseInsensitiveKeywords is enabled.
+//
+// Escape sequences are preserved and the content following `{#` and `|`
+// is left as is since `{#件}` and `{# items}` are both valid.
+// Format is idempotent and the formatted TIK is equal to the original
+// according to TIK.Equal.
+func Format(input string, conf Config) (string, error) {
+	t, err := NewParser(conf).Parse(input)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.Grow(len(input))
+	afterArm := false
+	for i, tok := range t.Tokens {
+		raw := t.Raw[tok.IndexStart:tok.IndexEnd]
+		if afterArm && tok.Type != TokenTypePluralExactMatch &&
+			tok.Type != TokenTypePluralOther && tok.Type != TokenTypeCardinalPluralEnd {
+			// Content of an exact match arm.
+			b.WriteByte(' ')
+		}
+		afterArm = false
+		switch tok.Type {
+		case TokenTypeContext:
+			b.WriteString(raw)
+			b.WriteByte(' ')
+		case TokenTypeLiteral:
+			if i > 0 && t.Tokens[i-1].Type == TokenTypeContext {
+				raw = strings.TrimLeftFunc(raw, unicode.IsSpace)
+			}
+			var leading, trailing string
+			if conf.PreserveEdgeWhitespace {
+				// Edge whitespace is significant and left as is.
+				if i == 0 {
+					trimmed := strings.TrimLeftFunc(raw, unicode.IsSpace)
+					leading, raw = raw[:len(raw)-len(trimmed)], trimmed
+				}
+				if i == len(t.Tokens)-1 {
+					trimmed := strings.TrimRightFunc(raw, unicode.IsSpace)
+					trailing, raw = raw[len(trimmed):], trimmed
+				}
+			}
+			b.WriteString(leading)
+			b.WriteString(collapseSpace(raw))
+			b.WriteString(trailing)
+		case TokenTypePluralOffset:
+			b.WriteByte(' ')
+			b.WriteString(raw)
+		case TokenTypePluralExactMatch:
+			b.WriteByte(' ')
+			b.WriteString(raw)
+			afterArm = true
+		case TokenTypePluralOther:
+			b.WriteString(" |")
+		case TokenTypeCardinalPluralStart, TokenTypeCardinalPluralEnd,
+			TokenTypeMessageRef, TokenTypeTagOpen, TokenTypeTagClose,
+			TokenTypeTagSelfClosing:
+			b.WriteString(raw)
+		default:
+			writeFormattedPlaceholder(&b, raw, tok, conf.CaseInsensitiveKeywords)
+		}
+	}
+	return b.String(), nil
+}
+
+// ReplacePlaceholder returns t.Raw with the placeholder consuming
+// the positional argument pos replaced by a placeholder of type newType,
+// such as `{integer # count}` for `{number # count}`. The name of a named
+// placeholder and the hint are preserved, everything else is left untouched.
+// Returns ErrReplacementInvalid if no placeholder starts at pos or if
+// either the placeholder or newType is a cardinal pluralization or requires
+// a value such as a select, which can't be replaced by keyword alone.
+// Returns the ParseError if the result isn't a valid TIK according to conf,
+// such as when a placeholder name is reused for another type.
+func (t TIK) ReplacePlaceholder(pos int, newType TokenType, conf Config) (string, error) {
+	keyword := newType.keyword()
+	if keyword == "" {
+		return "", ErrReplacementInvalid
+	}
+	for i, tok := range t.Placeholders() {
+		if i != pos {
+			continue
+		}
+		if tok.Type == TokenTypeCardinalPluralStart {
+			return "", ErrReplacementInvalid
+		}
+		var b strings.Builder
+		b.WriteString(t.Raw[:tok.IndexStart])
+		b.WriteByte('{')
+		b.WriteString(keyword)
+		if tok.Type.isNameable() && tok.Value != "" && newType.isNameable() {
+			b.WriteByte(':')
+			b.WriteString(tok.Value)
+		}
+		if tok.Hint != "" {
+			_, hint, _ := cutHint(t.Raw[tok.IndexStart+1 : tok.IndexEnd-1])
+			b.WriteString(" #")
+			b.WriteString(hint)
+		}
+		b.WriteByte('}')
+		b.WriteString(t.Raw[tok.IndexEnd:])
+		s := b.String()
+		if _, err := NewParser(conf).Parse(s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+	return "", ErrReplacementInvalid
+}
+
+func writeFormattedPlaceholder(b *strings.Builder, raw string, tok Token, fold bool) {
+	name, hint := raw[1:len(raw)-1], ""
+	if tok.Hint != "" {
+		name, hint, _ = cutHint(name)
+		name = strings.TrimRightFunc(name, unicode.IsSpace)
+	}
+	if fold && tok.Type != TokenTypeStringPlaceholder {
+		name = foldKeyword(name)
+	}
+	if tok.Type == TokenTypeCurrency && tok.Value != "" {
+		name = "currency:" + tok.Value
+	}
+	b.WriteByte('{')
+	b.WriteString(name)
+	if hint != "" {
+		b.WriteString(" # ")
+		b.WriteString(collapseSpace(strings.TrimSpace(hint)))
+	}
+	b.WriteByte('}')
+}
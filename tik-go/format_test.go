@@ -0,0 +1,209 @@
+package tik_test
+
+import (
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect, input string) {
+		t.Helper()
+		actual, err := tik.Format(input, tik.DefaultConfig)
+		requireNoErr(t, err)
+		requireEqual(t, expect, actual)
+
+		// Idempotent.
+		again, err := tik.Format(actual, tik.DefaultConfig)
+		requireNoErr(t, err)
+		requireEqual(t, expect, again)
+
+		a, err := p.Parse(input)
+		requireNoErr(t, err)
+		b, err := p.Parse(actual)
+		requireNoErr(t, err)
+		if !a.Equal(b) {
+			t.Fatalf("formatted TIK %q isn't equal to %q", actual, input)
+		}
+	}
+
+	f(t, `hello world`, `hello world`)
+	f(t, `hello world`, "  hello \t\n world  ")
+	f(t, `[ctx] hello {text}`, "[ctx]\t\n  hello   {text}")
+	f(t, `[ctx] hello`, `[ctx]  hello`)
+	f(t, `\{not a placeholder\} \\ {text}`, `\{not  a placeholder\}   \\ {text}`)
+	f(t, `[a\[b\]] \{ x`, `[a\[b\]]   \{  x`)
+	f(t, `{text # the recipient's name} and {"John # Doe" # a sample}`,
+		`{text   #   the   recipient's name } and {"John # Doe"  #a sample}`)
+	f(t, `{text # a \{b}`, `{text #a \{b  }`)
+	f(t, `{currency:EUR} {currency:USD # price}`, `{currency:eur} {currency:usd # price}`)
+	f(t, `You have {# messages} in {#件}`, `You have  {#  messages}  in {#件}`)
+	f(t, `{# |=0 no messages |=1 one message | messages}`,
+		`{#|=0   no messages |=1 one message|  messages}`)
+	f(t, `{# offset:1 |=0 nobody |=1 {name} | others and {name}}`,
+		`{#  offset:1   |=0 nobody |=1   {name}   | others   and {name}}`)
+	f(t, `{# |=0 |=1 {name} |件}`, `{#|=0  |=1 {name}  |件}`)
+	f(t, `see {@help.intro} at {date-short}`, `see   {@help.intro}  at {date-short}`)
+	f(t, `{bool:on/off} {select:status(a,b)} {time-zone}`,
+		`{bool:on/off}  {select:status(a,b)}   {time-zone}`)
+}
+
+func TestFormatMarkupTags(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.MarkupTags = []string{"a", "br"}
+	actual, err := tik.Format(`click  {<a>}here{</a>}{<br/>}  now`, conf)
+	requireNoErr(t, err)
+	requireEqual(t, `click {<a>}here{</a>}{<br/>} now`, actual)
+}
+
+func TestFormatNamedPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.NamedPlaceholders = true
+	actual, err := tik.Format(`{text:user}   has {integer:n   #  count}`, conf)
+	requireNoErr(t, err)
+	requireEqual(t, `{text:user} has {integer:n # count}`, actual)
+}
+
+func TestFormatErr(t *testing.T) {
+	t.Parallel()
+
+	actual, err := tik.Format(`{unknown}`, tik.DefaultConfig)
+	requireErrIs(t, tik.ErrUnknownPlaceholder, err)
+	requireEqual(t, "", actual)
+}
+
+func TestTIKReplacePlaceholder(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect string, pos int, newType tik.TokenType, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		actual, err := tk.ReplacePlaceholder(pos, newType, tik.DefaultConfig)
+		requireNoErr(t, err)
+		requireEqual(t, expect, actual)
+	}
+
+	f(t, `[ctx] Sent {integer}  files to {text}`,
+		0, tik.TokenTypeInteger, `[ctx] Sent {number}  files to {text}`)
+	f(t, `Sent {number} files to {name}`,
+		1, tik.TokenTypeTextWithGender, `Sent {number} files to {text}`)
+	f(t, `{integer # the \{count\}} items`,
+		0, tik.TokenTypeInteger, `{number # the \{count\}} items`)
+	f(t, `{number} to {currency:EUR} by {date-time}`,
+		2, tik.TokenTypeDateTime, `{number} to {currency:EUR} by {date-short}`)
+	f(t, `{number} by {text}`,
+		0, tik.TokenTypeNumber, `{number-range} by {text}`)
+
+	// Positional arguments of cardinal pluralizations are skipped.
+	f(t, `{# files in {date-medium}} by {text}`,
+		1, tik.TokenTypeDateMedium, `{# files in {date-short}} by {text}`)
+	f(t, `{# |=0 none | files by {email}}`,
+		1, tik.TokenTypeEmail, `{# |=0 none | files by {text}}`)
+
+	// Names are preserved.
+	conf := tik.DefaultConfig
+	conf.NamedPlaceholders = true
+	tk, err := tik.NewParser(conf).Parse(`{number:n # count} files`)
+	requireNoErr(t, err)
+	actual, err := tk.ReplacePlaceholder(0, tik.TokenTypeInteger, conf)
+	requireNoErr(t, err)
+	requireEqual(t, `{integer:n # count} files`, actual)
+}
+
+func TestTIKReplacePlaceholderErr(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect error, pos int, newType tik.TokenType, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		actual, err := tk.ReplacePlaceholder(pos, newType, tik.DefaultConfig)
+		requireErrIs(t, expect, err)
+		requireEqual(t, "", actual)
+	}
+
+	f(t, tik.ErrReplacementInvalid, 0, tik.TokenTypeInteger, `{# files}`)
+	f(t, tik.ErrReplacementInvalid, 0, tik.TokenTypeCardinalPluralStart, `{number}`)
+	f(t, tik.ErrReplacementInvalid, 0, tik.TokenTypeBoolean, `{number}`)
+	f(t, tik.ErrReplacementInvalid, 0, tik.TokenTypeUnit, `{number}`)
+	f(t, tik.ErrReplacementInvalid, 0, tik.TokenTypeLiteral, `{number}`)
+	f(t, tik.ErrReplacementInvalid, 1, tik.TokenTypeInteger, `{number} files`)
+	f(t, tik.ErrReplacementInvalid, -1, tik.TokenTypeInteger, `{number} files`)
+	f(t, tik.ErrReplacementInvalid, 1, tik.TokenTypeInteger, `{number-range} files`)
+
+	// The result must be valid.
+	conf := tik.DefaultConfig
+	conf.NamedPlaceholders = true
+	tk, err := tik.NewParser(conf).Parse(`{number:n} of {number:n}`)
+	requireNoErr(t, err)
+	actual, err := tk.ReplacePlaceholder(0, tik.TokenTypeInteger, conf)
+	requireErrIs(t, tik.ErrDuplicatePlaceholderName, err)
+	requireEqual(t, "", actual)
+}
+
+func TestFormatPreserveEdgeWhitespace(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.PreserveEdgeWhitespace = true
+
+	f := func(t *testing.T, expect, input string) {
+		t.Helper()
+		actual, err := tik.Format(input, conf)
+		requireNoErr(t, err)
+		requireEqual(t, expect, actual)
+
+		// Idempotent.
+		again, err := tik.Format(actual, conf)
+		requireNoErr(t, err)
+		requireEqual(t, expect, again)
+	}
+
+	f(t, "  ·  ", "  ·  ")
+	f(t, " \t· \n", " \t· \n")
+	f(t, "a {text}   ", "a  {text}   ")
+	f(t, "  {text} a", "  {text}  a")
+	f(t, "  a b  ", "  a   b  ")
+	f(t, "[ctx] a {text} ", "[ctx]   a  {text} ")
+}
+
+func TestFormatCaseInsensitiveKeywords(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.CaseInsensitiveKeywords = true
+	conf.NamedPlaceholders = true
+
+	f := func(t *testing.T, expect, input string) {
+		t.Helper()
+		actual, err := tik.Format(input, conf)
+		requireNoErr(t, err)
+		requireEqual(t, expect, actual)
+
+		// Idempotent.
+		again, err := tik.Format(actual, conf)
+		requireNoErr(t, err)
+		requireEqual(t, expect, again)
+	}
+
+	f(t, "{text}", "{Text}")
+	f(t, "{number:N} and {number:N}", "{NUMBER:N} and {number:N}")
+	f(t, "{date-full # start}", "{Date-Full   #  start}")
+	f(t, "{currency:EUR}", "{Currency:eur}")
+	f(t, "{number::.00}", "{NUMBER::.00}")
+	f(t, `{"Alice"}`, `{"Alice"}`)
+	f(t, "{# |=0 none | many}", "{# |=0 none | many}")
+}
@@ -1,3 +1,8 @@
 module github.com/romshark/tik/tik-go
 
-go 1.25
+go 1.25.0
+
+require (
+	github.com/clipperhouse/uax29/v2 v2.7.0
+	golang.org/x/text v0.41.0
+)
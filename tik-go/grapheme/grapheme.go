@@ -0,0 +1,25 @@
+// Package grapheme counts the user-perceived characters of TIKs
+// using the Unicode text segmentation of
+// github.com/clipperhouse/uax29.
+package grapheme
+
+import (
+	"github.com/clipperhouse/uax29/v2/graphemes"
+	tik "github.com/romshark/tik/tik-go"
+)
+
+// LiteralLen returns the number of user-perceived characters
+// (extended grapheme clusters as defined by Unicode Standard Annex #29)
+// of all literals of t, including the literals of all arms of cardinal
+// pluralizations, such as 6 for `Café {text}!`.
+// Escape sequences count as the character they escape.
+// Placeholders, message references and markup tags aren't counted.
+func LiteralLen(t tik.TIK) int {
+	n := 0
+	for _, tok := range t.Literals() {
+		for g := graphemes.FromString(tok.String(t.Raw)); g.Next(); {
+			n++
+		}
+	}
+	return n
+}
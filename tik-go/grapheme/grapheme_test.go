@@ -0,0 +1,63 @@
+package grapheme_test
+
+import (
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+	"github.com/romshark/tik/tik-go/grapheme"
+)
+
+func TestLiteralLen(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect int, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := grapheme.LiteralLen(tk); actual != expect {
+			t.Errorf("expected %d, received %d", expect, actual)
+		}
+	}
+
+	f(t, 5, `hello`)
+	f(t, 0, `{text}`)
+	f(t, 6, `[context is ignored] Café {text}!`)
+	f(t, 3, `\{\}\\`)
+	f(t, 12, `{# |=0 none | files}, {name}`)
+
+	// Combining marks.
+	f(t, 4, "Café") // e + combining acute accent.
+	f(t, 1, "ạ̈́")  // Stacked marks.
+	f(t, 1, "क्षि")  // Devanagari conjunct with spacing vowel sign.
+	f(t, 2, "किष")   // Two syllables.
+
+	// Emoji with skin tone modifiers, variation selectors,
+	// ZWJ sequences, regional indicators and tags.
+	f(t, 1, "\U0001F44D")
+	f(t, 1, "\U0001F44D\U0001F3FD")
+	f(t, 1, "❤️")
+	f(t, 1, "\U0001F469‍\U0001F469‍\U0001F467‍\U0001F466")
+	f(t, 1, "\U0001F3F3️‍\U0001F308")
+	f(t, 2, "\U0001F1E9\U0001F1EA\U0001F1EB\U0001F1F7")
+	f(t, 3, "\U0001F1E9\U0001F1EA\U0001F1EB\U0001F1F7\U0001F1EE")
+	f(t, 1, "\U0001F3F4\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F")
+	f(t, 7, "Hi \U0001F44B\U0001F3FB {name} ✨")
+
+	// ZWJ only joins emoji.
+	f(t, 1, "a‍‍")
+	f(t, 2, "a‍\U0001F44D")
+
+	// Hangul.
+	f(t, 2, "한국")
+	f(t, 1, "한") // Conjoining jamo.
+
+	// Prepended concatenation marks.
+	f(t, 1, "؀١")
+
+	// CR LF is a single character.
+	f(t, 3, "a\r\nb")
+}
@@ -0,0 +1,211 @@
+package tik
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrI18nextMultiplePlurals = errors.New(
+		"i18next values can't represent multiple cardinal pluralizations")
+	ErrI18nextUnsupported = errors.New("construct not representable in i18next")
+)
+
+// I18nextMeta describes the i18next keys a caller must emit for a value.
+type I18nextMeta struct {
+	// Suffixes are the suffixes the caller must append to the key
+	// and emit the value under. The first suffix is for the singular form.
+	// For example, a TIK with context `[report]` and a cardinal pluralization
+	// requires the suffixes "_report" and "_report_plural".
+	Suffixes []string
+
+	// PluralValue is the value of the plural key, which is equal to the
+	// singular value since TIKs are written in CLDR plural category `other`.
+	// PluralValue is empty if the TIK has no cardinal pluralization.
+	PluralValue string
+
+	// CountArg is the positional index of the argument that must be passed
+	// as i18next's `count` option, or -1 if the TIK has no cardinal pluralization.
+	CountArg int
+}
+
+// I18nextTranslator is a reusable TIK to i18next JSON value translator.
+// Placeholders become `{{var0}}` interpolations with i18next formats such as
+// `{{var0, number}}` and `{{var0, datetime(dateStyle: long)}}`, and the number
+// of a cardinal pluralization becomes `{{count}}`.
+// Message references become nesting `$t(key)`.
+type I18nextTranslator struct {
+	icu ICUTranslator
+	v   i18nextVisitor
+}
+
+func NewI18nextTranslator(conf Config) *I18nextTranslator {
+	return &I18nextTranslator{icu: ICUTranslator{conf: conf}}
+}
+
+// TIK2I18next translates tik into an i18next value.
+// Returns ErrI18nextMultiplePlurals if tik contains more than one cardinal
+// pluralization and ErrI18nextUnsupported for ordinals, number skeletons,
+// pluralization arms and literal curly braces that would be read as
+// interpolation delimiters such as `\{\{` or `\{` preceding a placeholder,
+// since i18next provides no way to escape them.
+func (i *I18nextTranslator) TIK2I18next(tik TIK) (
+	value string, meta I18nextMeta, err error,
+) {
+	i.v.b.Reset()
+	i.v.conf, i.v.countArg, i.v.literalEnd = &i.icu.conf, -1, 0
+	if err := i.icu.Visit(tik, &i.v); err != nil {
+		return "", I18nextMeta{}, err
+	}
+	value = i.v.b.String()
+
+	suffix := ""
+	if c := tik.Context(); c != "" {
+		suffix = "_" + c
+	}
+	meta = I18nextMeta{Suffixes: []string{suffix}, CountArg: i.v.countArg}
+	if i.v.countArg != -1 {
+		meta.Suffixes = append(meta.Suffixes, suffix+"_plural")
+		meta.PluralValue = value
+	}
+	return value, meta, nil
+}
+
+type i18nextVisitor struct {
+	b          strings.Builder
+	conf       *Config
+	countArg   int
+	literalEnd int // Length of b after the last literal.
+}
+
+// lastLiteralByte returns the last byte written if it belongs to a literal,
+// otherwise returns 0.
+func (v *i18nextVisitor) lastLiteralByte() byte {
+	if v.literalEnd == 0 || v.literalEnd != v.b.Len() {
+		return 0
+	}
+	return v.b.String()[v.literalEnd-1]
+}
+
+// interpolation returns ErrI18nextUnsupported if an interpolation
+// can't be written because the preceding literal ends with `{`.
+func (v *i18nextVisitor) interpolation() error {
+	if v.lastLiteralByte() == '{' {
+		return ErrI18nextUnsupported
+	}
+	return nil
+}
+
+func (v *i18nextVisitor) Literal(s string) error {
+	if s == "" {
+		return nil
+	}
+	// Literal curly braces can't be escaped and must not form
+	// the interpolation delimiters `{{` and `}}`.
+	if strings.Contains(s, "{{") || strings.Contains(s, "}}") ||
+		(s[0] == '{' || s[0] == '}') && v.lastLiteralByte() == s[0] {
+		return ErrI18nextUnsupported
+	}
+	v.b.WriteString(s)
+	v.literalEnd = v.b.Len()
+	return nil
+}
+
+func (v *i18nextVisitor) Argument(index int, kind FormatKind) error {
+	if err := v.interpolation(); err != nil {
+		return err
+	}
+	v.b.WriteString("{{")
+	v.b.WriteString(v.conf.argName(index))
+	switch kind {
+	case FormatKindText, FormatKindTextWithGender, FormatKindEmail, FormatKindURL:
+	case FormatKindInteger:
+		v.b.WriteString(", number(maximumFractionDigits: 0)")
+	case FormatKindNumber:
+		v.b.WriteString(", number")
+	case FormatKindNumberRange:
+		v.b.WriteString(", number}}–{{" + v.conf.argName(index+1) + ", number")
+	case FormatKindCurrency:
+		v.b.WriteString(", currency")
+	case FormatKindDateFull:
+		v.b.WriteString(", datetime(dateStyle: full)")
+	case FormatKindDateLong:
+		v.b.WriteString(", datetime(dateStyle: long)")
+	case FormatKindDateMedium:
+		v.b.WriteString(", datetime(dateStyle: medium)")
+	case FormatKindDateShort:
+		v.b.WriteString(", datetime(dateStyle: short)")
+	case FormatKindTimeFull:
+		v.b.WriteString(", datetime(timeStyle: full)")
+	case FormatKindTimeLong:
+		v.b.WriteString(", datetime(timeStyle: long)")
+	case FormatKindTimeMedium:
+		v.b.WriteString(", datetime(timeStyle: medium)")
+	case FormatKindTimeShort:
+		v.b.WriteString(", datetime(timeStyle: short)")
+	case FormatKindDateTime:
+		v.b.WriteString(", datetime(dateStyle: medium; timeStyle: short)")
+	case FormatKindTimeZone:
+		v.b.WriteString(", datetime(timeZoneName: " +
+			timeZoneNames[v.conf.timeZoneSkeleton()] + ")")
+	default:
+		return ErrI18nextUnsupported
+	}
+	v.b.WriteString("}}")
+	return nil
+}
+
+func (v *i18nextVisitor) Currency(index int, code string) error {
+	if err := v.interpolation(); err != nil {
+		return err
+	}
+	v.b.WriteString("{{" + v.conf.argName(index) + ", currency(currency: " + code + ")}}")
+	return nil
+}
+
+func (v *i18nextVisitor) Ordinal(int, string) error { return ErrI18nextUnsupported }
+
+func (v *i18nextVisitor) NumberSkeleton(int, string) error { return ErrI18nextUnsupported }
+
+func (v *i18nextVisitor) Unit(index int, unit string) error {
+	if err := v.interpolation(); err != nil {
+		return err
+	}
+	v.b.WriteString("{{" + v.conf.argName(index) + ", number(style: unit; unit: " + unit + ")}}")
+	return nil
+}
+
+func (v *i18nextVisitor) PluralStart(index int) error {
+	if v.countArg != -1 {
+		return ErrI18nextMultiplePlurals
+	}
+	v.countArg = index
+	return nil
+}
+
+func (v *i18nextVisitor) PluralOther() error {
+	if err := v.interpolation(); err != nil {
+		return err
+	}
+	v.b.WriteString("{{count}}")
+	return nil
+}
+
+func (v *i18nextVisitor) PluralOffset(int) error     { return ErrI18nextUnsupported }
+func (v *i18nextVisitor) PluralExactMatch(int) error { return ErrI18nextUnsupported }
+func (v *i18nextVisitor) PluralEnd() error           { return nil }
+
+func (v *i18nextVisitor) Tag(_ int, name string, tag TokenType) error {
+	v.b.WriteString(markupTag(name, tag))
+	return nil
+}
+
+func (v *i18nextVisitor) MessageRef(key string) error {
+	v.b.WriteString("$t(")
+	v.b.WriteString(key)
+	v.b.WriteString(")")
+	return nil
+}
+
+func (v *i18nextVisitor) Select(int, string, []SelectCase) error { return ErrI18nextUnsupported }
+func (v *i18nextVisitor) RawICU(int, string) error               { return ErrI18nextUnsupported }
@@ -0,0 +1,85 @@
+package tik_test
+
+import (
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestI18nextTranslator(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewI18nextTranslator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect string, expectMeta tik.I18nextMeta, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		actual, meta, err := translator.TIK2I18next(tk)
+		requireNoErr(t, err)
+		requireEqual(t, expect, actual)
+		requireDeepEqual(t, expectMeta, meta)
+	}
+
+	noPlural := tik.I18nextMeta{Suffixes: []string{""}, CountArg: -1}
+
+	f(t, `hello world`, noPlural, `hello world`)
+	f(t, `hello {{var0}} and {{var1}}`, noPlural, `hello {text} and {name}`)
+	f(t, `{{var0, number(maximumFractionDigits: 0)}} {{var1, number}} `+
+		`{{var2, currency}}`, noPlural, `{integer} {number} {currency}`)
+	f(t, `{{var0, number}}–{{var1, number}}`, noPlural, `{number-range}`)
+	f(t, `{{var0, currency(currency: EUR)}}`, noPlural, `{currency:EUR}`)
+	f(t, `{{var0, datetime(dateStyle: full)}} {{var1, datetime(dateStyle: long)}} `+
+		`{{var2, datetime(dateStyle: medium)}} {{var3, datetime(dateStyle: short)}}`,
+		noPlural, `{date-full} {date-long} {date-medium} {date-short}`)
+	f(t, `{{var0, datetime(timeStyle: full)}} {{var1, datetime(timeStyle: long)}} `+
+		`{{var2, datetime(timeStyle: medium)}} {{var3, datetime(timeStyle: short)}}`,
+		noPlural, `{time-full} {time-long} {time-medium} {time-short}`)
+	f(t, `see $t(help.intro)`, noPlural, `see {@help.intro}`)
+	f(t, `a {b} {{var0}}} {`, noPlural, `a \{b\} {text}\} \{`)
+	f(t, `} {{var0}} {`, noPlural, `\} {text} \{`)
+
+	f(t, `{{var0}} had {{count}} messages`, tik.I18nextMeta{
+		Suffixes:    []string{"", "_plural"},
+		PluralValue: `{{var0}} had {{count}} messages`,
+		CountArg:    1,
+	}, `{name} had {# messages}`)
+	f(t, `{{count}} reports`, tik.I18nextMeta{
+		Suffixes:    []string{"_weekly", "_weekly_plural"},
+		PluralValue: `{{count}} reports`,
+		CountArg:    0,
+	}, `[weekly] {# reports}`)
+	f(t, `Order`, tik.I18nextMeta{
+		Suffixes: []string{"_table sort column"},
+		CountArg: -1,
+	}, `[table sort column] Order`)
+}
+
+func TestI18nextTranslatorErr(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewI18nextTranslator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect error, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		_, _, err = translator.TIK2I18next(tk)
+		requireErrIs(t, expect, err)
+	}
+
+	f(t, tik.ErrI18nextMultiplePlurals, `{# messages} in {# folders}`)
+	f(t, tik.ErrI18nextUnsupported, `{# |=0 none | messages}`)
+	f(t, tik.ErrI18nextUnsupported, `You're {ordinal}`)
+
+	// Literal curly braces forming interpolation delimiters.
+	f(t, tik.ErrI18nextUnsupported, `\{\{text\}\}`)
+	f(t, tik.ErrI18nextUnsupported, `a \}\} b`)
+	f(t, tik.ErrI18nextUnsupported, `\{{text}`)
+	f(t, tik.ErrI18nextUnsupported, `\{{currency:EUR}`)
+	f(t, tik.ErrI18nextUnsupported, `\{{unit:kilometer}`)
+	f(t, tik.ErrI18nextUnsupported, `\{{# messages}`)
+	f(t, tik.ErrI18nextUnsupported, `{# files\}}\} done`)
+}
@@ -2,6 +2,9 @@ package tik
 
 import (
 	"bytes"
+	"cmp"
+	"io"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -10,6 +13,19 @@ import (
 type ICUTranslator struct {
 	b    bytes.Buffer
 	conf Config
+
+	// pluralCategories are the CLDR plural categories that
+	// cardinal plurals are scaffolded with, nil if only `other` is emitted.
+	pluralCategories []string
+
+	// pluralArmOpen is a stack defining whether an arm of the current
+	// cardinal plural is open.
+	pluralArmOpen []bool
+
+	// pluralOtherStart is a stack of the buffer offsets of the category
+	// arms and of the other arm of the current cardinal plurals
+	// used by Config.ScaffoldEnglishPlural.
+	pluralOtherStart [][2]int
 }
 
 func NewICUTranslator(conf Config) *ICUTranslator {
@@ -17,128 +33,957 @@ func NewICUTranslator(conf Config) *ICUTranslator {
 }
 
 func (i *ICUTranslator) writePositionalPlaceholder(index int, suffix string) {
-	i.b.WriteString("var")
-	i.b.WriteString(strconv.Itoa(index))
+	i.b.WriteString(i.conf.argName(index))
 	i.b.WriteString(suffix)
 }
 
 func (i *ICUTranslator) write(s string) { _, _ = i.b.WriteString(s) }
 
-var replacerEscapeQuote = strings.NewReplacer("'", "''")
+// argName returns the name of the positional argument at index
+// using the default prefix.
+func argName(index int) string { return Config{}.argName(index) }
 
-// TIK2ICUBuf similar TIK2ICU but gives temporary access to the internal buffer
-// to avoid string allocation if only a temporary byte slice is needed.
-// This function can be used instead TIK2ICU to achieve efficiency when possible
-// but must be used with caution!
-//
-// WARNING: Never use or alias buf outside fn!
-func (i *ICUTranslator) TIK2ICUBuf(
-	tik TIK, fn func(buf *bytes.Buffer),
-) {
-	i.b.Reset()
+// writeICULiteral writes s to b escaped according to the ICU MessageFormat
+// quoting rules. Apostrophes are always doubled and runs of the syntax
+// characters `{` and `}`, as well as `#` if inPlural is true, are quoted.
+// Since `'#'` outside of a plural is a literal `'#'` in ICU,
+// `#` must only be quoted inside plurals.
+// If tags is true, `<` is quoted too since runtimes supporting markup
+// tags such as `<0>` would otherwise interpret it as the start of a tag.
+func writeICULiteral(b *bytes.Buffer, s string, inPlural, tags bool) {
+	quoted := false
+	for _, r := range s {
+		switch {
+		case r == '\'':
+			b.WriteString("''")
+			continue
+		case r == '{', r == '}', r == '#' && inPlural, r == '<' && tags:
+			if !quoted {
+				b.WriteByte('\'')
+				quoted = true
+			}
+		case quoted:
+			b.WriteByte('\'')
+			quoted = false
+		}
+		b.WriteRune(r)
+	}
+	if quoted {
+		b.WriteByte('\'')
+	}
+}
+
+// FormatKind defines the formatting of an ICU argument.
+type FormatKind uint8
+
+const (
+	_ FormatKind = iota
+
+	FormatKindText           // {var0}
+	FormatKindTextWithGender // {var0}
+	FormatKindInteger        // {var0, number, integer}
+	FormatKindNumber         // {var0, number}
+	FormatKindCurrency       // {var0, number, ::currency/auto}
+	FormatKindOrdinal        // {var0, selectordinal, other {#th}}
+	FormatKindDateFull       // {var0, date, full}
+	FormatKindDateLong       // {var0, date, long}
+	FormatKindDateMedium     // {var0, date, medium}
+	FormatKindDateShort      // {var0, date, short}
+	FormatKindTimeFull       // {var0, time, full}
+	FormatKindTimeLong       // {var0, time, long}
+	FormatKindTimeMedium     // {var0, time, medium}
+	FormatKindTimeShort      // {var0, time, short}
+	FormatKindTimeZone       // {var0, date, ::zzzz}
+
+	// FormatKindRelativeTime uses the custom format type relativetime
+	// since ICU MessageFormat has no built-in relative time format.
+	// The runtime must register a formatter for it.
+	FormatKindRelativeTime // {var0, relativetime}
+
+	FormatKindSpellout // {var0, spellout}
+
+	FormatKindWeekOfYear // {var0, date, ::w}
+	FormatKindQuarter    // {var0, date, ::qqq}
+
+	// FormatKindNumberRange consumes the arguments index and index+1
+	// formatted as numbers and separated by an en dash since ICU
+	// MessageFormat has no range format.
+	FormatKindNumberRange // {var0, number}–{var1, number}
+
+	// FormatKindEmail and FormatKindURL are opaque strings isolated
+	// left-to-right if Config.BidiIsolateAddresses is enabled.
+	FormatKindEmail // {var0}
+	FormatKindURL   // {var0}
+
+	FormatKindUnit     // {var0, number, ::unit/kilometer}
+	FormatKindPermille // {var0, number, ::permille}
+
+	// FormatKindSkip consumes an argument without emitting anything.
+	FormatKindSkip
+
+	FormatKindDateTime // {var0, date, ::yMMMdjmm}
+)
 
-	positionalIndex := 0
+// ICUVisitor is visited by ICUTranslator.Visit for every element
+// of the ICU message generated from a TIK.
+// Returning an error from any method aborts the visit.
+type ICUVisitor interface {
+	// Literal is called for every string literal.
+	// s is the unescaped literal text and isn't ICU-escaped.
+	Literal(s string) error
 
-	for _, token := range tik.Tokens {
+	// Argument is called for every placeholder that isn't a cardinal plural.
+	Argument(index int, kind FormatKind) error
+
+	// Currency is called instead of Argument for currency placeholders
+	// declaring an ISO 4217 currency code such as `{currency:EUR}`.
+	Currency(index int, code string) error
+
+	// Ordinal is called instead of Argument for ordinal placeholders
+	// overriding the configured suffix such as `{ordinal:º}`.
+	Ordinal(index int, suffix string) error
+
+	// Unit is called instead of Argument for measurement unit placeholders
+	// with the CLDR unit identifier such as `kilometer` in `{unit:kilometer}`.
+	Unit(index int, unit string) error
+
+	// NumberSkeleton is called instead of Argument for number placeholders
+	// declaring an ICU number skeleton such as `.00` in `{number::.00}`.
+	NumberSkeleton(index int, skeleton string) error
+
+	// PluralStart is called at the start of a cardinal plural block.
+	PluralStart(index int) error
+
+	// PluralOffset is called after PluralStart if the plural has an offset.
+	PluralOffset(offset int) error
+
+	// PluralExactMatch is called at the start of an exact match arm
+	// of a cardinal plural block.
+	PluralExactMatch(value int) error
+
+	// PluralOther is called at the start of the other arm
+	// of a cardinal plural block. The other arm is always the last arm.
+	PluralOther() error
+
+	// PluralEnd is called at the end of a cardinal plural block.
+	PluralEnd() error
+
+	// MessageRef is called for every reference to another message.
+	MessageRef(key string) error
+
+	// Select is called for every select placeholder such as a boolean
+	// with its cases excluding the implicit empty `other` case.
+	// name is the declared argument name of an enum select
+	// and empty for positional selects such as booleans.
+	Select(index int, name string, cases []SelectCase) error
+
+	// Tag is called for every markup tag, where tag is either
+	// TokenTypeTagOpen, TokenTypeTagClose or TokenTypeTagSelfClosing.
+	// Tags are indexed in order of occurrence in the TIK independently
+	// of argument indexes and a closing tag has the index of its opening tag.
+	Tag(index int, name string, tag TokenType) error
+
+	// RawICU is called for every raw ICU placeholder such as
+	// `{icu:{var, number, ::percent}}` with the ICU as is.
+	// index is the positional index of the argument the raw ICU references
+	// as `{var`, or -1 if it doesn't reference an argument.
+	RawICU(index int, icu string) error
+}
+
+// SelectCase is a case of an ICU select.
+type SelectCase struct {
+	// Key is the selector such as `true`.
+	Key string
+
+	// Text is the unescaped text of the case.
+	Text string
+}
+
+// Visit calls v for every element of the ICU message generated from tik
+// in order of occurrence and assigns positional argument indexes.
+// Exact match arms of cardinal plurals are visited in canonical order
+// (see SortPluralCategories) while positional argument indexes are
+// assigned in order of occurrence in the TIK.
+// Visit returns the first error returned by v.
+func (i *ICUTranslator) Visit(tik TIK, v ICUVisitor) error {
+	_, err := visit(tik.Raw, tik.Tokens, 0,
+		tagIndexes(tik.Tokens), argNames(tik.Tokens), i.conf.CustomICU, v)
+	return err
+}
+
+// tagIndexes returns the indexes of the markup tags in tokens
+// by the start index of the tag token, or nil if there are no tags.
+func tagIndexes(tokens Tokens) map[int]int {
+	var m map[int]int
+	var open []int
+	n := 0
+	for _, t := range tokens {
+		switch t.Type {
+		case TokenTypeTagOpen, TokenTypeTagSelfClosing:
+			if m == nil {
+				m = make(map[int]int)
+			}
+			m[t.IndexStart] = n
+			if t.Type == TokenTypeTagOpen {
+				open = append(open, n)
+			}
+			n++
+		case TokenTypeTagClose:
+			m[t.IndexStart] = open[len(open)-1]
+			open = open[:len(open)-1]
+		}
+	}
+	return m
+}
+
+// visit visits tokens assigning positional indexes starting at pos
+// and returns the positional index following the last argument.
+// tags are the markup tag indexes as returned by tagIndexes,
+// names are the argument indexes of placeholder names as returned by argNames
+// and custom is Config.CustomICU.
+func visit(
+	raw string, tokens Tokens, pos int, tags map[int]int, names map[string]int,
+	custom func(Token) (string, bool), v ICUVisitor,
+) (int, error) {
+	spanner, _ := v.(tokenSpanner)
+	for ti := 0; ti < len(tokens); ti++ {
+		token := tokens[ti]
+		if spanner != nil && token.Type != TokenTypeContext {
+			spanner.enterToken(token)
+		}
+		next := -1
+		if first, ok := names[token.placeholderName()]; ok && first < pos {
+			// Reused name, visit the first argument without consuming one.
+			next, pos = pos, first
+		}
+		var err error
 		switch token.Type {
 		case TokenTypeLiteral:
-			s := token.String(tik.Raw)
-			s = replacerEscapeQuote.Replace(s)
-			i.write(s)
-		case TokenTypeText, TokenTypeTextWithGender:
-			pos := positionalIndex
-			positionalIndex++
-			i.write("{")
-			i.writePositionalPlaceholder(pos, "")
-			i.write("}")
-
-		case TokenTypeInteger:
-			pos := positionalIndex
-			positionalIndex++
-			i.write("{")
-			i.writePositionalPlaceholder(pos, "")
-			i.write(", number, integer}")
-
-		case TokenTypeNumber:
-			pos := positionalIndex
-			positionalIndex++
-			i.write("{")
-			i.writePositionalPlaceholder(pos, "")
-			i.write(", number}")
-
+			err = v.Literal(token.String(raw))
+		case TokenTypeCardinalPluralStart:
+			end := pluralEnd(tokens, ti)
+			pos, err = visitPlural(raw, tokens[ti+1:end], pos, tags, names, custom, v)
+			if err == nil {
+				err = v.PluralEnd()
+			}
+			ti = end
+		case TokenTypeMessageRef:
+			err = v.MessageRef(token.Value)
+		case TokenTypeBoolean:
+			on, off, _ := strings.Cut(token.Value, "/")
+			err = v.Select(pos, "", []SelectCase{{"true", on}, {"false", off}})
+			pos++
 		case TokenTypeCurrency:
-			pos := positionalIndex
-			positionalIndex++
-			i.write("{")
-			i.writePositionalPlaceholder(pos, "")
-			i.write(", number, ::currency/auto}")
-
-		case TokenTypeTimeFull,
-			TokenTypeTimeLong,
-			TokenTypeTimeMedium,
-			TokenTypeTimeShort,
-			TokenTypeDateFull,
-			TokenTypeDateLong,
-			TokenTypeDateMedium,
-			TokenTypeDateShort:
-			pos := positionalIndex
-			positionalIndex++
-			var varType, style string
-			switch token.Type {
-			case TokenTypeTimeFull:
-				varType, style = "time", "full"
-			case TokenTypeTimeLong:
-				varType, style = "time", "long"
-			case TokenTypeTimeMedium:
-				varType, style = "time", "medium"
-			case TokenTypeTimeShort:
-				varType, style = "time", "short"
-			case TokenTypeDateFull:
-				varType, style = "date", "full"
-			case TokenTypeDateLong:
-				varType, style = "date", "long"
-			case TokenTypeDateMedium:
-				varType, style = "date", "medium"
-			case TokenTypeDateShort:
-				varType, style = "date", "short"
-			default:
-				panic("unexpected token type")
+			if token.Value != "" {
+				err = v.Currency(pos, token.Value)
+			} else {
+				err = v.Argument(pos, FormatKindCurrency)
+			}
+			pos++
+		case TokenTypeOrdinalPlural:
+			if token.Value != "" {
+				err = v.Ordinal(pos, token.Value)
+			} else {
+				err = v.Argument(pos, FormatKindOrdinal)
 			}
+			pos++
+		case TokenTypeUnit:
+			err = v.Unit(pos, token.Value)
+			pos++
+		case TokenTypeNumberSkeleton:
+			err = v.NumberSkeleton(pos, token.Value)
+			pos++
+		case TokenTypeSelect:
+			name, options, _ := selectOptions(token.Value)
+			cases := make([]SelectCase, len(options))
+			for i, o := range options {
+				cases[i].Key = o
+			}
+			err = v.Select(pos, name, cases)
+			pos++
+		case TokenTypeTagOpen, TokenTypeTagClose, TokenTypeTagSelfClosing:
+			err = v.Tag(tags[token.IndexStart], token.Value, token.Type)
+		case TokenTypeRawICU:
+			err = v.RawICU(-1, token.Value)
+		case TokenTypeRawICUArgument:
+			err = v.RawICU(pos, token.Value)
+			pos++
+		case TokenTypeSkip:
+			pos++ // Reserve the argument without visiting it.
+		default:
+			if token.Type.IsCustom() && custom != nil {
+				if icu, ok := custom(token); ok {
+					err = v.RawICU(pos, icu)
+					pos++
+					break
+				}
+			}
+			kind := formatKind(token.Type)
+			if kind == 0 {
+				continue // Context.
+			}
+			err = v.Argument(pos, kind)
+			pos += token.Type.argCount()
+		}
+		if next != -1 {
+			pos = next
+		}
+		if err != nil {
+			return pos, err
+		}
+		if spanner != nil {
+			spanner.exitToken()
+		}
+	}
+	return pos, nil
+}
 
-			i.write("{") // Start placeholder.
-			i.writePositionalPlaceholder(pos, "")
-			i.write(", ")
-			i.write(varType)
-			i.write(", ")
-			i.write(style)
-			i.write("}")
+// tokenSpanner is optionally implemented by visitors that need to know
+// which token the visitor methods called in between originate from.
+// The methods of cardinal plural arm tokens are called within
+// the span of the cardinal plural start token.
+type tokenSpanner interface {
+	enterToken(t Token)
+	exitToken()
+}
 
-		case TokenTypeOrdinalPlural:
-			pos := positionalIndex
-			positionalIndex++
+// visitPlural visits a cardinal plural with body, which is the tokens between
+// the plural start and end, and returns the positional index following
+// the last argument inside body.
+func visitPlural(
+	raw string, body Tokens, pos int, tags map[int]int, names map[string]int,
+	custom func(Token) (string, bool), v ICUVisitor,
+) (int, error) {
+	if err := v.PluralStart(pos); err != nil {
+		return pos, err
+	}
+	pos++
+	if len(body) > 0 && body[0].Type == TokenTypePluralOffset {
+		n, _ := strconv.Atoi(body[0].Value)
+		if err := v.PluralOffset(n); err != nil {
+			return pos, err
+		}
+		body = body[1:]
+	}
+	if len(body) == 0 || body[0].Type != TokenTypePluralExactMatch {
+		// Plain cardinal plural consisting of the other arm only.
+		if err := v.PluralOther(); err != nil {
+			return pos, err
+		}
+		return visit(raw, body, pos, tags, names, custom, v)
+	}
 
-			i.write("{") // Start plural block.
-			i.writePositionalPlaceholder(pos, "")
-			i.write(", selectordinal, ")
-			i.write("other {#")
-			i.write(i.conf.OrdinalPluralOtherSuffix)
-			i.write("}}")
+	type arm struct {
+		selector Token
+		body     Tokens
+		pos      int
+	}
+	var arms []arm
+	for len(body) > 0 {
+		end := 1
+		for ; end < len(body); end++ {
+			if t := body[end].Type; t == TokenTypePluralExactMatch ||
+				t == TokenTypePluralOther {
+				break
+			} else if t == TokenTypeCardinalPluralStart {
+				end = pluralEnd(body, end)
+			}
+		}
+		arms = append(arms, arm{selector: body[0], body: body[1:end], pos: pos})
+		pos += countArguments(body[1:end], pos, names)
+		body = body[end:]
+	}
+	// The other arm is always last and remains last.
+	slices.SortStableFunc(arms[:len(arms)-1], func(a, b arm) int {
+		x, _ := strconv.Atoi(a.selector.Value)
+		y, _ := strconv.Atoi(b.selector.Value)
+		return cmp.Compare(x, y)
+	})
+	for _, a := range arms {
+		var err error
+		if a.selector.Type == TokenTypePluralOther {
+			err = v.PluralOther()
+		} else {
+			n, _ := strconv.Atoi(a.selector.Value)
+			err = v.PluralExactMatch(n)
+		}
+		if err != nil {
+			return pos, err
+		}
+		if _, err := visit(raw, a.body, a.pos, tags, names, custom, v); err != nil {
+			return pos, err
+		}
+	}
+	return pos, nil
+}
 
+// pluralEnd returns the index of the cardinal plural end token matching
+// the start token at tokens[start], or len(tokens) if there's none.
+func pluralEnd(tokens Tokens, start int) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch tokens[i].Type {
 		case TokenTypeCardinalPluralStart:
-			pos := positionalIndex
-			positionalIndex++
+			depth++
+		case TokenTypeCardinalPluralEnd:
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(tokens)
+}
 
-			i.write("{") // Start plural block.
-			i.writePositionalPlaceholder(pos, "")
-			i.write(", plural, ")
-			i.write("other {")
-			i.write("#") // Number placeholder.
+// countArguments returns the number of positional arguments tokens
+// starting at pos consume. Placeholders reusing a name of names
+// assigned before don't consume arguments.
+func countArguments(tokens Tokens, pos int, names map[string]int) (n int) {
+	for _, t := range tokens {
+		if first, ok := names[t.placeholderName()]; ok && first < pos+n {
+			continue
+		}
+		n += t.Type.argCount()
+	}
+	return n
+}
 
-		case TokenTypeCardinalPluralEnd:
-			i.write("}}") // Finish both other and plural blocks.
+// Arg is a positional argument of the ICU message generated from a TIK.
+type Arg struct {
+	// Index is the position of the argument.
+	Index int
+
+	// Type is the type of the placeholder token the argument originates from,
+	// which is TokenTypeCardinalPluralStart for cardinal plurals.
+	Type TokenType
+
+	// Kind is the formatting of the argument,
+	// or zero for cardinal plurals and selects.
+	Kind FormatKind
+
+	// Hint is the translator hint of the placeholder, if any.
+	Hint string
+
+	// Gender is true for the gender argument of a text with gender
+	// placeholder consumed by the select generated if Config.GenderSelect
+	// is enabled. Its kind is zero and its name has the `_gender` suffix.
+	Gender bool
+
+	name string
+}
+
+// Name returns the declared name of an enum select argument
+// or the name of a gender argument, otherwise the ICU argument name
+// such as "var0". The name uses Config.ICUVarPrefix for the arguments
+// returned by ICUTranslator.Args and the default prefix otherwise.
+func (a Arg) Name() string {
+	if a.name != "" {
+		return a.name
+	}
+	return argName(a.Index)
+}
+
+// Args returns the positional arguments of the ICU message generated
+// from t in the order assigned by ICUTranslator.Visit.
+// A number range yields two arguments of the same type and kind,
+// the start and the end of the range. Placeholders reusing the name
+// of an earlier placeholder share its argument and yield none.
+// Gender arguments aren't included, see ICUTranslator.Args.
+func (t TIK) Args() []Arg {
+	var args []Arg
+	for i, p := range t.Placeholders() {
+		if i < len(args) {
+			continue // Reused name.
+		}
+		for n := range p.Type.argCount() {
+			args = append(args, Arg{
+				Index: i + n, Type: p.Type, Kind: formatKind(p.Type), Hint: p.Hint,
+				name: p.selectName(),
+			})
+		}
+	}
+	return args
+}
+
+// Args is similar to TIK.Args but names arguments using Config.ICUVarPrefix.
+// If Config.GenderSelect is enabled, the positional arguments are followed
+// by the gender argument of each text with gender placeholder,
+// such as "var0_gender" for `{name}`, with indexes continuing
+// after the last positional argument.
+func (i *ICUTranslator) Args(t TIK) []Arg {
+	args := t.Args()
+	for n, a := range args {
+		// The name is only kept if it differs from the default.
+		if name := i.conf.argName(a.Index); a.name == "" && name != a.Name() {
+			args[n].name = name
+		}
+	}
+	if !i.conf.GenderSelect {
+		return args
+	}
+	for _, a := range args {
+		if a.Type == TokenTypeTextWithGender {
+			args = append(args, Arg{
+				Index: len(args), Type: a.Type, Gender: true,
+				name: i.conf.argName(a.Index) + "_gender",
+			})
+		}
+	}
+	return args
+}
+
+// ArgOrder returns the index of the first positional argument of each
+// placeholder of t in source order, such as [0 1 3] for
+// `{text} {number-range} {date-short}`, which is Arg.Index of the
+// corresponding TIK.Args. Most placeholders are generated as the ICU
+// argument `varN` of their index, except enum selects, which are generated
+// as the argument of their declared name, and skip placeholders, which
+// reserve their index without being generated.
+// Placeholders reusing a name share the argument of the first placeholder
+// of the name, such as [0 1 0] for `{text:user} and {name} met {text:user}`.
+func (t TIK) ArgOrder() []int {
+	var order []int
+	for i := range t.Placeholders() {
+		order = append(order, i)
+	}
+	return order
+}
+
+func formatKind(t TokenType) FormatKind {
+	switch t {
+	case TokenTypeText, TokenTypeStringPlaceholder, TokenTypePhone:
+		return FormatKindText
+	case TokenTypeTextWithGender:
+		return FormatKindTextWithGender
+	case TokenTypeInteger:
+		return FormatKindInteger
+	case TokenTypeNumber, TokenTypeNumberSkeleton:
+		return FormatKindNumber
+	case TokenTypeCurrency:
+		return FormatKindCurrency
+	case TokenTypeTimeZone:
+		return FormatKindTimeZone
+	case TokenTypeRelativeTime:
+		return FormatKindRelativeTime
+	case TokenTypeNumberSpellout:
+		return FormatKindSpellout
+	case TokenTypeWeekOfYear:
+		return FormatKindWeekOfYear
+	case TokenTypeQuarter:
+		return FormatKindQuarter
+	case TokenTypeNumberRange:
+		return FormatKindNumberRange
+	case TokenTypeEmail:
+		return FormatKindEmail
+	case TokenTypeURL:
+		return FormatKindURL
+	case TokenTypeUnit:
+		return FormatKindUnit
+	case TokenTypePermille:
+		return FormatKindPermille
+	case TokenTypeSkip:
+		return FormatKindSkip
+	case TokenTypeDateTime:
+		return FormatKindDateTime
+	case TokenTypeOrdinalPlural:
+		return FormatKindOrdinal
+	case TokenTypeDateFull:
+		return FormatKindDateFull
+	case TokenTypeDateLong:
+		return FormatKindDateLong
+	case TokenTypeDateMedium:
+		return FormatKindDateMedium
+	case TokenTypeDateShort:
+		return FormatKindDateShort
+	case TokenTypeTimeFull:
+		return FormatKindTimeFull
+	case TokenTypeTimeLong:
+		return FormatKindTimeLong
+	case TokenTypeTimeMedium:
+		return FormatKindTimeMedium
+	case TokenTypeTimeShort:
+		return FormatKindTimeShort
+	}
+	if t.IsCustom() {
+		return FormatKindText
+	}
+	return 0
+}
+
+// bidiIsolate returns the isolate initiator arguments of the given kind
+// are wrapped in, or an empty string if they aren't isolated.
+// Emails and URLs are always left-to-right and are isolated as such
+// if Config.BidiIsolateAddresses is enabled, text of unknown direction
+// is isolated with FIRST STRONG ISOLATE (U+2068).
+func (c Config) bidiIsolate(kind FormatKind) string {
+	switch kind {
+	case FormatKindEmail, FormatKindURL:
+		if c.BidiIsolateAddresses {
+			return "\u2066"
+		}
+		fallthrough
+	case FormatKindText, FormatKindTextWithGender:
+		if c.BidiIsolatePlaceholders {
+			return "\u2068"
+		}
+	}
+	return ""
+}
+
+// icuWriter is the ICUVisitor writing the ICU message to the translator buffer.
+type icuWriter ICUTranslator
+
+func (w *icuWriter) Literal(s string) error {
+	writeICULiteral(&w.b, s, len(w.pluralArmOpen) > 0, len(w.conf.MarkupTags) > 0)
+	return nil
+}
+
+func (w *icuWriter) Argument(index int, kind FormatKind) error {
+	i := (*ICUTranslator)(w)
+	if kind != FormatKindTextWithGender || !i.conf.GenderSelect {
+		return w.argument(index, kind)
+	}
+	// Every arm repeats the argument to keep the message correct
+	// until translators adjust the arms.
+	i.write("{")
+	i.writePositionalPlaceholder(index, "_gender, select,")
+	arm := func(category string) error {
+		i.write(" " + category + " {")
+		defer i.write("}")
+		return w.argument(index, kind)
+	}
+	categories := slices.Clone(i.conf.genderCategories())
+	SortGenderCategories(categories) // The other arm is always last.
+	for _, c := range categories {
+		if err := arm(c); err != nil {
+			return err
 		}
 	}
+	i.write("}")
+	return nil
+}
 
+func (w *icuWriter) argument(index int, kind FormatKind) error {
+	i := (*ICUTranslator)(w)
+	if isolate := i.conf.bidiIsolate(kind); isolate != "" {
+		i.write(isolate + "{")
+		i.writePositionalPlaceholder(index, "}\u2069")
+		return nil
+	}
+	if kind == FormatKindEmail || kind == FormatKindURL {
+		kind = FormatKindText
+	}
+	i.write("{") // Start placeholder.
+	i.writePositionalPlaceholder(index, "")
+	switch kind {
+	case FormatKindText, FormatKindTextWithGender:
+	case FormatKindInteger:
+		i.write(", number, integer")
+	case FormatKindNumber:
+		i.write(", number")
+	case FormatKindCurrency:
+		i.write(", number, ::currency/auto")
+	case FormatKindOrdinal:
+		i.write(", selectordinal, other {#")
+		i.write(i.conf.OrdinalPluralOtherSuffix)
+		i.write("}")
+	case FormatKindDateFull:
+		i.write(", date, full")
+	case FormatKindDateLong:
+		i.write(", date, long")
+	case FormatKindDateMedium:
+		i.write(", date, medium")
+	case FormatKindDateShort:
+		i.write(", date, short")
+	case FormatKindTimeFull:
+		i.write(", time, full")
+	case FormatKindTimeLong:
+		i.write(", time, long")
+	case FormatKindTimeMedium:
+		i.write(", time, medium")
+	case FormatKindTimeShort:
+		i.write(", time, short")
+	case FormatKindRelativeTime:
+		i.write(", relativetime")
+	case FormatKindSpellout:
+		i.write(", spellout")
+	case FormatKindWeekOfYear:
+		i.write(", date, ::w")
+	case FormatKindQuarter:
+		i.write(", date, ::qqq")
+	case FormatKindDateTime:
+		i.write(", date, ::yMMMdjmm")
+	case FormatKindPermille:
+		i.write(", number, ::permille")
+	case FormatKindNumberRange:
+		i.write(", number}–{")
+		i.writePositionalPlaceholder(index+1, ", number")
+	case FormatKindTimeZone:
+		i.write(", date, ::")
+		i.write(i.conf.timeZoneSkeleton())
+	default:
+		panic("unexpected format kind")
+	}
+	i.write("}")
+	return nil
+}
+
+func (w *icuWriter) PluralStart(index int) error {
+	i := (*ICUTranslator)(w)
+	i.write("{") // Start plural block.
+	i.writePositionalPlaceholder(index, "")
+	i.write(", plural, ")
+	i.pluralArmOpen = append(i.pluralArmOpen, false)
+	i.pluralOtherStart = append(i.pluralOtherStart, [2]int{})
+	return nil
+}
+
+func (w *icuWriter) PluralOffset(offset int) error {
+	w.b.WriteString("offset:")
+	w.b.WriteString(strconv.Itoa(offset))
+	w.b.WriteString(" ")
+	return nil
+}
+
+// openPluralArm closes the previous arm of the current plural, if any.
+func (w *icuWriter) openPluralArm() {
+	top := &w.pluralArmOpen[len(w.pluralArmOpen)-1]
+	if *top {
+		w.b.WriteString("} ")
+	}
+	*top = true
+}
+
+func (w *icuWriter) PluralExactMatch(value int) error {
+	w.openPluralArm()
+	w.b.WriteString("=")
+	w.b.WriteString(strconv.Itoa(value))
+	w.b.WriteString(" {")
+	return nil
+}
+
+func (w *icuWriter) PluralOther() error {
+	w.openPluralArm()
+	top := &w.pluralOtherStart[len(w.pluralOtherStart)-1]
+	top[0] = w.b.Len()
+	for _, c := range w.pluralCategories {
+		if c == "other" || c == "one" && w.conf.ScaffoldEnglishPlural {
+			continue
+		}
+		w.b.WriteString(c)
+		w.b.WriteString(" {} ")
+	}
+	top[1] = w.b.Len()
+	w.b.WriteString("other {")
+	w.b.WriteString("#") // Number placeholder.
+	return nil
+}
+
+func (w *icuWriter) PluralEnd() error {
+	w.pluralArmOpen = w.pluralArmOpen[:len(w.pluralArmOpen)-1]
+	start := w.pluralOtherStart[len(w.pluralOtherStart)-1]
+	w.pluralOtherStart = w.pluralOtherStart[:len(w.pluralOtherStart)-1]
+	if w.conf.ScaffoldEnglishPlural {
+		// Duplicate the content of the other arm as the one arm
+		// preceding the arms of the other categories.
+		arms := bytes.Clone(w.b.Bytes()[start[0]:])
+		w.b.Truncate(start[0])
+		w.b.WriteString("one {")
+		w.b.Write(arms[start[1]-start[0]+len("other {"):])
+		w.b.WriteString("} ")
+		w.b.Write(arms)
+	}
+	w.b.WriteString("}}") // Finish both other and plural blocks.
+	return nil
+}
+
+func (w *icuWriter) Currency(index int, code string) error {
+	i := (*ICUTranslator)(w)
+	i.write("{")
+	i.writePositionalPlaceholder(index, ", number, ::currency/")
+	i.write(code)
+	i.write("}")
+	return nil
+}
+
+func (w *icuWriter) Ordinal(index int, suffix string) error {
+	i := (*ICUTranslator)(w)
+	i.write("{")
+	i.writePositionalPlaceholder(index, ", selectordinal, other {#")
+	i.write(suffix)
+	i.write("}}")
+	return nil
+}
+
+func (w *icuWriter) Unit(index int, unit string) error {
+	i := (*ICUTranslator)(w)
+	i.write("{")
+	i.writePositionalPlaceholder(index, ", number, ::unit/")
+	i.write(unit)
+	i.write("}")
+	return nil
+}
+
+func (w *icuWriter) NumberSkeleton(index int, skeleton string) error {
+	i := (*ICUTranslator)(w)
+	i.write("{")
+	i.writePositionalPlaceholder(index, ", number, ::")
+	i.write(skeleton)
+	i.write("}")
+	return nil
+}
+
+// markupTag returns the HTML-like markup of a tag such as `<a>`,
+// `</a>` or `<br/>`.
+func markupTag(name string, tag TokenType) string {
+	switch tag {
+	case TokenTypeTagOpen:
+		return "<" + name + ">"
+	case TokenTypeTagClose:
+		return "</" + name + ">"
+	}
+	return "<" + name + "/>"
+}
+
+// Tag writes the markup tag as an ICU tag named by its index
+// such as `<0>` for the runtime to associate with an element.
+func (w *icuWriter) Tag(index int, _ string, tag TokenType) error {
+	n := strconv.Itoa(index)
+	switch tag {
+	case TokenTypeTagOpen:
+		w.b.WriteString("<" + n + ">")
+	case TokenTypeTagClose:
+		w.b.WriteString("</" + n + ">")
+	default:
+		w.b.WriteString("<" + n + "/>")
+	}
+	return nil
+}
+
+// RawICU writes icu verbatim replacing references to its argument
+// by the argument name. Raw ICU is unchecked and may produce
+// an invalid ICU message.
+func (w *icuWriter) RawICU(index int, icu string) error {
+	if index == -1 {
+		w.b.WriteString(icu)
+		return nil
+	}
+	name := w.conf.argName(index)
+	for {
+		i := strings.Index(icu, "{var")
+		if i == -1 {
+			break
+		}
+		w.b.WriteString(icu[:i+1])
+		icu = icu[i+len("{var"):]
+		if icu != "" && (icu[0] == ',' || icu[0] == '}') {
+			w.b.WriteString(name)
+		} else {
+			w.b.WriteString("var")
+		}
+	}
+	w.b.WriteString(icu)
+	return nil
+}
+
+// MessageRef writes the message reference as is for the runtime to resolve.
+func (w *icuWriter) MessageRef(key string) error {
+	w.b.WriteString("{@")
+	w.b.WriteString(key)
+	w.b.WriteString("}")
+	return nil
+}
+
+func (w *icuWriter) Select(index int, name string, cases []SelectCase) error {
+	i := (*ICUTranslator)(w)
+	i.write("{")
+	if name != "" {
+		i.write(name)
+		i.write(", select, ")
+	} else {
+		i.writePositionalPlaceholder(index, ", select, ")
+	}
+	for _, c := range cases {
+		i.write(c.Key)
+		i.write(" {")
+		writeICULiteral(&i.b, c.Text, false, len(i.conf.MarkupTags) > 0)
+		i.write("} ")
+	}
+	i.write("other {}}")
+	return nil
+}
+
+// Span is the range of an ICU message generated from the token of a TIK.
+type Span struct {
+	// Start and End are the byte offsets of the range in the ICU message.
+	Start, End int
+
+	// Token is the originating token. Token.IndexStart and Token.IndexEnd
+	// are the byte offsets of the token in the TIK.
+	Token Token
+}
+
+// spanWriter is an icuWriter recording the spans of the tokens it writes.
+type spanWriter struct {
+	*icuWriter
+	spans []Span
+	open  []int
+}
+
+func (w *spanWriter) enterToken(t Token) {
+	w.open = append(w.open, len(w.spans))
+	w.spans = append(w.spans, Span{Start: w.b.Len(), Token: t})
+}
+
+func (w *spanWriter) exitToken() {
+	w.spans[w.open[len(w.open)-1]].End = w.b.Len()
+	w.open = w.open[:len(w.open)-1]
+}
+
+// PluralEnd shifts the spans of the other arm behind the one arm
+// scaffolded by Config.ScaffoldEnglishPlural.
+func (w *spanWriter) PluralEnd() error {
+	start, n := w.pluralOtherStart[len(w.pluralOtherStart)-1][0], w.b.Len()
+	_ = w.icuWriter.PluralEnd()
+	if !w.conf.ScaffoldEnglishPlural {
+		return nil
+	}
+	delta := w.b.Len() - n - len("}}")
+	for i := range w.spans {
+		if w.spans[i].Start > start {
+			w.spans[i].Start += delta
+			w.spans[i].End += delta
+		}
+	}
+	return nil
+}
+
+// TIK2ICUMapped is similar to TIK2ICU but also returns the spans of the ICU
+// message that literals, placeholders, message references and markup tags
+// are translated to, in order of the ICU message.
+// The span of a cardinal plural start token covers the entire plural,
+// while its arms and the plural end token have no spans of their own.
+// Spans account for ICU escaping of literals, hence the span of a literal
+// may be longer than the literal itself.
+func (i *ICUTranslator) TIK2ICUMapped(tik TIK) (icu string, spans []Span) {
+	i.b.Reset()
+	i.pluralArmOpen, i.pluralOtherStart = i.pluralArmOpen[:0], i.pluralOtherStart[:0]
+	w := spanWriter{icuWriter: (*icuWriter)(i)}
+	_ = i.Visit(tik, &w) // icuWriter never returns an error.
+	return i.b.String(), w.spans
+}
+
+// TIK2ICUBuf similar TIK2ICU but gives temporary access to the internal buffer
+// to avoid string allocation if only a temporary byte slice is needed.
+// This function can be used instead TIK2ICU to achieve efficiency when possible
+// but must be used with caution!
+//
+// WARNING: Never use or alias buf outside fn!
+func (i *ICUTranslator) TIK2ICUBuf(
+	tik TIK, fn func(buf *bytes.Buffer),
+) {
+	i.b.Reset()
+	i.pluralArmOpen, i.pluralOtherStart = i.pluralArmOpen[:0], i.pluralOtherStart[:0]
+	_ = i.Visit(tik, (*icuWriter)(i)) // icuWriter never returns an error.
 	fn(&i.b)
 }
 
@@ -151,3 +996,103 @@ func (i *ICUTranslator) TIK2ICU(tik TIK) (str string) {
 	i.TIK2ICUBuf(tik, func(buf *bytes.Buffer) { str = buf.String() })
 	return str
 }
+
+var replacerICUComment = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// TIK2ICUWithComment is similar to TIK2ICU but also returns the context
+// of tik as a translator comment for catalogs that support message
+// descriptions, or an empty string if tik has no context.
+// Since ICU messages have no comment syntax, the comment is never part
+// of the ICU message. Line breaks in the comment are replaced by spaces
+// such that it fits single-line comments such as `#.` in PO files.
+func (i *ICUTranslator) TIK2ICUWithComment(tik TIK) (icu, comment string) {
+	return i.TIK2ICU(tik), replacerICUComment.Replace(tik.Context())
+}
+
+// TIK2ICUTo is similar to TIK2ICU but writes the ICU message to w
+// without allocating a string.
+// Returns the number of bytes written and the error returned by w, if any.
+func (i *ICUTranslator) TIK2ICUTo(w io.Writer, tik TIK) (n int, err error) {
+	i.TIK2ICUBuf(tik, func(buf *bytes.Buffer) { n, err = w.Write(buf.Bytes()) })
+	return n, err
+}
+
+// ArgHints returns the translator hints of tik by ICU argument name
+// for catalogs that support argument descriptions, or nil if tik has no hints.
+// Hints are never part of the ICU message itself.
+func (i *ICUTranslator) ArgHints(tik TIK) map[string]string {
+	var m map[string]string
+	for index, p := range tik.Placeholders() {
+		if p.Hint == "" {
+			continue
+		}
+		name := p.selectName()
+		if name == "" {
+			name = i.conf.argName(index)
+		}
+		if _, ok := m[name]; ok {
+			continue // Reused name, the first hint applies.
+		}
+		if m == nil {
+			m = make(map[string]string)
+		}
+		m[name] = p.Hint
+	}
+	return m
+}
+
+// selectName returns the declared argument name of an enum select token
+// or an empty string for any other token.
+func (t Token) selectName() string {
+	if t.Type != TokenTypeSelect {
+		return ""
+	}
+	name, _, _ := selectOptions(t.Value)
+	return name
+}
+
+// ICUChange is a difference in the ICU message generated for a TIK
+// under two different configurations.
+type ICUChange struct {
+	// Index is the index of the TIK in the slice passed to DiffICU.
+	Index int
+	TIK   TIK
+
+	// Old and New are the ICU messages generated under the old
+	// and the new configuration respectively.
+	Old, New string
+}
+
+// DiffICU translates every TIK in tiks to ICU under both oldConf and newConf
+// and returns the changes for all TIKs for which the ICU messages differ.
+func DiffICU(tiks []TIK, oldConf, newConf Config) []ICUChange {
+	var changes []ICUChange
+	o, n := NewICUTranslator(oldConf), NewICUTranslator(newConf)
+	for i, tik := range tiks {
+		o.TIK2ICUBuf(tik, func(oldBuf *bytes.Buffer) {
+			n.TIK2ICUBuf(tik, func(newBuf *bytes.Buffer) {
+				if bytes.Equal(oldBuf.Bytes(), newBuf.Bytes()) {
+					return
+				}
+				changes = append(changes, ICUChange{
+					Index: i,
+					TIK:   tik,
+					Old:   oldBuf.String(),
+					New:   newBuf.String(),
+				})
+			})
+		})
+	}
+	return changes
+}
+
+// TIK2ICUWithPluralCategories is similar to TIK2ICU but scaffolds cardinal
+// plurals with empty branches for the CLDR plural categories in canonical
+// order, such as `{var0, plural, one {} few {} many {} other {# messages}}`
+// for one, few, many and other. cldr.TIK2ICUForLocale of package
+// github.com/romshark/tik/tik-go/cldr scaffolds the categories of a locale.
+func (i *ICUTranslator) TIK2ICUWithPluralCategories(tik TIK, categories []string) string {
+	i.pluralCategories = categories
+	defer func() { i.pluralCategories = nil }()
+	return i.TIK2ICU(tik)
+}
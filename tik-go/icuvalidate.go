@@ -0,0 +1,237 @@
+package tik
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrICUInvalid = errors.New("invalid ICU message")
+
+// ValidateICU returns a ParseError with ErrICUInvalid if msg isn't
+// a syntactically valid ICU MessageFormat message, such as when curly
+// braces aren't balanced, an argument is malformed or a plural or select
+// lacks the other case. Apostrophes are interpreted the way ICU does
+// by default: a doubled apostrophe is literal and a single apostrophe only
+// starts quoted text when followed by `{`, `}` or `#` inside plurals.
+// Argument names must be ICU identifiers or numbers and simple argument
+// styles such as `::currency/auto` aren't checked.
+// The choice argument type isn't supported.
+// Message reference markers such as `{@help.intro}`, which TIK2ICU
+// writes for the runtime to resolve, are accepted if the key is valid
+// although they aren't ICU.
+//
+// ValidateICU is meant as a self-check of generated messages, for example
+// the output of ICUTranslator.TIK2ICU, which is always valid unless
+// it contains raw ICU.
+func ValidateICU(msg string) error {
+	end, e := validateICUMessage(msg, 0, false)
+	if e != nil {
+		return e
+	}
+	if end != len(msg) {
+		return err(end, ErrICUInvalid) // Unexpected '}'.
+	}
+	return nil
+}
+
+// validateICUMessage validates the message text of s starting at i
+// and returns the index of the `}` terminating it or len(s).
+// inPlural is true for the messages of plural and selectordinal cases.
+func validateICUMessage(s string, i int, inPlural bool) (int, error) {
+	for i < len(s) {
+		switch s[i] {
+		case '\'':
+			i = skipICUQuote(s, i, inPlural)
+		case '{':
+			end, e := validateICUArgument(s, i)
+			if e != nil {
+				return 0, e
+			}
+			i = end
+		case '}':
+			return i, nil
+		default:
+			i++
+		}
+	}
+	return i, nil
+}
+
+// skipICUQuote returns the index following the apostrophe at s[i]
+// and the text quoted by it, if any. Quoted text that isn't closed
+// extends to the end of s.
+func skipICUQuote(s string, i int, inPlural bool) int {
+	if i+1 >= len(s) {
+		return i + 1
+	}
+	switch c := s[i+1]; {
+	case c == '\'':
+		return i + 2 // Literal apostrophe.
+	case c != '{' && c != '}' && (c != '#' || !inPlural):
+		return i + 1 // Literal apostrophe.
+	}
+	for i += 2; i < len(s); i++ {
+		if s[i] != '\'' {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '\'' {
+			i++ // Doubled apostrophe in quoted text.
+			continue
+		}
+		return i + 1
+	}
+	return i
+}
+
+// validateICUArgument validates the argument starting with the `{`
+// at s[start] and returns the index following its closing `}`.
+func validateICUArgument(s string, start int) (int, error) {
+	if strings.HasPrefix(s[start+1:], "@") {
+		end := strings.IndexByte(s[start:], '}')
+		if end == -1 || !isValidMessageRefKey(s[start+2:start+end]) {
+			return 0, err(start, ErrICUInvalid)
+		}
+		return start + end + 1, nil // Message reference marker.
+	}
+	i := skipSpace(s, start+1)
+	name, i := cutICUWord(s, i)
+	if !isICUIdentifier(name) && !isICUNumber(name, false) {
+		return 0, err(i-len(name), ErrICUInvalid)
+	}
+	if i = skipSpace(s, i); i < len(s) && s[i] == '}' {
+		return i + 1, nil // Simple argument such as {var0}.
+	}
+	if i >= len(s) || s[i] != ',' {
+		return 0, err(i, ErrICUInvalid)
+	}
+	argType, i := cutICUWord(s, skipSpace(s, i+1))
+	if !isICUIdentifier(argType) || argType == "choice" {
+		return 0, err(i-len(argType), ErrICUInvalid)
+	}
+	i = skipSpace(s, i)
+	switch {
+	case i >= len(s):
+		return 0, err(start, ErrICUInvalid) // Unclosed.
+	case s[i] == '}':
+		return i + 1, nil // Argument without style such as {var0, number}.
+	case s[i] != ',':
+		return 0, err(i, ErrICUInvalid)
+	}
+	switch argType {
+	case "plural", "selectordinal":
+		return validateICUCases(s, start, i+1, true)
+	case "select":
+		return validateICUCases(s, start, i+1, false)
+	}
+
+	// Simple style such as `::currency/auto`, which may contain
+	// balanced curly braces and quoted text.
+	i, depth := skipSpace(s, i+1), 0
+	if i < len(s) && s[i] == '}' {
+		return 0, err(i, ErrICUInvalid) // Empty style.
+	}
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			if j := strings.IndexByte(s[i+1:], '\''); j != -1 {
+				i += j + 1
+			} else {
+				i = len(s)
+			}
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i + 1, nil
+			}
+			depth--
+		}
+	}
+	return 0, err(start, ErrICUInvalid) // Unclosed.
+}
+
+// validateICUCases validates the offset and the cases of the plural,
+// selectordinal or select argument starting with the `{` at s[start]
+// from s[i] on and returns the index following its closing `}`.
+func validateICUCases(s string, start, i int, plural bool) (int, error) {
+	i = skipSpace(s, i)
+	if v, ok := strings.CutPrefix(s[i:], "offset:"); ok && plural {
+		offset, _ := cutICUWord(v, skipSpace(v, 0))
+		if !isICUNumber(offset, false) {
+			return 0, err(i, ErrICUInvalid)
+		}
+		i = len(s) - len(v) + skipSpace(v, 0) + len(offset)
+	}
+	hasOther := false
+	for {
+		i = skipSpace(s, i)
+		if i >= len(s) {
+			return 0, err(start, ErrICUInvalid) // Unclosed.
+		}
+		if s[i] == '}' {
+			if !hasOther {
+				return 0, err(start, ErrICUInvalid)
+			}
+			return i + 1, nil
+		}
+		selector, end := cutICUWord(s, i)
+		switch {
+		case selector == "other":
+			hasOther = true
+		case plural && strings.HasPrefix(selector, "="):
+			if !isICUNumber(selector[1:], true) {
+				return 0, err(i, ErrICUInvalid)
+			}
+		case !isICUIdentifier(selector):
+			return 0, err(i, ErrICUInvalid)
+		}
+		i = skipSpace(s, end)
+		if i >= len(s) || s[i] != '{' {
+			return 0, err(i, ErrICUInvalid)
+		}
+		end, e := validateICUMessage(s, i+1, plural)
+		if e != nil {
+			return 0, e
+		}
+		if end >= len(s) {
+			return 0, err(i, ErrICUInvalid) // Unclosed.
+		}
+		i = end + 1
+	}
+}
+
+// cutICUWord returns the word starting at s[i] up to the next whitespace,
+// `,`, `{` or `}` and the index following it.
+func cutICUWord(s string, i int) (word string, end int) {
+	end = i
+	for end < len(s) {
+		r, size := utf8.DecodeRuneInString(s[end:])
+		if unicode.IsSpace(r) || r == ',' || r == '{' || r == '}' {
+			break
+		}
+		end += size
+	}
+	return s[i:end], end
+}
+
+// isICUNumber returns true if s is a non-empty sequence of ASCII digits,
+// which may be signed and contain a decimal point if decimal is true.
+func isICUNumber(s string, decimal bool) bool {
+	if decimal {
+		s = strings.TrimPrefix(s, "-")
+	}
+	digits, point := 0, false
+	for i := range len(s) {
+		switch {
+		case s[i] >= '0' && s[i] <= '9':
+			digits++
+		case s[i] == '.' && decimal && !point && digits > 0:
+			point = true
+		default:
+			return false
+		}
+	}
+	return digits > 0 && s[len(s)-1] != '.'
+}
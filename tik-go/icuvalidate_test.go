@@ -0,0 +1,160 @@
+package tik_test
+
+import (
+	"testing"
+
+	"github.com/romshark/tik/tik-go"
+)
+
+func TestValidateICU(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, msg string) {
+		t.Helper()
+		requireNoErr(t, tik.ValidateICU(msg))
+	}
+
+	f(t, "")
+	f(t, "hello world")
+	f(t, "it''s {var0}")
+	f(t, "it's # {var0} | ok") // Single apostrophes and '#' are literal.
+	f(t, "'{'curly'}' and '{}' braces")
+	f(t, "'{ it''s quoted }'")
+	f(t, "'{unclosed quote extends to the end")
+	f(t, "{ var0 }{0}")
+	f(t, "{var0, number}{var1, number, integer}{var2, date, short}")
+	f(t, "{var0, number, ::currency/auto}{var1, date, ::yMMMdjmm}")
+	f(t, "{var0, number, '{'quoted'}' {nested}}")
+	f(t, "{var0, plural, other {# files}}")
+	f(t, "{var0, plural, offset:1 =0 {nobody} =1 {just {var1}} one {# other} other {# others}}")
+	f(t, "{var0, plural, offset: 2 =-1.5 {x} other {y}}")
+	f(t, "{var0, plural, other {'#' is '{'quoted'}' but ''#'' isn''t}}")
+	f(t, "{var0, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}")
+	f(t, "{var0_gender, select, female {} male {} other {{var0}}} arrived")
+	f(t, "{var0, plural, other {{var1, select, yes {#} other {no}}}}")
+	f(t, "{status, select, pending {Pending} other {Unknown}}")
+	f(t, "see {@help.intro} and {@faq}")
+	f(t, "{var0, plural, other {# see {@help-1.a_b}}}")
+}
+
+func TestValidateICUErr(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, expectIndex int, msg string) {
+		t.Helper()
+		err := tik.ValidateICU(msg)
+		requireErrIs(t, tik.ErrICUInvalid, err)
+		requireEqual(t, expectIndex, err.(tik.ParseError).Index)
+	}
+
+	f(t, 6, "hello }")
+	f(t, 1, "{")
+	f(t, 7, "hello {")
+	f(t, 1, "{}")
+	f(t, 2, "{ }")
+	f(t, 1, "{0var}")
+	f(t, 6, "{var0 var1}")
+	f(t, 6, "{var0,")
+	f(t, 7, "{var0, }")
+	f(t, 7, "{var0, choice, 0#none|1#one}")
+	f(t, 14, "{var0, number integer}")
+	f(t, 15, "{var0, number, }")
+	f(t, 0, "{var0, number, {unbalanced}")
+	f(t, 0, "{var0, number, 'quoted}'")
+	f(t, 0, "{var0, plural, one {# file}}")
+	f(t, 0, "{var0, select, }")
+	f(t, 0, "{var0, plural, other {# files}")
+	f(t, 21, "{var0, plural, other {# files")
+	f(t, 20, "{var0, plural, other}")
+	f(t, 21, "{var0, plural, other # files}")
+	f(t, 15, "{var0, plural, =x {none} other {# files}}")
+	f(t, 15, "{var0, select, =0 {none} other {files}}")
+	f(t, 15, "{var0, plural, offset:x other {# files}}")
+	f(t, 15, "{var0, plural, one-or-two {#} other {# files}}")
+	f(t, 6, "it's {")
+	f(t, 25, "{var0, plural, other {# {}}}")
+	f(t, 4, "see {@}")
+	f(t, 4, "see {@a..b}")
+	f(t, 4, "see {@a b}")
+	f(t, 4, "see {@help")
+}
+
+func TestValidateICUTIK2ICU(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.MarkupTags = []string{"b"}
+	conf.GenderSelect = true
+	conf.AllowNestedPlural = true
+	p := tik.NewParser(conf)
+	translator := tik.NewICUTranslator(conf)
+
+	f := func(t *testing.T, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		icu := translator.TIK2ICU(tk)
+		if err := tik.ValidateICU(icu); err != nil {
+			t.Fatalf("%v: %q", err, icu)
+		}
+	}
+
+	f(t, `it's '{text}' o'clock`)
+	f(t, `'''' ''{name}'' '`)
+	f(t, `\{ it's \} not a placeholder \{\{ \}\} #`)
+	f(t, `# {# it's #1, '#', \{#\} and '' {integer} #s}`)
+	f(t, `{# |=0 '\{'none'\}' | '# {text}' \} ''}`)
+	f(t, `{# offset:1 |=0 nobody' | all of {name} and # '\{others\}'}`)
+	f(t, `{# files {# '#' \{'\}} in #'s}`)
+	f(t, `{<b>}it's {text}{</b>} #'{ordinal}'# {currency:EUR}`)
+	f(t, `{select:status(pending,shipped)}'s {bool:yes/no} {"it's \"#\" \{"}`)
+	f(t, `{# 'x {number::.0#}' {unit:kilometer}}`)
+}
+
+func TestValidateICUTokenTypes(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.MarkupTags = []string{"a", "br"}
+	conf.GenderSelect = true
+	conf.AllowNestedPlural = true
+	p := tik.NewParser(conf)
+	translator := tik.NewICUTranslator(conf)
+
+	inputs := []string{
+		`[context] {# offset:1 |=0 none |=1 one {# in {text}} | many}`,
+		`{ordinal:.} {currency:EUR} {@help.intro} {bool:on/off}`,
+		`{select:status(open,closed)} {"John"} {<a>}x{</a>}{<br/>}`,
+		`{unit:kilometer} {number::.00}`,
+	}
+	// Every placeholder that can be written by keyword alone.
+	for tp := tik.TokenType(1); tp < tik.TokenTypeCustom; tp++ {
+		if keyword, ok := conf.KeywordFor(tp); ok && tp != tik.TokenTypeCardinalPluralStart {
+			inputs = append(inputs, "{"+keyword+"}")
+		}
+	}
+
+	seen := map[tik.TokenType]bool{}
+	for _, input := range inputs {
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		for _, token := range tk.Tokens {
+			seen[token.Type] = true
+		}
+		icu := translator.TIK2ICU(tk)
+		if err := tik.ValidateICU(icu); err != nil {
+			t.Fatalf("%v: %q", err, icu)
+		}
+	}
+
+	// Raw ICU is emitted verbatim and may be invalid.
+	for tp := tik.TokenType(1); tp < tik.TokenTypeCustom; tp++ {
+		if tp.String() == "unknown" ||
+			tp == tik.TokenTypeRawICU || tp == tik.TokenTypeRawICUArgument {
+			continue
+		}
+		if !seen[tp] {
+			t.Errorf("token type %v not covered", tp)
+		}
+	}
+}
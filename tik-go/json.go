@@ -0,0 +1,191 @@
+package tik
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"unicode/utf8"
+)
+
+// tokenJSON is the stable JSON representation of Token.
+type tokenJSON struct {
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	Type     uint8  `json:"type"`
+	TypeName string `json:"typeName"`
+	Value    string `json:"value,omitempty"`
+	Hint     string `json:"hint,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t Token) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tokenJSON{
+		Start:    t.IndexStart,
+		End:      t.IndexEnd,
+		Type:     uint8(t.Type),
+		TypeName: t.Type.String(),
+		Value:    t.Value,
+		Hint:     t.Hint,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Token) UnmarshalJSON(data []byte) error {
+	var v tokenJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	tp := TokenType(v.Type)
+	if tp.String() == "unknown" {
+		return fmt.Errorf("unknown token type: %d", v.Type)
+	}
+	if v.TypeName != "" && v.TypeName != tp.String() {
+		return fmt.Errorf("token type name %q doesn't match type %d (%s)",
+			v.TypeName, v.Type, tp.String())
+	}
+	if v.Start < 0 || v.End < v.Start {
+		return fmt.Errorf("invalid token range: %d-%d", v.Start, v.End)
+	}
+	*t = Token{
+		IndexStart: v.Start, IndexEnd: v.End, Type: tp, Value: v.Value, Hint: v.Hint,
+	}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+// The text is the name returned by String, such as "date medium".
+// Returns an error for undefined token types.
+func (t TokenType) MarshalText() ([]byte, error) {
+	s := t.String()
+	if s == "unknown" {
+		return nil, fmt.Errorf("unknown token type: %d", uint8(t))
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// It accepts the names returned by String except "unknown".
+func (t *TokenType) UnmarshalText(text []byte) error {
+	for tp := TokenType(1); tp.String() != "unknown"; tp++ {
+		if tp.String() == string(text) {
+			*t = tp
+			return nil
+		}
+	}
+	var n uint8
+	if _, err := fmt.Sscanf(string(text), "custom %d", &n); err == nil &&
+		n < 128 && (TokenTypeCustom+TokenType(n)).String() == string(text) {
+		*t = TokenTypeCustom + TokenType(n)
+		return nil
+	}
+	return fmt.Errorf("unknown token type: %q", text)
+}
+
+// MarshalJSON implements json.Marshaler.
+// The token type is encoded as a string such as "date medium".
+func (t TokenType) MarshalJSON() ([]byte, error) {
+	s, err := t.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(s))
+}
+
+// tikJSON is the stable JSON representation of TIK.
+type tikJSON struct {
+	Raw    string `json:"raw"`
+	Tokens Tokens `json:"tokens"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t TIK) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tikJSON{Raw: t.Raw, Tokens: t.Tokens})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// It returns an error if any token range is out of bounds of the raw TIK.
+func (t *TIK) UnmarshalJSON(data []byte) error {
+	var v tikJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	for _, tok := range v.Tokens {
+		if tok.IndexEnd > len(v.Raw) {
+			return fmt.Errorf("token range %d-%d out of bounds",
+				tok.IndexStart, tok.IndexEnd)
+		}
+	}
+	*t = TIK{Raw: v.Raw, Tokens: v.Tokens}
+	return nil
+}
+
+// configFields has the fields of Config without its methods
+// to avoid recursion in Config.MarshalJSON and Config.UnmarshalJSON.
+type configFields Config
+
+// configJSON is the stable JSON representation of Config.
+// The fields are named by the struct tags of Config.
+type configJSON struct {
+	ContextBrackets string `json:"contextBrackets"`
+	*configFields
+}
+
+// MarshalJSON implements json.Marshaler.
+// ContextBrackets is encoded as a string of the opening and closing bracket.
+// Normalize, CustomResolver and CustomICU aren't encoded.
+func (c Config) MarshalJSON() ([]byte, error) {
+	v := configJSON{configFields: (*configFields)(&c)}
+	if c.ContextBrackets != [2]rune{} {
+		v.ContextBrackets = string(c.ContextBrackets[:])
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// It decodes a configuration as encoded by Config.MarshalJSON
+// and validates it. Fields missing in data are taken from DefaultConfig.
+// Unknown fields are rejected.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	conf := DefaultConfig
+	// Decoding into the default slices would overwrite their elements.
+	conf.GenderCategories = slices.Clone(DefaultConfig.GenderCategories)
+	conf.MarkupTags = slices.Clone(DefaultConfig.MarkupTags)
+	v := configJSON{
+		ContextBrackets: string(DefaultConfig.ContextBrackets[:]),
+		configFields:    (*configFields)(&conf),
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&v); err != nil {
+		return err
+	}
+	conf.ContextBrackets = [2]rune{}
+	if v.ContextBrackets != "" {
+		if utf8.RuneCountInString(v.ContextBrackets) != 2 {
+			return ErrConfigContextBrackets
+		}
+		copy(conf.ContextBrackets[:], []rune(v.ContextBrackets))
+	}
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+	*c = conf
+	return nil
+}
+
+// LoadConfig decodes a JSON configuration from r using Config.UnmarshalJSON.
+// Returns an error if r contains anything but a single JSON document.
+func LoadConfig(r io.Reader) (*Config, error) {
+	d := json.NewDecoder(r)
+	var c Config
+	if err := d.Decode(&c); err != nil {
+		return nil, fmt.Errorf("decoding config: %w", err)
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, errors.New("decoding config: unexpected data after the document")
+	}
+	return &c, nil
+}
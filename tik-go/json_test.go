@@ -0,0 +1,220 @@
+package tik_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestJSONGolden(t *testing.T) {
+	t.Parallel()
+
+	tk, err := tik.NewParser(tik.DefaultConfig).Parse(
+		`[report] {name} had {# |=0 no messages | messages} ` +
+			`on {date-medium}, see {@help}`)
+	requireNoErr(t, err)
+
+	actual, err := json.MarshalIndent(tk, "", "\t")
+	requireNoErr(t, err)
+
+	golden, err := os.ReadFile("testdata/tik.golden.json")
+	requireNoErr(t, err)
+	requireEqual(t, string(bytes.TrimSpace(golden)), string(actual))
+
+	var decoded tik.TIK
+	requireNoErr(t, json.Unmarshal(golden, &decoded))
+	requireDeepEqual(t, tk, decoded)
+	for i := range tk.Tokens {
+		requireEqual(t, tk.Tokens[i].String(tk.Raw), decoded.Tokens[i].String(decoded.Raw))
+	}
+}
+
+func TestJSONUnmarshalErr(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, input string) {
+		t.Helper()
+		var decoded tik.TIK
+		if err := json.Unmarshal([]byte(input), &decoded); err == nil {
+			t.Fatalf("expected error for %s", input)
+		}
+	}
+
+	f(t, `{"raw":"x","tokens":[{"start":0,"end":1,"type":0}]}`)
+	f(t, `{"raw":"x","tokens":[{"start":0,"end":1,"type":2,"typeName":"text"}]}`)
+	f(t, `{"raw":"x","tokens":[{"start":1,"end":0,"type":2}]}`)
+	f(t, `{"raw":"x","tokens":[{"start":0,"end":2,"type":2}]}`)
+	f(t, `{"raw":"x","tokens":{}}`)
+}
+
+func TestTokenTypeText(t *testing.T) {
+	t.Parallel()
+
+	n := 0
+	for tp := tik.TokenType(1); tp.String() != "unknown"; tp++ {
+		text, err := tp.MarshalText()
+		requireNoErr(t, err)
+		requireEqual(t, tp.String(), string(text))
+
+		var decoded tik.TokenType
+		requireNoErr(t, decoded.UnmarshalText(text))
+		requireEqual(t, tp, decoded)
+
+		data, err := json.Marshal(tp)
+		requireNoErr(t, err)
+		requireEqual(t, `"`+tp.String()+`"`, string(data))
+		decoded = 0
+		requireNoErr(t, json.Unmarshal(data, &decoded))
+		requireEqual(t, tp, decoded)
+		n++
+	}
+	requireEqual(t, int(tik.TokenTypeNumberSkeleton), n)
+
+	// Token types are encoded by name in maps and structs.
+	data, err := json.Marshal(map[tik.TokenType]int{tik.TokenTypeDateMedium: 1})
+	requireNoErr(t, err)
+	requireEqual(t, `{"date medium":1}`, string(data))
+	var v struct{ Type tik.TokenType }
+	requireNoErr(t, json.Unmarshal([]byte(`{"Type":"time zone"}`), &v))
+	requireEqual(t, tik.TokenTypeTimeZone, v.Type)
+
+	// Custom token types are encoded by their offset from TokenTypeCustom.
+	var custom tik.TokenType
+	requireNoErr(t, json.Unmarshal([]byte(`"custom 5"`), &custom))
+	requireEqual(t, tik.TokenTypeCustom+5, custom)
+}
+
+func TestTokenTypeTextErr(t *testing.T) {
+	t.Parallel()
+
+	_, err := tik.TokenType(0).MarshalText()
+	requireEqual(t, "unknown token type: 0", err.Error())
+	_, err = json.Marshal(tik.TokenType(127))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	f := func(t *testing.T, input string) {
+		t.Helper()
+		var tp tik.TokenType
+		err := tp.UnmarshalText([]byte(input))
+		requireEqual(t, "unknown token type: "+strconv.Quote(input), err.Error())
+		requireEqual(t, tik.TokenType(0), tp)
+	}
+	f(t, "unknown")
+	f(t, "")
+	f(t, "Date Medium")
+	f(t, "date-medium")
+	f(t, "custom 128")
+	f(t, "custom 05")
+}
+
+func TestConfigJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(tik.DefaultConfig)
+	requireNoErr(t, err)
+	requireEqual(t, `{"contextBrackets":"[]",`+
+		`"ordinalPluralOtherSuffix":"th","strictEscapes":false,"icuVarPrefix":"var","cardinalPluralNumberSign":"#",`+
+		`"timeZoneSkeleton":"zzzz","allowEmptyText":false,"preserveEdgeWhitespace":false,`+
+		`"namedPlaceholders":false,"caseInsensitiveKeywords":false,"allowNestedPlural":false,`+
+		`"disallowContext":false,"strictContextSegments":false,`+
+		`"bidiIsolateAddresses":false,"bidiIsolatePlaceholders":false,`+
+		`"maxContextLen":0,"maxComplexity":0,"maxPlaceholders":0,"unknownPlaceholderAsLiteral":false,"scaffoldEnglishPlural":false,"genderSelect":false,`+
+		`"genderCategories":["female","male","other"]}`,
+		string(data))
+
+	c, err := tik.LoadConfig(bytes.NewReader(data))
+	requireNoErr(t, err)
+	requireDeepEqual(t, tik.DefaultConfig, *c)
+
+	// Missing fields are taken from the default configuration.
+	c, err = tik.LoadConfig(strings.NewReader(
+		`{"strictEscapes":true,"contextBrackets":"【】","icuVarPrefix":"arg"}`))
+	requireNoErr(t, err)
+	expect := tik.DefaultConfig
+	expect.StrictEscapes = true
+	expect.ContextBrackets = [2]rune{'【', '】'}
+	expect.ICUVarPrefix = "arg"
+	requireDeepEqual(t, expect, *c)
+
+	data, err = json.Marshal(c)
+	requireNoErr(t, err)
+	requireEqual(t, `{"contextBrackets":"【】",`+
+		`"ordinalPluralOtherSuffix":"th","strictEscapes":true,"icuVarPrefix":"arg","cardinalPluralNumberSign":"#",`+
+		`"timeZoneSkeleton":"zzzz","allowEmptyText":false,"preserveEdgeWhitespace":false,`+
+		`"namedPlaceholders":false,"caseInsensitiveKeywords":false,"allowNestedPlural":false,`+
+		`"disallowContext":false,"strictContextSegments":false,`+
+		`"bidiIsolateAddresses":false,"bidiIsolatePlaceholders":false,`+
+		`"maxContextLen":0,"maxComplexity":0,"maxPlaceholders":0,"unknownPlaceholderAsLiteral":false,"scaffoldEnglishPlural":false,"genderSelect":false,`+
+		`"genderCategories":["female","male","other"]}`,
+		string(data))
+
+	c, err = tik.LoadConfig(strings.NewReader(`{"markupTags":["a","br"]}`))
+	requireNoErr(t, err)
+	requireDeepEqual(t, []string{"a", "br"}, c.MarkupTags)
+
+	c, err = tik.LoadConfig(strings.NewReader(
+		`{"genderCategories":["masculine","feminine","neuter","other"]}`))
+	requireNoErr(t, err)
+	requireDeepEqual(t, []string{"masculine", "feminine", "neuter", "other"},
+		c.GenderCategories)
+}
+
+func TestLoadConfigErr(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, expect error, input string) {
+		t.Helper()
+		c, err := tik.LoadConfig(strings.NewReader(input))
+		if expect == nil {
+			if err == nil {
+				t.Fatal("expected error")
+			}
+		} else {
+			requireErrIs(t, expect, err)
+		}
+		requireDeepEqual(t, (*tik.Config)(nil), c)
+	}
+
+	f(t, nil, ``)
+	f(t, nil, `{"strictEscape":true}`) // Typo.
+	f(t, nil, `{"strictEscapes":"yes"}`)
+	f(t, tik.ErrConfigContextBrackets, `{"contextBrackets":"["}`)
+	f(t, tik.ErrConfigContextBrackets, `{"contextBrackets":"{}"}`)
+	f(t, tik.ErrConfigICUVarPrefix, `{"icuVarPrefix":"0"}`)
+	f(t, tik.ErrConfigPluralSign, `{"cardinalPluralNumberSign":"te"}`)
+	f(t, tik.ErrConfigMarkupTags, `{"markupTags":["<a>"]}`)
+	f(t, tik.ErrConfigGender, `{"genderCategories":["male","female"]}`)
+	f(t, nil, `{} {}`)
+	f(t, nil, `{}x`)
+	f(t, nil, `{"strictEscapes":true}]`)
+}
+
+func TestConfigUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	var c tik.Config
+	err := json.Unmarshal([]byte(`{"icuVarPrefix":"arg","markupTags":["b"]}`), &c)
+	requireNoErr(t, err)
+	expect := tik.DefaultConfig
+	expect.ICUVarPrefix = "arg"
+	expect.MarkupTags = []string{"b"}
+	requireDeepEqual(t, expect, c)
+
+	// Invalid configurations leave c untouched.
+	err = json.Unmarshal([]byte(`{"icuVarPrefix":"0"}`), &c)
+	requireErrIs(t, tik.ErrConfigICUVarPrefix, err)
+	requireDeepEqual(t, expect, c)
+
+	err = json.Unmarshal([]byte(`{"icuVarPrefx":"arg"}`), &c)
+	if err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+	requireDeepEqual(t, expect, c)
+}
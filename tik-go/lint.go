@@ -0,0 +1,203 @@
+package tik
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Severity is the severity of a Diagnostic.
+type Severity int8
+
+const (
+	_ Severity = iota
+
+	// SeverityInfo is a stylistic remark that's usually harmless.
+	SeverityInfo
+
+	// SeverityWarning is likely a mistake.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "unknown"
+}
+
+// DiagnosticKind is the kind of a Diagnostic.
+type DiagnosticKind int8
+
+const (
+	_ DiagnosticKind = iota
+
+	// DiagnosticWhitespaceLiteral is a literal consisting only of whitespace
+	// between two placeholders such as `{text} {text}`, which gives
+	// translators no clue about how the placeholders relate.
+	DiagnosticWhitespaceLiteral
+
+	// DiagnosticEmptyPluralArm is a cardinal pluralization without content
+	// such as `{#}` or an exact match arm without content such as
+	// `{# |=0 | messages}`.
+	DiagnosticEmptyPluralArm
+
+	// DiagnosticWordAdjacentPlaceholder is a placeholder directly adjacent
+	// to a letter such as `{integer}x` or `re{text}`, which suggests
+	// the placeholder is part of a word that translators can't inflect.
+	// Letters of scripts that don't separate words by spaces,
+	// such as Han, Hiragana, Katakana and Thai, are ignored.
+	DiagnosticWordAdjacentPlaceholder
+)
+
+func (k DiagnosticKind) String() string {
+	switch k {
+	case DiagnosticWhitespaceLiteral:
+		return "whitespace-only literal between placeholders"
+	case DiagnosticEmptyPluralArm:
+		return "empty cardinal pluralization arm"
+	case DiagnosticWordAdjacentPlaceholder:
+		return "placeholder adjacent to a word"
+	}
+	return "unknown"
+}
+
+// Severity returns the severity of diagnostics of kind k.
+func (k DiagnosticKind) Severity() Severity {
+	switch k {
+	case DiagnosticEmptyPluralArm, DiagnosticWordAdjacentPlaceholder:
+		return SeverityWarning
+	case DiagnosticWhitespaceLiteral:
+		return SeverityInfo
+	}
+	return 0
+}
+
+// Diagnostic is an advisory finding reported by Lint.
+type Diagnostic struct {
+	Kind     DiagnosticKind
+	Severity Severity
+
+	// Token is the index of the offending token in TIK.Tokens.
+	Token int
+
+	// IndexStart and IndexEnd are the byte span of the offending token
+	// in the input.
+	IndexStart, IndexEnd int
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%v at index %d: %v", d.Severity, d.IndexStart, d.Kind)
+}
+
+// Lint parses input and returns non-fatal diagnostics ordered by
+// token index. Diagnostics are advisory and never make input invalid.
+// Returns an error only if input fails to parse.
+func Lint(input string, conf Config) ([]Diagnostic, error) {
+	t, err := NewParser(conf).Parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var diags []Diagnostic
+	report := func(kind DiagnosticKind, index int) {
+		tok := t.Tokens[index]
+		diags = append(diags, Diagnostic{
+			Kind:       kind,
+			Severity:   kind.Severity(),
+			Token:      index,
+			IndexStart: tok.IndexStart,
+			IndexEnd:   tok.IndexEnd,
+		})
+	}
+
+	type arm struct {
+		start   int // Index of the arm's start token.
+		content bool
+	}
+	var arms []arm // Open arms of cardinal pluralizations.
+	closeArm := func(next TokenType) {
+		a := arms[len(arms)-1]
+		// The content of a pluralization with exact match arms
+		// follows the `|` of its other arm instead.
+		isContent := t.Tokens[a.start].Type == TokenTypeCardinalPluralStart
+		if !a.content && (!isContent || next == TokenTypeCardinalPluralEnd) {
+			report(DiagnosticEmptyPluralArm, a.start)
+		}
+	}
+
+	for i, tok := range t.Tokens {
+		switch tok.Type {
+		case TokenTypeCardinalPluralStart:
+			if len(arms) > 0 {
+				arms[len(arms)-1].content = true
+			}
+			arms = append(arms, arm{start: i})
+			continue
+		case TokenTypePluralExactMatch, TokenTypePluralOther:
+			closeArm(tok.Type)
+			arms[len(arms)-1] = arm{start: i}
+			continue
+		case TokenTypeCardinalPluralEnd:
+			closeArm(tok.Type)
+			arms = arms[:len(arms)-1]
+			continue
+		case TokenTypePluralOffset, TokenTypeContext:
+			continue
+		}
+
+		raw := t.Raw[tok.IndexStart:tok.IndexEnd]
+		if tok.Type == TokenTypeLiteral {
+			blank := strings.TrimFunc(raw, unicode.IsSpace) == ""
+			if len(arms) > 0 && !blank {
+				arms[len(arms)-1].content = true
+			}
+			if blank && i > 0 && i+1 < len(t.Tokens) &&
+				t.Tokens[i-1].Type.IsPlaceholder() &&
+				t.Tokens[i+1].Type.IsPlaceholder() {
+				report(DiagnosticWhitespaceLiteral, i)
+			}
+			continue
+		}
+		if len(arms) > 0 {
+			arms[len(arms)-1].content = true
+		}
+		if tok.Type.IsPlaceholder() && tok.Type != TokenTypeSkip &&
+			isWordAdjacent(t, i) {
+			report(DiagnosticWordAdjacentPlaceholder, i)
+		}
+	}
+	return diags, nil
+}
+
+// isWordAdjacent returns true if the placeholder token at index is
+// directly preceded or followed by a letter of a literal.
+func isWordAdjacent(t TIK, index int) bool {
+	if index > 0 && t.Tokens[index-1].Type == TokenTypeLiteral {
+		prev := t.Tokens[index-1]
+		r, _ := utf8.DecodeLastRuneInString(t.Raw[prev.IndexStart:prev.IndexEnd])
+		if isSpacedLetter(r) {
+			return true
+		}
+	}
+	if index+1 < len(t.Tokens) && t.Tokens[index+1].Type == TokenTypeLiteral {
+		next := t.Tokens[index+1]
+		r, _ := utf8.DecodeRuneInString(t.Raw[next.IndexStart:next.IndexEnd])
+		if isSpacedLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSpacedLetter returns true if r is a letter of a script
+// that separates words by spaces.
+func isSpacedLetter(r rune) bool {
+	return unicode.IsLetter(r) && !unicode.In(r,
+		unicode.Han, unicode.Hiragana, unicode.Katakana,
+		unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar)
+}
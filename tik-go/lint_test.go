@@ -0,0 +1,74 @@
+package tik_test
+
+import (
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, input string, expect ...tik.Diagnostic) {
+		t.Helper()
+		actual, err := tik.Lint(input, tik.DefaultConfig)
+		requireNoErr(t, err)
+		requireDeepEqual(t, expect, actual)
+	}
+
+	d := func(kind tik.DiagnosticKind, token, start, end int) tik.Diagnostic {
+		return tik.Diagnostic{
+			Kind: kind, Severity: kind.Severity(),
+			Token: token, IndexStart: start, IndexEnd: end,
+		}
+	}
+
+	f(t, `Hello {name}, you have {# messages}`)
+	f(t, `[ctx] {text} and {number}`)
+	f(t, `{#件}`)
+	f(t, `{# offset:1 |=0 nobody | others}`)
+
+	// Whitespace-only literals between placeholders.
+	f(t, `{name} {text}`,
+		d(tik.DiagnosticWhitespaceLiteral, 1, 6, 7))
+	f(t, `[ctx] {date-short}  {time-short}.`,
+		d(tik.DiagnosticWhitespaceLiteral, 2, 18, 20))
+
+	// Empty pluralizations and exact match arms.
+	f(t, `{#}`,
+		d(tik.DiagnosticEmptyPluralArm, 0, 0, 2))
+	f(t, `{# |=0 |=1 {name} | messages}`,
+		d(tik.DiagnosticEmptyPluralArm, 1, 3, 6))
+	f(t, `{# |=0 none |=1 one |}`,
+		d(tik.DiagnosticEmptyPluralArm, 5, 20, 21))
+
+	// Placeholders adjacent to words.
+	f(t, `{integer}x faster`,
+		d(tik.DiagnosticWordAdjacentPlaceholder, 0, 0, 9))
+	f(t, `re{text} now`,
+		d(tik.DiagnosticWordAdjacentPlaceholder, 1, 2, 8))
+	f(t, `{text}の{number}件`)
+
+	// Multiple kinds.
+	f(t, `{text} {text}s`,
+		d(tik.DiagnosticWhitespaceLiteral, 1, 6, 7),
+		d(tik.DiagnosticWordAdjacentPlaceholder, 2, 7, 13))
+}
+
+func TestLintErr(t *testing.T) {
+	t.Parallel()
+
+	actual, err := tik.Lint(`{unknown}`, tik.DefaultConfig)
+	requireErrIs(t, tik.ErrUnknownPlaceholder, err)
+	requireDeepEqual(t, []tik.Diagnostic(nil), actual)
+}
+
+func TestDiagnosticString(t *testing.T) {
+	t.Parallel()
+
+	actual, err := tik.Lint(`{#}`, tik.DefaultConfig)
+	requireNoErr(t, err)
+	requireEqual(t, "warning at index 0: empty cardinal pluralization arm",
+		actual[0].String())
+	requireEqual(t, "info", tik.DiagnosticWhitespaceLiteral.Severity().String())
+}
@@ -0,0 +1,297 @@
+package tik
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrMF2Unsupported = errors.New("construct not representable in MessageFormat 2")
+
+// MF2Translator is a reusable TIK to Unicode MessageFormat 2 translator.
+// Placeholders become variables `{$var0}` with functions such as
+// `{$var0 :number}` and `{$var0 :datetime dateStyle=long}`.
+// Cardinal pluralizations, ordinals and selects become `.input` declarations
+// selected by a `.match` statement, in which case the whole message is
+// repeated for every combination of variant keys.
+// MF2 has no comments, the context is left to the caller.
+type MF2Translator struct {
+	icu ICUTranslator
+	v   mf2Visitor
+}
+
+func NewMF2Translator(conf Config) *MF2Translator {
+	return &MF2Translator{icu: ICUTranslator{conf: conf}}
+}
+
+// TIK2MF2 translates tik into an MF2 message.
+// Returns ErrMF2Unsupported for pluralization offsets, message references,
+// number skeletons, nested pluralizations and messages that would require
+// more than MaxMF2Variants variants.
+func (m *MF2Translator) TIK2MF2(tik TIK) (string, error) {
+	m.v.reset(&m.icu.conf)
+	if err := m.icu.Visit(tik, &m.v); err != nil {
+		return "", err
+	}
+	return m.v.render()
+}
+
+// MaxMF2Variants is the maximum number of variants of an MF2 message.
+// Since every combination of variant keys is a variant, the number of
+// variants grows exponentially with the number of selectors,
+// such as 4096 variants for 12 booleans.
+const MaxMF2Variants = 1024
+
+var replacerEscapeMF2 = strings.NewReplacer(`\`, `\\`, "{", `\{`, "}", `\}`)
+
+// mf2Part is either a text part of the pattern (if arms is nil)
+// or a selector with its variants.
+type mf2Part struct {
+	text       strings.Builder
+	variable   string // Name of the selector's variable without `$`.
+	annotation string // Function annotation of the selector's `.input`.
+	arms       []mf2Arm
+}
+
+type mf2Arm struct {
+	key  string // "*" for the catch-all variant.
+	text strings.Builder
+}
+
+type mf2Visitor struct {
+	b        strings.Builder
+	conf     *Config
+	parts    []*mf2Part
+	inPlural bool
+}
+
+func (v *mf2Visitor) reset(conf *Config) {
+	v.b.Reset()
+	v.conf = conf
+	v.parts = v.parts[:0]
+	v.inPlural = false
+}
+
+// out returns the builder the next pattern element must be written to.
+func (v *mf2Visitor) out() *strings.Builder {
+	if v.inPlural {
+		p := v.parts[len(v.parts)-1]
+		return &p.arms[len(p.arms)-1].text
+	}
+	if len(v.parts) == 0 || v.parts[len(v.parts)-1].arms != nil {
+		v.parts = append(v.parts, &mf2Part{})
+	}
+	return &v.parts[len(v.parts)-1].text
+}
+
+func (v *mf2Visitor) Literal(s string) error {
+	v.out().WriteString(replacerEscapeMF2.Replace(s))
+	return nil
+}
+
+func (v *mf2Visitor) Argument(index int, kind FormatKind) error {
+	if kind == FormatKindOrdinal {
+		return v.Ordinal(index, v.conf.OrdinalPluralOtherSuffix)
+	}
+	name := "$" + v.conf.argName(index)
+	b := v.out()
+	switch kind {
+	case FormatKindText, FormatKindTextWithGender, FormatKindEmail, FormatKindURL:
+		b.WriteString("{" + name + "}")
+	case FormatKindInteger:
+		b.WriteString("{" + name + " :integer}")
+	case FormatKindNumber:
+		b.WriteString("{" + name + " :number}")
+	case FormatKindNumberRange:
+		b.WriteString("{" + name + " :number}–{$" + v.conf.argName(index+1) + " :number}")
+	case FormatKindCurrency:
+		b.WriteString("{" + name + " :currency}")
+	case FormatKindDateFull:
+		b.WriteString("{" + name + " :datetime dateStyle=full}")
+	case FormatKindDateLong:
+		b.WriteString("{" + name + " :datetime dateStyle=long}")
+	case FormatKindDateMedium:
+		b.WriteString("{" + name + " :datetime dateStyle=medium}")
+	case FormatKindDateShort:
+		b.WriteString("{" + name + " :datetime dateStyle=short}")
+	case FormatKindTimeFull:
+		b.WriteString("{" + name + " :datetime timeStyle=full}")
+	case FormatKindTimeLong:
+		b.WriteString("{" + name + " :datetime timeStyle=long}")
+	case FormatKindTimeMedium:
+		b.WriteString("{" + name + " :datetime timeStyle=medium}")
+	case FormatKindTimeShort:
+		b.WriteString("{" + name + " :datetime timeStyle=short}")
+	case FormatKindDateTime:
+		b.WriteString("{" + name + " :datetime dateStyle=medium timeStyle=short}")
+	case FormatKindTimeZone:
+		b.WriteString("{" + name + " :datetime timeZoneName=" +
+			timeZoneNames[v.conf.timeZoneSkeleton()] + "}")
+	default:
+		return ErrMF2Unsupported
+	}
+	return nil
+}
+
+func (v *mf2Visitor) Currency(index int, code string) error {
+	v.out().WriteString("{$" + v.conf.argName(index) + " :currency currency=" + code + "}")
+	return nil
+}
+
+func (v *mf2Visitor) Ordinal(index int, suffix string) error {
+	// Ordinals need a selector to append the suffix.
+	if v.inPlural {
+		return ErrMF2Unsupported
+	}
+	p := &mf2Part{
+		variable:   v.conf.argName(index),
+		annotation: ":number select=ordinal",
+		arms:       []mf2Arm{{key: "*"}},
+	}
+	p.arms[0].text.WriteString("{$" + v.conf.argName(index) + "}")
+	p.arms[0].text.WriteString(replacerEscapeMF2.Replace(suffix))
+	v.parts = append(v.parts, p)
+	return nil
+}
+
+func (v *mf2Visitor) NumberSkeleton(int, string) error { return ErrMF2Unsupported }
+
+func (v *mf2Visitor) Unit(index int, unit string) error {
+	v.out().WriteString("{$" + v.conf.argName(index) + " :unit unit=" + unit + "}")
+	return nil
+}
+
+func (v *mf2Visitor) PluralStart(index int) error {
+	if v.inPlural {
+		return ErrMF2Unsupported
+	}
+	v.inPlural = true
+	v.parts = append(v.parts, &mf2Part{
+		variable: v.conf.argName(index), annotation: ":number", arms: []mf2Arm{},
+	})
+	return nil
+}
+
+func (v *mf2Visitor) PluralOffset(int) error { return ErrMF2Unsupported }
+
+func (v *mf2Visitor) PluralExactMatch(value int) error {
+	p := v.parts[len(v.parts)-1]
+	p.arms = append(p.arms, mf2Arm{key: strconv.Itoa(value)})
+	return nil
+}
+
+func (v *mf2Visitor) PluralOther() error {
+	p := v.parts[len(v.parts)-1]
+	p.arms = append(p.arms, mf2Arm{key: "*"})
+	p.arms[len(p.arms)-1].text.WriteString("{$" + p.variable + "}")
+	return nil
+}
+
+func (v *mf2Visitor) PluralEnd() error {
+	v.inPlural = false
+	return nil
+}
+
+func (v *mf2Visitor) MessageRef(string) error { return ErrMF2Unsupported }
+
+func (v *mf2Visitor) RawICU(int, string) error { return ErrMF2Unsupported }
+
+// Tag writes the markup tag as MF2 markup such as `{#a}`, `{/a}` or `{#br/}`.
+func (v *mf2Visitor) Tag(_ int, name string, tag TokenType) error {
+	switch tag {
+	case TokenTypeTagOpen:
+		v.out().WriteString("{#" + name + "}")
+	case TokenTypeTagClose:
+		v.out().WriteString("{/" + name + "}")
+	default:
+		v.out().WriteString("{#" + name + "/}")
+	}
+	return nil
+}
+
+func (v *mf2Visitor) Select(index int, name string, cases []SelectCase) error {
+	if v.inPlural {
+		return ErrMF2Unsupported
+	}
+	if name == "" {
+		name = v.conf.argName(index)
+	}
+	p := &mf2Part{variable: name, annotation: ":string"}
+	p.arms = make([]mf2Arm, len(cases)+1)
+	for i, c := range cases {
+		p.arms[i].key = c.Key
+		p.arms[i].text.WriteString(replacerEscapeMF2.Replace(c.Text))
+	}
+	p.arms[len(cases)].key = "*"
+	v.parts = append(v.parts, p)
+	return nil
+}
+
+func (v *mf2Visitor) render() (string, error) {
+	var selectors []*mf2Part
+	variants := 1
+	for _, p := range v.parts {
+		if p.arms != nil {
+			selectors = append(selectors, p)
+			if variants *= len(p.arms); variants > MaxMF2Variants {
+				return "", ErrMF2Unsupported
+			}
+		}
+	}
+
+	if selectors == nil {
+		var pattern string
+		if len(v.parts) > 0 {
+			pattern = v.parts[0].text.String()
+		}
+		// Simple messages can't start with a dot and
+		// their leading whitespace is insignificant.
+		if r, _ := utf8.DecodeRuneInString(pattern); r == '.' || unicode.IsSpace(r) {
+			return "{{" + pattern + "}}", nil
+		}
+		return pattern, nil
+	}
+
+	for _, s := range selectors {
+		v.b.WriteString(".input {$" + s.variable + " " +
+			s.annotation + "}\n")
+	}
+	v.b.WriteString(".match")
+	for _, s := range selectors {
+		v.b.WriteString(" $" + s.variable)
+	}
+
+	// Emit a variant for every combination of keys.
+	arms := make([]int, len(selectors))
+	for {
+		v.b.WriteByte('\n')
+		for i, s := range selectors {
+			v.b.WriteString(s.arms[arms[i]].key)
+			v.b.WriteByte(' ')
+		}
+		v.b.WriteString("{{")
+		si := 0
+		for _, p := range v.parts {
+			if p.arms == nil {
+				v.b.WriteString(p.text.String())
+				continue
+			}
+			v.b.WriteString(p.arms[arms[si]].text.String())
+			si++
+		}
+		v.b.WriteString("}}")
+
+		i := len(arms) - 1
+		for ; i >= 0; i-- {
+			if arms[i]++; arms[i] < len(selectors[i].arms) {
+				break
+			}
+			arms[i] = 0
+		}
+		if i < 0 {
+			return v.b.String(), nil
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package tik_test
+
+import (
+	"strings"
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestMF2Translator(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewMF2Translator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		actual, err := translator.TIK2MF2(tk)
+		requireNoErr(t, err)
+		requireEqual(t, expect, actual)
+	}
+
+	// Literals.
+	f(t, `hello world`, `hello world`)
+	f(t, `hello world`, `[greeting] hello world`)
+	f(t, `\{not a placeholder\} \\`, `\{not a placeholder\} \\`)
+	f(t, `{{.dotfile}}`, `.dotfile`)
+
+	// Text.
+	f(t, `hello {$var0} and {$var1}`, `hello {text} and {name}`)
+
+	// Numbers.
+	f(t, `{$var0 :integer} items`, `{integer} items`)
+	f(t, `{$var0 :number} degrees`, `{number} degrees`)
+	f(t, `balance: {$var0 :currency}`, `balance: {currency}`)
+	f(t, `balance: {$var0 :currency currency=EUR}`, `balance: {currency:EUR}`)
+
+	// Dates and times.
+	f(t, `{$var0 :datetime dateStyle=full}, {$var1 :datetime dateStyle=long}, `+
+		`{$var2 :datetime dateStyle=medium}, {$var3 :datetime dateStyle=short}`,
+		`{date-full}, {date-long}, {date-medium}, {date-short}`)
+	f(t, `{$var0 :datetime timeStyle=full}, {$var1 :datetime timeStyle=long}, `+
+		`{$var2 :datetime timeStyle=medium}, {$var3 :datetime timeStyle=short}`,
+		`{time-full}, {time-long}, {time-medium}, {time-short}`)
+
+	f(t, `{$var0 :number}–{$var1 :number} of {$var2}`, `{number-range} of {text}`)
+
+	// Pluralization.
+	f(t, ".input {$var0 :number select=ordinal}\n"+
+		".match $var0\n"+
+		"* {{You're {$var0}th}}", `You're {ordinal}`)
+	f(t, ".input {$var0 :number select=ordinal}\n"+
+		".match $var0\n"+
+		"* {{{$var0}º}}", `{ordinal:º}`)
+	f(t, ".input {$var1 :number}\n"+
+		".match $var1\n"+
+		"* {{{$var0} had {$var1} messages from {$var2}.}}",
+		`{name} had {# messages from {text}}.`)
+	f(t, ".input {$var0 :number}\n"+
+		".match $var0\n"+
+		"0 {{no messages}}\n"+
+		"1 {{}}\n"+
+		"* {{{$var0} messages}}", `{# |=1  |=0 no messages | messages}`)
+	f(t, ".input {$var0 :number}\n"+
+		".input {$var1 :number}\n"+
+		".match $var0 $var1\n"+
+		"0 0 {{nothing in nowhere}}\n"+
+		"0 * {{nothing in {$var1} folders}}\n"+
+		"* 0 {{{$var0} messages in nowhere}}\n"+
+		"* * {{{$var0} messages in {$var1} folders}}",
+		`{# |=0 nothing | messages} in {# |=0 nowhere | folders}`)
+
+	// Booleans.
+	f(t, ".input {$var0 :string}\n"+
+		".match $var0\n"+
+		"true {{Wi-Fi is on}}\n"+
+		"false {{Wi-Fi is off}}\n"+
+		"* {{Wi-Fi is }}", `Wi-Fi is {bool:on/off}`)
+
+	// Enum selects.
+	f(t, ".input {$status :string}\n"+
+		".match $status\n"+
+		"pending {{Order }}\n"+
+		"shipped {{Order }}\n"+
+		"* {{Order }}", `Order {select:status(pending,shipped)}`)
+}
+
+func TestMF2TranslatorMarkupTags(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.MarkupTags = []string{"a", "br"}
+	tk, err := tik.NewParser(conf).Parse(`Click {<a>}here{</a>}{<br/>}`)
+	requireNoErr(t, err)
+	actual, err := tik.NewMF2Translator(conf).TIK2MF2(tk)
+	requireNoErr(t, err)
+	requireEqual(t, `Click {#a}here{/a}{#br/}`, actual)
+}
+
+func TestMF2TranslatorErr(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewMF2Translator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect error, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		actual, err := translator.TIK2MF2(tk)
+		requireErrIs(t, expect, err)
+		requireEqual(t, "", actual)
+	}
+
+	f(t, tik.ErrMF2Unsupported, `{# offset:1 |=0 nobody | others}`)
+	f(t, tik.ErrMF2Unsupported, `see {@help}`)
+
+	// Every boolean has the variants true, false and *.
+	f(t, tik.ErrMF2Unsupported, strings.Repeat("{bool:on/off} ", 7))
+	f(t, tik.ErrMF2Unsupported, strings.Repeat("{bool:on/off} ", 12))
+
+	tk, err := p.Parse(strings.Repeat("{bool:on/off} ", 6))
+	requireNoErr(t, err)
+	actual, err := translator.TIK2MF2(tk)
+	requireNoErr(t, err)
+	requireEqual(t, 729, strings.Count(actual, "{{"))
+}
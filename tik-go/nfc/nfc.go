@@ -0,0 +1,10 @@
+// Package nfc normalizes TIKs to Unicode normalization form C
+// using golang.org/x/text/unicode/norm.
+package nfc
+
+import "golang.org/x/text/unicode/norm"
+
+// Normalize returns s in Unicode normalization form C.
+// Use it as tik.Config.Normalize to parse visually identical keys
+// in composed and decomposed form equally.
+func Normalize(s string) string { return norm.NFC.String(s) }
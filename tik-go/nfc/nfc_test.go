@@ -0,0 +1,53 @@
+package nfc_test
+
+import (
+	"reflect"
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+	"github.com/romshark/tik/tik-go/nfc"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	const composed, decomposed = "[caf\u00e9] r\u00e9sum\u00e9 {text}",
+		"[cafe\u0301] re\u0301sume\u0301 {text}"
+
+	parse := func(t *testing.T, p *tik.Parser, input string) tik.TIK {
+		t.Helper()
+		tk, err := p.Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tk
+	}
+
+	// Without normalization the keys differ.
+	p := tik.NewParser(tik.DefaultConfig)
+	if parse(t, p, composed).Equal(parse(t, p, decomposed)) {
+		t.Fatal("expected composed and decomposed TIKs to differ")
+	}
+
+	conf := tik.DefaultConfig
+	conf.Normalize = nfc.Normalize
+	p = tik.NewParser(conf)
+	a, b := parse(t, p, composed), parse(t, p, decomposed)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("\nexpected: %#v;\nreceived: %#v", a, b)
+	}
+	if b.Raw != composed {
+		t.Errorf("expected raw %q, received %q", composed, b.Raw)
+	}
+	if c := b.Context(); c != "café" {
+		t.Errorf("expected context %q, received %q", "café", c)
+	}
+
+	// Error indexes refer to the normalized input.
+	_, err := p.Parse("re\u0301sume\u0301 {unknown}")
+	if expect := (tik.ParseError{
+		Index: len("r\u00e9sum\u00e9 "), Err: tik.ErrUnknownPlaceholder,
+	}); !reflect.DeepEqual(error(expect), err) {
+		t.Fatalf("\nexpected: %#v;\nreceived: %#v", expect, err)
+	}
+}
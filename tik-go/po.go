@@ -0,0 +1,228 @@
+package tik
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrPOMultiplePlurals = errors.New(
+		"PO entries can't represent multiple cardinal pluralizations")
+	ErrPOUnsupported = errors.New("construct not representable in PO")
+)
+
+// POEntry is a gettext PO catalog entry.
+type POEntry struct {
+	// Comments are the extracted comments describing the placeholders.
+	Comments []string
+
+	// MsgCtxt is the context of the TIK without the brackets.
+	MsgCtxt string
+
+	// MsgID is the message with placeholders in printf-style format.
+	MsgID string
+
+	// MsgIDPlural is set when the TIK contains a cardinal pluralization.
+	// Since TIKs are always written in CLDR plural category `other`
+	// it's equal to MsgID.
+	MsgIDPlural string
+}
+
+// String returns the entry in .pot format with empty translations.
+func (e POEntry) String() string {
+	var b strings.Builder
+	for _, c := range e.Comments {
+		b.WriteString("#. ")
+		b.WriteString(c)
+		b.WriteByte('\n')
+	}
+	b.WriteString("#, c-format\n")
+	if e.MsgCtxt != "" {
+		writePOString(&b, "msgctxt", e.MsgCtxt)
+	}
+	writePOString(&b, "msgid", e.MsgID)
+	if e.MsgIDPlural == "" {
+		writePOString(&b, "msgstr", "")
+		return b.String()
+	}
+	writePOString(&b, "msgid_plural", e.MsgIDPlural)
+	writePOString(&b, "msgstr[0]", "")
+	writePOString(&b, "msgstr[1]", "")
+	return b.String()
+}
+
+var replacerEscapePO = strings.NewReplacer(
+	`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`,
+)
+
+func writePOString(b *strings.Builder, keyword, s string) {
+	b.WriteString(keyword)
+	b.WriteString(` "`)
+	b.WriteString(replacerEscapePO.Replace(s))
+	b.WriteString("\"\n")
+}
+
+// POTranslator is a reusable TIK to gettext PO entry translator.
+//
+// Placeholders are translated to positional printf-style arguments
+// (`%1$s`, `%2$d`) and a comment describing the type and the translator
+// hint of each argument is generated. A TIK can contain at most one cardinal pluralization
+// since PO entries can't represent multiple independent plurals.
+type POTranslator struct {
+	icu ICUTranslator
+	v   poVisitor
+}
+
+func NewPOTranslator(conf Config) *POTranslator {
+	return &POTranslator{icu: ICUTranslator{conf: conf}}
+}
+
+// TIK2PO translates tik into a PO entry.
+// Returns ErrPOMultiplePlurals if tik contains more than one cardinal
+// pluralization and ErrPOUnsupported for pluralization arms and message
+// references.
+func (p *POTranslator) TIK2PO(tik TIK) (POEntry, error) {
+	p.v.b.Reset()
+	p.v.comments, p.v.plurals = nil, 0
+	p.v.args = tik.Args()
+	if err := p.icu.Visit(tik, &p.v); err != nil {
+		return POEntry{}, err
+	}
+	e := POEntry{
+		Comments: p.v.comments,
+		MsgCtxt:  tik.Context(),
+		MsgID:    p.v.b.String(),
+	}
+	if p.v.plurals > 0 {
+		e.MsgIDPlural = e.MsgID
+	}
+	return e, nil
+}
+
+type poVisitor struct {
+	b        strings.Builder
+	args     []Arg
+	comments []string
+	plurals  int
+}
+
+func (v *poVisitor) Literal(s string) error {
+	v.b.WriteString(strings.ReplaceAll(s, "%", "%%"))
+	return nil
+}
+
+func (v *poVisitor) writeArgument(index int, verb byte, desc string) {
+	arg := "%" + strconv.Itoa(index+1) + "$" + string(verb)
+	v.b.WriteString(arg)
+	if h := v.args[index].Hint; h != "" {
+		desc += ", " + h
+	}
+	v.comments = append(v.comments, arg+": "+desc)
+}
+
+func (v *poVisitor) Argument(index int, kind FormatKind) error {
+	verb, desc, ok := printfVerb(kind)
+	if !ok {
+		return ErrPOUnsupported
+	}
+	v.writeArgument(index, verb, desc)
+	return nil
+}
+
+func (v *poVisitor) Currency(index int, code string) error {
+	v.writeArgument(index, 's', "currency "+code)
+	return nil
+}
+
+func (v *poVisitor) Ordinal(index int, suffix string) error {
+	v.writeArgument(index, 's', "ordinal "+suffix)
+	return nil
+}
+
+func (v *poVisitor) Unit(index int, unit string) error {
+	v.writeArgument(index, 's', "unit "+unit)
+	return nil
+}
+
+func (v *poVisitor) NumberSkeleton(index int, skeleton string) error {
+	v.writeArgument(index, 's', "number "+skeleton)
+	return nil
+}
+
+// printfVerb returns the printf verb and the description of an argument
+// of the given kind for printf-style formats such as PO and Android strings.
+func printfVerb(kind FormatKind) (verb byte, desc string, ok bool) {
+	switch kind {
+	case FormatKindText:
+		return 's', "text", true
+	case FormatKindTextWithGender:
+		return 's', "text with gender", true
+	case FormatKindInteger:
+		return 'd', "integer", true
+	case FormatKindNumber:
+		return 's', "number", true
+	case FormatKindCurrency:
+		return 's', "currency", true
+	case FormatKindOrdinal:
+		return 's', "ordinal", true
+	case FormatKindDateFull:
+		return 's', "date full", true
+	case FormatKindDateLong:
+		return 's', "date long", true
+	case FormatKindDateMedium:
+		return 's', "date medium", true
+	case FormatKindDateShort:
+		return 's', "date short", true
+	case FormatKindTimeFull:
+		return 's', "time full", true
+	case FormatKindTimeLong:
+		return 's', "time long", true
+	case FormatKindTimeMedium:
+		return 's', "time medium", true
+	case FormatKindTimeShort:
+		return 's', "time short", true
+	case FormatKindDateTime:
+		return 's', "date time", true
+	case FormatKindTimeZone:
+		return 's', "time zone", true
+	case FormatKindRelativeTime:
+		return 's', "relative time", true
+	case FormatKindSpellout:
+		return 'd', "number spellout", true
+	case FormatKindWeekOfYear:
+		return 's', "week of year", true
+	case FormatKindQuarter:
+		return 's', "quarter", true
+	case FormatKindEmail:
+		return 's', "email", true
+	case FormatKindURL:
+		return 's', "URL", true
+	case FormatKindUnit:
+		return 's', "unit", true
+	case FormatKindPermille:
+		return 's', "permille", true
+	}
+	return 0, "", false
+}
+
+func (v *poVisitor) PluralStart(index int) error {
+	if v.plurals++; v.plurals > 1 {
+		return ErrPOMultiplePlurals
+	}
+	v.writeArgument(index, 'd', "pluralization count")
+	return nil
+}
+
+func (v *poVisitor) PluralOffset(int) error                 { return ErrPOUnsupported }
+func (v *poVisitor) PluralExactMatch(int) error             { return ErrPOUnsupported }
+func (v *poVisitor) PluralOther() error                     { return nil }
+func (v *poVisitor) PluralEnd() error                       { return nil }
+func (v *poVisitor) MessageRef(string) error                { return ErrPOUnsupported }
+func (v *poVisitor) Select(int, string, []SelectCase) error { return ErrPOUnsupported }
+func (v *poVisitor) RawICU(int, string) error               { return ErrPOUnsupported }
+
+func (v *poVisitor) Tag(_ int, name string, tag TokenType) error {
+	v.b.WriteString(markupTag(name, tag))
+	return nil
+}
@@ -0,0 +1,94 @@
+package tik_test
+
+import (
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestPOTranslator(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewPOTranslator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect tik.POEntry, expectPOT, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		actual, err := translator.TIK2PO(tk)
+		requireNoErr(t, err)
+		requireDeepEqual(t, expect, actual)
+		requireEqual(t, expectPOT, actual.String())
+	}
+
+	f(t, tik.POEntry{
+		Comments: []string{
+			"%1$s: text with gender",
+			"%2$d: pluralization count",
+		},
+		MsgID:       "%1$s had %2$d messages",
+		MsgIDPlural: "%1$s had %2$d messages",
+	}, `#. %1$s: text with gender
+#. %2$d: pluralization count
+#, c-format
+msgid "%1$s had %2$d messages"
+msgid_plural "%1$s had %2$d messages"
+msgstr[0] ""
+msgstr[1] ""
+`, `{name} had {# messages}`)
+
+	f(t, tik.POEntry{
+		Comments: []string{"%1$d: integer", "%2$s: date short"},
+		MsgCtxt:  "report",
+		MsgID:    `50%% of "{%1$d}" on %2$s`,
+	}, `#. %1$d: integer
+#. %2$s: date short
+#, c-format
+msgctxt "report"
+msgid "50%% of \"{%1$d}\" on %2$s"
+msgstr ""
+`, `[report] 50% of "\{{integer}\}" on {date-short}`)
+
+	f(t, tik.POEntry{
+		Comments: []string{"%1$s: text with gender, the sender", "%2$s: text"},
+		MsgID:    "%1$s wrote to %2$s",
+	}, `#. %1$s: text with gender, the sender
+#. %2$s: text
+#, c-format
+msgid "%1$s wrote to %2$s"
+msgstr ""
+`, `{name # the sender} wrote to {text}`)
+
+	f(t, tik.POEntry{
+		Comments: []string{"%1$s: email", "%2$s: URL"},
+		MsgID:    "Sent to %1$s, visit %2$s",
+	}, `#. %1$s: email
+#. %2$s: URL
+#, c-format
+msgid "Sent to %1$s, visit %2$s"
+msgstr ""
+`, `Sent to {email}, visit {url}`)
+}
+
+func TestPOTranslatorErr(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewPOTranslator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect error, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		actual, err := translator.TIK2PO(tk)
+		requireErrIs(t, expect, err)
+		requireDeepEqual(t, tik.POEntry{}, actual)
+	}
+
+	f(t, tik.ErrPOMultiplePlurals, `{# messages} in {# folders}`)
+	f(t, tik.ErrPOUnsupported, `{# |=0 no messages | messages}`)
+	f(t, tik.ErrPOUnsupported, `Wi-Fi is {bool:on/off}`)
+	f(t, tik.ErrPOUnsupported, `see {@help}`)
+	f(t, tik.ErrPOUnsupported, `{number-range}`)
+}
@@ -0,0 +1,33 @@
+package tik
+
+import "sync"
+
+// ParserPool is a pool of parsers sharing the same configuration.
+// Unlike Parser, ParserPool is safe for concurrent use.
+type ParserPool struct {
+	pool sync.Pool
+}
+
+// NewParserPool creates a new pool of parsers using conf.
+func NewParserPool(conf Config) *ParserPool {
+	return &ParserPool{pool: sync.Pool{
+		New: func() any { return NewParser(conf) },
+	}}
+}
+
+// Get returns a parser from the pool.
+// The returned parser must not be shared across goroutines
+// and should be returned to the pool using Put once it's no longer used.
+func (p *ParserPool) Get() *Parser { return p.pool.Get().(*Parser) }
+
+// Put returns parser to the pool.
+// Neither parser nor any token slice obtained through ParseFn
+// must be used after Put.
+func (p *ParserPool) Put(parser *Parser) { p.pool.Put(parser) }
+
+// Parse is equivalent to Parser.Parse but is safe for concurrent use.
+func (p *ParserPool) Parse(input string) (TIK, error) {
+	parser := p.Get()
+	defer p.Put(parser)
+	return parser.Parse(input)
+}
@@ -0,0 +1,455 @@
+// Package render formats TIKs with concrete argument values for previews
+// and tests using golang.org/x/text.
+package render
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tik "github.com/romshark/tik/tik-go"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+var (
+	ErrArgCount   = tik.ErrArgCount
+	ErrArgType    = tik.ErrArgType
+	ErrMessageRef = errors.New("message references can't be rendered")
+	ErrRawICU     = errors.New("raw ICU can't be rendered")
+)
+
+// Renderer renders TIKs parsed with the Config it was created with.
+type Renderer struct {
+	translator *tik.ICUTranslator
+}
+
+// NewRenderer creates a Renderer for TIKs parsed with conf.
+// Custom placeholders are rendered as text unless conf.CustomICU
+// generates raw ICU for them.
+func NewRenderer(conf tik.Config) *Renderer {
+	return &Renderer{translator: tik.NewICUTranslator(conf)}
+}
+
+// Render formats tik parsed with tik.DefaultConfig for locale with args.
+// See Renderer.Render for details.
+func Render(t tik.TIK, locale language.Tag, args ...any) (string, error) {
+	return NewRenderer(tik.DefaultConfig).Render(t, locale, args...)
+}
+
+// Render formats tik for locale with args, which must match
+// the arguments of tik.ICUTranslator.Args in number and kind as defined by
+// tik.ICUTranslator.CheckArgs. Currency placeholders require
+// a currency.Amount. If Config.GenderSelect is enabled, the gender
+// arguments are required but don't change the rendered text.
+// Relative times are in the past if the time.Duration is negative.
+//
+// Render formats the same message structure TIK2ICU generates:
+// exact match arms of cardinal plurals are selected by the count,
+// otherwise the other arm is used with `#` formatted as the count
+// minus the offset. Numbers and currencies are formatted for locale.
+// Ordinal suffixes are derived from the CLDR ordinal plural rules
+// and spellouts are generated for English only,
+// other locales render the plain number.
+// Since x/text provides no CLDR date patterns, dates and times are always
+// formatted using English patterns and time zones are rendered
+// as the zone abbreviation regardless of the configured skeleton,
+// except for full times, which render the GMT offset such as "GMT-07:00"
+// since x/text provides no time zone names.
+// Weeks of the year are ISO 8601 weeks.
+// Relative times are formatted in English in the largest whole unit
+// of seconds, minutes, hours and days, such as "3 days ago" or "in 2 hours".
+//
+// Returns ErrArgCount and ErrArgType naming the offending placeholder index
+// if args don't match, ErrMessageRef if tik contains message references
+// and ErrRawICU if it contains raw ICU.
+func (r *Renderer) Render(t tik.TIK, locale language.Tag, args ...any) (string, error) {
+	if err := r.checkArgs(t, args); err != nil {
+		return "", err
+	}
+	w := renderer{
+		p:      message.NewPrinter(locale),
+		locale: locale,
+		args:   args,
+	}
+	if err := r.translator.Visit(t, &w); err != nil {
+		return "", err
+	}
+	return w.b.String(), nil
+}
+
+// checkArgs is similar to tik.ICUTranslator.CheckArgs but
+// also requires a currency.Amount for currency placeholders.
+func (r *Renderer) checkArgs(t tik.TIK, args []any) error {
+	if err := r.translator.CheckArgs(t, args...); err != nil {
+		return err
+	}
+	for _, a := range r.translator.Args(t) {
+		if !a.Type.IsCurrency() {
+			continue
+		}
+		if _, ok := args[a.Index].(currency.Amount); !ok {
+			return &tik.ArgError{
+				Index:    a.Index,
+				Type:     a.Type,
+				Expect:   "currency.Amount",
+				Received: fmt.Sprintf("%T", args[a.Index]),
+			}
+		}
+	}
+	return nil
+}
+
+// toInt returns v as int64 if v is of an integer type.
+func toInt(v any) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// renderer is the tik.ICUVisitor writing the formatted message.
+type renderer struct {
+	b      strings.Builder
+	p      *message.Printer
+	locale language.Tag
+	args   []any
+
+	plurals []cardinal // Stack of the current cardinal plurals.
+	skip    bool       // The current arm isn't selected.
+}
+
+// cardinal is the state of a cardinal plural being rendered.
+type cardinal struct {
+	count   int64
+	offset  int64
+	matched bool // An arm was selected.
+	skip    bool // The skip state outside the plural.
+}
+
+func (r *renderer) Literal(s string) error {
+	if !r.skip {
+		r.b.WriteString(s)
+	}
+	return nil
+}
+
+func (r *renderer) Argument(index int, kind tik.FormatKind) error {
+	if r.skip {
+		return nil
+	}
+	v := r.args[index]
+	switch kind {
+	case tik.FormatKindText, tik.FormatKindTextWithGender,
+		tik.FormatKindEmail, tik.FormatKindURL:
+		r.b.WriteString(v.(string))
+	case tik.FormatKindInteger:
+		i, _ := toInt(v)
+		r.b.WriteString(r.p.Sprint(number.Decimal(i)))
+	case tik.FormatKindNumber:
+		r.b.WriteString(r.p.Sprint(number.Decimal(v)))
+	case tik.FormatKindNumberRange:
+		r.b.WriteString(r.p.Sprint(number.Decimal(v)))
+		r.b.WriteString("–")
+		r.b.WriteString(r.p.Sprint(number.Decimal(r.args[index+1])))
+	case tik.FormatKindCurrency:
+		r.b.WriteString(r.p.Sprint(currency.Symbol(v.(currency.Amount))))
+	case tik.FormatKindOrdinal:
+		i, _ := toInt(v)
+		r.b.WriteString(r.p.Sprint(number.Decimal(i)))
+		r.b.WriteString(r.ordinalSuffix(i))
+	case tik.FormatKindDateFull:
+		r.b.WriteString(v.(time.Time).Format("Monday, January 2, 2006"))
+	case tik.FormatKindDateLong:
+		r.b.WriteString(v.(time.Time).Format("January 2, 2006"))
+	case tik.FormatKindDateMedium:
+		r.b.WriteString(v.(time.Time).Format("Jan 2, 2006"))
+	case tik.FormatKindDateShort:
+		r.b.WriteString(v.(time.Time).Format("1/2/06"))
+	case tik.FormatKindTimeFull:
+		t := v.(time.Time)
+		r.b.WriteString(t.Format("3:04:05 PM "))
+		r.b.WriteString(gmtOffset(t))
+	case tik.FormatKindTimeLong:
+		r.b.WriteString(v.(time.Time).Format("3:04:05 PM MST"))
+	case tik.FormatKindTimeMedium:
+		r.b.WriteString(v.(time.Time).Format("3:04:05 PM"))
+	case tik.FormatKindTimeShort:
+		r.b.WriteString(v.(time.Time).Format("3:04 PM"))
+	case tik.FormatKindDateTime:
+		r.b.WriteString(v.(time.Time).Format("Jan 2, 2006, 3:04 PM"))
+	case tik.FormatKindTimeZone:
+		r.b.WriteString(v.(time.Time).Format("MST"))
+	case tik.FormatKindWeekOfYear:
+		_, week := v.(time.Time).ISOWeek()
+		r.b.WriteString(r.p.Sprint(number.Decimal(week)))
+	case tik.FormatKindQuarter:
+		r.b.WriteString("Q" + strconv.Itoa((int(v.(time.Time).Month())+2)/3))
+	case tik.FormatKindPermille:
+		r.b.WriteString(r.p.Sprint(number.PerMille(v)))
+	case tik.FormatKindRelativeTime:
+		r.writeRelativeTime(v.(time.Duration))
+	case tik.FormatKindSpellout:
+		i, _ := toInt(v)
+		if base, _ := r.locale.Base(); base.String() != "en" {
+			r.b.WriteString(r.p.Sprint(number.Decimal(i)))
+			break
+		}
+		r.b.WriteString(spellOutEnglish(i))
+	}
+	return nil
+}
+
+// gmtOffset returns the localized GMT format of the zone offset of t
+// such as "GMT-07:00", or "GMT" if the offset is zero.
+func gmtOffset(t time.Time) string {
+	if _, offset := t.Zone(); offset == 0 {
+		return "GMT"
+	}
+	return "GMT" + t.Format("-07:00")
+}
+
+func (r *renderer) writeRelativeTime(d time.Duration) {
+	past := d < 0
+	if past {
+		d = -d
+	}
+	n, unit := int64(d/time.Second), "second"
+	switch {
+	case d >= 24*time.Hour:
+		n, unit = int64(d/(24*time.Hour)), "day"
+	case d >= time.Hour:
+		n, unit = int64(d/time.Hour), "hour"
+	case d >= time.Minute:
+		n, unit = int64(d/time.Minute), "minute"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	s := r.p.Sprint(number.Decimal(n)) + " " + unit
+	if past {
+		r.b.WriteString(s + " ago")
+	} else {
+		r.b.WriteString("in " + s)
+	}
+}
+
+var (
+	englishOnes = [...]string{
+		"zero", "one", "two", "three", "four", "five", "six", "seven", "eight",
+		"nine", "ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen",
+		"sixteen", "seventeen", "eighteen", "nineteen",
+	}
+	englishTens = [...]string{
+		"", "", "twenty", "thirty", "forty", "fifty",
+		"sixty", "seventy", "eighty", "ninety",
+	}
+	englishScales = [...]string{
+		"", "thousand", "million", "billion",
+		"trillion", "quadrillion", "quintillion",
+	}
+)
+
+// spellOutEnglish spells out i in English such as
+// "one hundred twenty-three thousand four hundred fifty-six".
+func spellOutEnglish(i int64) string {
+	if i == 0 {
+		return englishOnes[0]
+	}
+	var words []string
+	u := uint64(i)
+	if i < 0 {
+		words = append(words, "minus")
+		u = -u
+	}
+	// Split into groups of three digits, most significant first.
+	var groups []uint64
+	for ; u > 0; u /= 1000 {
+		groups = append(groups, u%1000)
+	}
+	for g := len(groups) - 1; g >= 0; g-- {
+		n := groups[g]
+		if n == 0 {
+			continue
+		}
+		if n >= 100 {
+			words = append(words, englishOnes[n/100], "hundred")
+			n %= 100
+		}
+		switch {
+		case n >= 20 && n%10 != 0:
+			words = append(words, englishTens[n/10]+"-"+englishOnes[n%10])
+		case n >= 20:
+			words = append(words, englishTens[n/10])
+		case n > 0:
+			words = append(words, englishOnes[n])
+		}
+		if g > 0 {
+			words = append(words, englishScales[g])
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func (r *renderer) ordinalSuffix(i int64) string {
+	if base, _ := r.locale.Base(); base.String() != "en" {
+		return ""
+	}
+	if i < 0 {
+		i = -i
+	}
+	switch plural.Ordinal.MatchPlural(language.English, int(i), 0, 0, 0, 0) {
+	case plural.One:
+		return "st"
+	case plural.Two:
+		return "nd"
+	case plural.Few:
+		return "rd"
+	}
+	return "th"
+}
+
+func (r *renderer) Currency(index int, _ string) error {
+	return r.Argument(index, tik.FormatKindCurrency)
+}
+
+func (r *renderer) Ordinal(index int, suffix string) error {
+	if r.skip {
+		return nil
+	}
+	i, _ := toInt(r.args[index])
+	r.b.WriteString(r.p.Sprint(number.Decimal(i)))
+	r.b.WriteString(suffix)
+	return nil
+}
+
+// Unit writes the number followed by the CLDR unit identifier such as
+// "5 kilometer" since x/text provides no unit display names.
+func (r *renderer) Unit(index int, unit string) error {
+	if r.skip {
+		return nil
+	}
+	r.b.WriteString(r.p.Sprint(number.Decimal(r.args[index])))
+	r.b.WriteString(" " + unit)
+	return nil
+}
+
+// NumberSkeleton writes the number applying the fraction precision stems
+// such as `.00` and `.0#` and the `group-off` stem of skeleton.
+// Other stems are ignored.
+func (r *renderer) NumberSkeleton(index int, skeleton string) error {
+	if r.skip {
+		return nil
+	}
+	var opts []number.Option
+	for _, stem := range strings.Fields(skeleton) {
+		switch {
+		case stem == "group-off":
+			opts = append(opts, number.NoSeparator())
+		case strings.HasPrefix(stem, ".") &&
+			strings.TrimLeft(strings.TrimLeft(stem[1:], "0"), "#") == "":
+			opts = append(opts,
+				number.MinFractionDigits(strings.Count(stem, "0")),
+				number.MaxFractionDigits(len(stem)-1))
+		}
+	}
+	r.b.WriteString(r.p.Sprint(number.Decimal(r.args[index], opts...)))
+	return nil
+}
+
+func (r *renderer) PluralStart(index int) error {
+	count, _ := toInt(r.args[index])
+	r.plurals = append(r.plurals, cardinal{count: count, skip: r.skip})
+	return nil
+}
+
+func (r *renderer) PluralOffset(offset int) error {
+	r.plurals[len(r.plurals)-1].offset = int64(offset)
+	return nil
+}
+
+func (r *renderer) PluralExactMatch(value int) error {
+	p := &r.plurals[len(r.plurals)-1]
+	selected := !p.matched && int64(value) == p.count
+	p.matched = p.matched || selected
+	r.skip = p.skip || !selected
+	return nil
+}
+
+func (r *renderer) PluralOther() error {
+	p := &r.plurals[len(r.plurals)-1]
+	if r.skip = p.skip || p.matched; !r.skip {
+		r.b.WriteString(r.p.Sprint(number.Decimal(p.count - p.offset)))
+	}
+	return nil
+}
+
+func (r *renderer) PluralEnd() error {
+	r.skip = r.plurals[len(r.plurals)-1].skip
+	r.plurals = r.plurals[:len(r.plurals)-1]
+	return nil
+}
+
+func (r *renderer) MessageRef(string) error { return ErrMessageRef }
+
+func (r *renderer) RawICU(int, string) error { return ErrRawICU }
+
+// Tag writes the markup tag as is, such as `<a>`.
+func (r *renderer) Tag(_ int, name string, tag tik.TokenType) error {
+	if r.skip {
+		return nil
+	}
+	switch tag {
+	case tik.TokenTypeTagOpen:
+		r.b.WriteString("<" + name + ">")
+	case tik.TokenTypeTagClose:
+		r.b.WriteString("</" + name + ">")
+	default:
+		r.b.WriteString("<" + name + "/>")
+	}
+	return nil
+}
+
+func (r *renderer) Select(index int, _ string, cases []tik.SelectCase) error {
+	if r.skip {
+		return nil
+	}
+	var key string
+	switch v := r.args[index].(type) {
+	case bool:
+		key = strconv.FormatBool(v)
+	case string:
+		key = v
+	}
+	for _, c := range cases {
+		if c.Key == key {
+			r.b.WriteString(c.Text)
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package render_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tik "github.com/romshark/tik/tik-go"
+	"github.com/romshark/tik/tik-go/render"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	date := time.Date(2025, time.March, 4, 15, 6, 7, 0, time.UTC)
+
+	f := func(t *testing.T, expect string, locale language.Tag, input string, args ...any) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := render.Render(tk, locale, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != expect {
+			t.Errorf("\nexpected: %q;\nreceived: %q", expect, actual)
+		}
+	}
+
+	f(t, "hello world", language.English, `[ctx] hello world`)
+	f(t, "Alice and Bob", language.English, `{name} and {text}`, "Alice", "Bob")
+	f(t, "1,234,567 and 1,234.5", language.English,
+		`{integer} and {number}`, 1234567, 1234.5)
+	f(t, "1.234.567 und 1.234,5", language.German,
+		`{integer} und {number}`, int64(1234567), float32(1234.5))
+	f(t, "costs $ 12.50", language.English,
+		`costs {currency}`, currency.USD.Amount(12.5))
+	f(t, "1st 2nd 3rd 4th 11th 22nd 1,003rd", language.English,
+		`{ordinal} {ordinal} {ordinal} {ordinal} {ordinal} {ordinal} {ordinal}`,
+		1, 2, 3, 4, 11, 22, 1003)
+	f(t, "1", language.German, `{ordinal}`, 1)
+	f(t, "1. 2nd 1,003º", language.English,
+		`{ordinal:.} {ordinal} {ordinal:º}`, 1, 2, 1003)
+	f(t, "Tuesday, March 4, 2025|March 4, 2025|Mar 4, 2025|3/4/25", language.English,
+		`{date-full}|{date-long}|{date-medium}|{date-short}`, date, date, date, date)
+	f(t, "3:06:07 PM GMT|3:06:07 PM UTC|3:06:07 PM|3:06 PM", language.English,
+		`{time-full}|{time-long}|{time-medium}|{time-short}`, date, date, date, date)
+	pdt := date.In(time.FixedZone("PDT", -7*60*60))
+	f(t, "3 days ago, in 1 hour, 25 minutes ago, in 5 seconds", language.English,
+		`{relative-time}, {relative-time}, {relative-time}, {relative-time}`,
+		-3*24*time.Hour-time.Hour, time.Hour+59*time.Second, -1500*time.Second, 5*time.Second)
+	f(t, "10–20.5 and 1,000–2,000", language.English,
+		`{number-range} and {number-range}`, 10, 20.5, 1000, 2000)
+	f(t, "1.000–2.500", language.German, `{number-range}`, 1000, 2500)
+	f(t, "one hundred", language.English, `{spellout}`, 100)
+	f(t, "minus twelve thousand three hundred forty-five, zero, "+
+		"one million one", language.English,
+		`{spellout}, {spellout}, {spellout}`, -12345, 0, 1000001)
+	f(t, "100", language.German, `{spellout}`, 100)
+	f(t, "Mar 4, 2025, 3:06 PM", language.English, `{date-time}`, date)
+	f(t, "Week 10, Q1", language.English, `Week {week-of-year}, {quarter}`, date, date)
+	f(t, "Bob, 3 files", language.English, `{skip}{text}, {integer} files`, "Alice", "Bob", 3)
+	f(t, "25‰ of 5 kilometer", language.English, `{permille} of {unit:kilometer}`, 0.025, 5)
+	f(t, "1,234.50, 1.5, 1234", language.English,
+		`{number::.00}, {number::.0#}, {number::group-off}`, 1234.5, 1.5, 1234)
+	f(t, "8:06 AM (PDT)", language.English, `{time-short} ({time-zone})`, pdt, pdt)
+	f(t, "8:06:07 AM GMT-07:00|8:06:07 AM PDT", language.English,
+		`{time-full}|{time-long}`, pdt, pdt)
+
+	// Cardinal plurals.
+	f(t, "Alice had 1,000 messages", language.English,
+		`{name} had {# messages}`, "Alice", 1000)
+	const arms = `{# offset:1 |=0 nobody |=1 only {name} | others and {name}}`
+	f(t, "nobody", language.English, arms, 0, "Alice", "Bob")
+	f(t, "only Alice", language.English, arms, 1, "Alice", "Bob")
+	f(t, "4 others and Bob", language.English, arms, 5, "Alice", "Bob")
+	f(t, "Wi-Fi is on", language.English, `Wi-Fi is {bool:on/off}`, true)
+	f(t, "Wi-Fi is off", language.English, `Wi-Fi is {bool:on/off}`, false)
+	f(t, "Order ", language.English, `Order {select:status(pending)}`, "pending")
+	f(t, "2 a, none", language.English,
+		`{# a}, {# |=0 none | b}`, 2, 0)
+}
+
+func TestRenderNestedPlural(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.AllowNestedPlural = true
+	tk, err := tik.NewParser(conf).Parse(
+		`{# |=0 no files | files in {# |=1 one folder | folders}} by {name}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := func(t *testing.T, expect string, args ...any) {
+		t.Helper()
+		actual, err := render.Render(tk, language.English, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != expect {
+			t.Errorf("\nexpected: %q;\nreceived: %q", expect, actual)
+		}
+	}
+
+	f(t, "no files by Alice", 0, 1, "Alice")
+	f(t, "no files by Alice", 0, 5, "Alice")
+	f(t, "3 files in one folder by Alice", 3, 1, "Alice")
+	f(t, "3 files in 2 folders by Alice", 3, 2, "Alice")
+}
+
+func TestRendererConfig(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.GenderSelect = true
+	conf.CustomResolver = func(directive string) (tik.TokenType, bool) {
+		switch directive {
+		case "sku":
+			return tik.TokenTypeCustom, true
+		case "price":
+			return tik.TokenTypeCustom + 1, true
+		}
+		return 0, false
+	}
+	conf.CustomICU = func(t tik.Token) (string, bool) {
+		if t.Type != tik.TokenTypeCustom+1 {
+			return "", false
+		}
+		return "{var, number, ::currency/EUR}", true
+	}
+	p := tik.NewParser(conf)
+	r := render.NewRenderer(conf)
+
+	tk, err := p.Parse(`{name} ordered {sku}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := r.Render(tk, language.English, "Alice", "A-42", "female")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Alice ordered A-42"; actual != expect {
+		t.Errorf("\nexpected: %q;\nreceived: %q", expect, actual)
+	}
+
+	// The gender argument is required.
+	_, err = r.Render(tk, language.English, "Alice", "A-42")
+	if !errors.Is(err, render.ErrArgCount) {
+		t.Fatalf("expected error %v, received: %v", render.ErrArgCount, err)
+	}
+
+	// Custom placeholders generated as raw ICU can't be rendered.
+	tk, err = p.Parse(`costs {price}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Render(tk, language.English, "12")
+	if !errors.Is(err, render.ErrRawICU) {
+		t.Fatalf("expected error %v, received: %v", render.ErrRawICU, err)
+	}
+}
+
+func TestRenderErr(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect error, expectMsg, input string, args ...any) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := render.Render(tk, language.English, args...)
+		if !errors.Is(err, expect) {
+			t.Fatalf("expected error %v, received: %v", expect, err)
+		}
+		if err.Error() != expectMsg {
+			t.Errorf("\nexpected: %q;\nreceived: %q", expectMsg, err.Error())
+		}
+		if actual != "" {
+			t.Errorf("unexpected result: %q", actual)
+		}
+	}
+
+	f(t, render.ErrArgCount, "wrong number of arguments: expected 2, received 1",
+		`{name} {integer}`, "Alice")
+	f(t, render.ErrArgType,
+		"wrong argument type: argument 1 (integer) must be integer, received string",
+		`{name} {integer}`, "Alice", "5")
+	f(t, render.ErrArgType,
+		"wrong argument type: argument 0 (pluralization) "+
+			"must be integer, received float64",
+		`{# items}`, 1.5)
+	f(t, render.ErrArgType,
+		"wrong argument type: argument 0 (currency) must be currency.Amount, received int",
+		`{currency}`, 5)
+	f(t, render.ErrArgType,
+		"wrong argument type: argument 0 (date full) must be time.Time, received string",
+		`{date-full}`, "today")
+	f(t, render.ErrArgType,
+		"wrong argument type: argument 0 (boolean) must be bool, received string",
+		`{bool:on/off}`, "on")
+	f(t, render.ErrMessageRef, "message references can't be rendered", `see {@help}`)
+}
@@ -0,0 +1,164 @@
+package tik
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrStringsdictNoPlural = errors.New(
+		"stringsdict entries require a cardinal pluralization")
+	ErrStringsdictMultiplePlurals = errors.New(
+		"stringsdict output doesn't support multiple cardinal pluralizations")
+	ErrStringsdictUnsupported = errors.New("construct not representable in stringsdict")
+)
+
+// StringsdictTranslator is a reusable TIK to Apple .stringsdict translator.
+//
+// Placeholders are translated to positional arguments (`%1$@`, `%2$ld`)
+// and the cardinal pluralization becomes a `%2$#@var1@` variable
+// with an NSStringPluralRuleType rule scaffold containing only the
+// `other` category. A TIK must contain exactly one cardinal pluralization,
+// TIKs without pluralization belong in .strings files and multiple
+// pluralizations aren't supported.
+// The context becomes an XML comment preceding the entry.
+type StringsdictTranslator struct {
+	icu ICUTranslator
+	v   stringsdictVisitor
+}
+
+func NewStringsdictTranslator(conf Config) *StringsdictTranslator {
+	return &StringsdictTranslator{icu: ICUTranslator{conf: conf}}
+}
+
+// TIK2Stringsdict translates tik into a .stringsdict property list
+// containing a single entry for key.
+// Returns ErrStringsdictNoPlural if tik contains no cardinal pluralization,
+// ErrStringsdictMultiplePlurals if it contains more than one and
+// ErrStringsdictUnsupported for pluralization arms and message references.
+func (s *StringsdictTranslator) TIK2Stringsdict(tik TIK, key string) ([]byte, error) {
+	s.v.format.Reset()
+	s.v.rule.Reset()
+	s.v.conf, s.v.plurals, s.v.inPlural = &s.icu.conf, 0, false
+	if err := s.icu.Visit(tik, &s.v); err != nil {
+		return nil, err
+	}
+	if s.v.plurals == 0 {
+		return nil, ErrStringsdictNoPlural
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" ` +
+		`"http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	if c := tik.Context(); c != "" {
+		// "--" isn't allowed inside of XML comments.
+		b.WriteString("\t<!-- ")
+		b.WriteString(strings.ReplaceAll(c, "--", "- -"))
+		b.WriteString(" -->\n")
+	}
+	b.WriteString("\t<key>" + replacerEscapeXML.Replace(key) + "</key>\n")
+	b.WriteString("\t<dict>\n")
+	b.WriteString("\t\t<key>NSStringLocalizedFormatKey</key>\n")
+	b.WriteString("\t\t<string>" + s.v.format.String() + "</string>\n")
+	b.WriteString("\t\t<key>" + s.v.variable + "</key>\n")
+	b.WriteString("\t\t<dict>\n")
+	b.WriteString("\t\t\t<key>NSStringFormatSpecTypeKey</key>\n")
+	b.WriteString("\t\t\t<string>NSStringPluralRuleType</string>\n")
+	b.WriteString("\t\t\t<key>NSStringFormatValueTypeKey</key>\n")
+	b.WriteString("\t\t\t<string>ld</string>\n")
+	b.WriteString("\t\t\t<key>other</key>\n")
+	b.WriteString("\t\t\t<string>" + s.v.rule.String() + "</string>\n")
+	b.WriteString("\t\t</dict>\n")
+	b.WriteString("\t</dict>\n")
+	b.WriteString("</dict>\n</plist>\n")
+	return b.Bytes(), nil
+}
+
+var replacerEscapeXML = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+type stringsdictVisitor struct {
+	conf     *Config
+	format   strings.Builder
+	rule     strings.Builder
+	variable string
+	plurals  int
+	inPlural bool
+}
+
+func (v *stringsdictVisitor) out() *strings.Builder {
+	if v.inPlural {
+		return &v.rule
+	}
+	return &v.format
+}
+
+func (v *stringsdictVisitor) Literal(s string) error {
+	v.out().WriteString(replacerEscapeXML.Replace(strings.ReplaceAll(s, "%", "%%")))
+	return nil
+}
+
+func (v *stringsdictVisitor) Argument(index int, kind FormatKind) error {
+	verb, _, ok := printfVerb(kind)
+	if !ok {
+		return ErrStringsdictUnsupported
+	}
+	spec := "@"
+	if verb == 'd' {
+		spec = "ld"
+	}
+	v.out().WriteString("%" + strconv.Itoa(index+1) + "$" + spec)
+	return nil
+}
+
+func (v *stringsdictVisitor) Currency(index int, _ string) error {
+	return v.Argument(index, FormatKindCurrency)
+}
+
+func (v *stringsdictVisitor) Ordinal(index int, _ string) error {
+	return v.Argument(index, FormatKindOrdinal)
+}
+
+func (v *stringsdictVisitor) Unit(index int, _ string) error {
+	return v.Argument(index, FormatKindUnit)
+}
+
+func (v *stringsdictVisitor) NumberSkeleton(index int, _ string) error {
+	return v.Argument(index, FormatKindNumber)
+}
+
+func (v *stringsdictVisitor) PluralStart(index int) error {
+	if v.plurals++; v.plurals > 1 {
+		return ErrStringsdictMultiplePlurals
+	}
+	v.variable = v.conf.argName(index)
+	pos := strconv.Itoa(index + 1)
+	v.format.WriteString("%" + pos + "$#@" + v.variable + "@")
+	v.rule.WriteString("%" + pos + "$ld")
+	v.inPlural = true
+	return nil
+}
+
+func (v *stringsdictVisitor) PluralOffset(int) error     { return ErrStringsdictUnsupported }
+func (v *stringsdictVisitor) PluralExactMatch(int) error { return ErrStringsdictUnsupported }
+func (v *stringsdictVisitor) PluralOther() error         { return nil }
+func (v *stringsdictVisitor) MessageRef(string) error    { return ErrStringsdictUnsupported }
+func (v *stringsdictVisitor) RawICU(int, string) error   { return ErrStringsdictUnsupported }
+func (v *stringsdictVisitor) Select(int, string, []SelectCase) error {
+	return ErrStringsdictUnsupported
+}
+
+func (v *stringsdictVisitor) Tag(_ int, name string, tag TokenType) error {
+	v.out().WriteString(replacerEscapeXML.Replace(markupTag(name, tag)))
+	return nil
+}
+
+func (v *stringsdictVisitor) PluralEnd() error {
+	v.inPlural = false
+	return nil
+}
@@ -0,0 +1,89 @@
+package tik_test
+
+import (
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestStringsdictTranslator(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewStringsdictTranslator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect, key, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		actual, err := translator.TIK2Stringsdict(tk, key)
+		requireNoErr(t, err)
+		requireEqual(t, expect, string(actual))
+	}
+
+	f(t, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>msg</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%1$#@var0@</string>
+		<key>var0</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>ld</string>
+			<key>other</key>
+			<string>%1$ld messages</string>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`, "msg", `{# messages}`)
+
+	f(t, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<!-- inbox -->
+	<key>a&amp;b</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%1$@ &lt;%2$ld&gt; had %3$#@var2@ (100%%).</string>
+		<key>var2</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>ld</string>
+			<key>other</key>
+			<string>%3$ld messages from %4$@ on %5$@</string>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`, "a&b", `[inbox] {name} <{integer}> had {# messages from {text} on {date-short}} (100%).`)
+}
+
+func TestStringsdictTranslatorErr(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewStringsdictTranslator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect error, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		actual, err := translator.TIK2Stringsdict(tk, "msg")
+		requireErrIs(t, expect, err)
+		requireEqual(t, 0, len(actual))
+	}
+
+	f(t, tik.ErrStringsdictNoPlural, `hello {name}`)
+	f(t, tik.ErrStringsdictMultiplePlurals, `{# messages} in {# folders}`)
+	f(t, tik.ErrStringsdictUnsupported, `{# |=0 none | messages}`)
+	f(t, tik.ErrStringsdictUnsupported, `see {@help}`)
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"slices"
+	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -63,8 +65,106 @@ const (
 
 	// Currency.
 	TokenTypeCurrency // {currency}
+
+	// TokenTypeMessageRef is a reference to another message
+	// that's resolved by the runtime and doesn't consume an argument.
+	TokenTypeMessageRef // {@key}
+
+	// Cardinal pluralization arms.
+	TokenTypePluralOffset     // `offset:1` in `{# offset:1 |=0 ... | ...}`
+	TokenTypePluralExactMatch // `|=0` in `{# |=0 ... | ...}`
+	TokenTypePluralOther      // `|` in `{# |=0 ... | ...}`
+
+	// TokenTypeBoolean is a boolean placeholder carrying the surface words
+	// of the true and the false state.
+	TokenTypeBoolean // {bool:on/off}
+
+	// TokenTypeSelect is an enum select placeholder carrying
+	// the argument name and the set of options.
+	TokenTypeSelect // {select:status(pending,shipped,delivered)}
+
+	// TokenTypeStringPlaceholder is a text placeholder carrying a quoted
+	// sample value for translators. Unlike TokenTypeText the sample
+	// shows translators what the argument could look like,
+	// the generated ICU argument is the same.
+	TokenTypeStringPlaceholder // {"John"}
+
+	// TokenTypeTimeZone equals the time zone formatted by
+	// Config.TimeZoneSkeleton, "Pacific Daylight Time" by default.
+	TokenTypeTimeZone // {time-zone}
+
+	// TokenTypeRelativeTime equals "3 days ago" or "in 3 days".
+	TokenTypeRelativeTime // {relative-time}
+
+	// TokenTypeNumberSpellout equals "one hundred".
+	TokenTypeNumberSpellout // {spellout}
+
+	// Markup tags enabled by Config.MarkupTags.
+	// Tags don't consume arguments and must be balanced.
+	TokenTypeTagOpen        // {<a>}
+	TokenTypeTagClose       // {</a>}
+	TokenTypeTagSelfClosing // {<br/>}
+
+	// TokenTypeRawICU is raw ICU emitted verbatim that doesn't reference
+	// an argument and hence doesn't consume one.
+	TokenTypeRawICU // {icu:...}
+
+	// TokenTypeRawICUArgument is raw ICU emitted verbatim that references
+	// its positional argument as `{var` such as
+	// `{icu:{var, number, ::percent scale/100}}`.
+	TokenTypeRawICUArgument // {icu:{var, ...}}
+
+	// TokenTypeWeekOfYear equals the week of the year such as "33".
+	TokenTypeWeekOfYear // {week-of-year}
+
+	// TokenTypeQuarter equals the abbreviated quarter such as "Q3".
+	TokenTypeQuarter // {quarter}
+
+	// TokenTypeNumberRange equals a range of two numbers such as "10–20".
+	// It consumes two positional arguments, the start and the end.
+	TokenTypeNumberRange // {number-range}
+
+	// TokenTypePhone equals a telephone number such as "+1 555 0100".
+	// The runtime passes the number preformatted as a string,
+	// hence it's never subject to locale number formatting.
+	TokenTypePhone // {phone}
+
+	// TokenTypeEmail equals an email address such as "alice@example.com".
+	TokenTypeEmail // {email}
+
+	// TokenTypeURL equals a URL such as "https://example.com".
+	TokenTypeURL // {url}
+
+	// TokenTypeUnit equals a measurement such as "5 km" of the CLDR unit
+	// declared after the colon such as `kilometer` or `celsius`.
+	TokenTypeUnit // {unit:kilometer}
+
+	// TokenTypePermille equals a fraction in per mille such as "25‰"
+	// for the argument 0.025.
+	TokenTypePermille // {permille}
+
+	// TokenTypeSkip consumes an argument without rendering it such that
+	// the positional arguments of a translation stay aligned with
+	// a fixed runtime signature even if its wording doesn't use one.
+	TokenTypeSkip // {skip}
+
+	// TokenTypeDateTime equals the medium date and short time of
+	// a single argument such as "Jul 16, 1999, 10:30 PM".
+	TokenTypeDateTime // {date-time}
+
+	// TokenTypeNumberSkeleton equals a number formatted by the ICU number
+	// skeleton declared after `::` such as `.00` for two fraction digits.
+	TokenTypeNumberSkeleton // {number::.00}
 )
 
+// TokenTypeCustom is the first token type of custom placeholders
+// resolved by Config.CustomResolver. All token types from TokenTypeCustom
+// on are custom and their meaning is defined by the caller.
+const TokenTypeCustom TokenType = 128
+
+// IsCustom returns true for custom placeholders.
+func (t TokenType) IsCustom() bool { return t >= TokenTypeCustom }
+
 func (t TokenType) String() string {
 	switch t {
 	case TokenTypeContext:
@@ -103,10 +203,135 @@ func (t TokenType) String() string {
 		return `date full`
 	case TokenTypeCurrency:
 		return `currency`
+	case TokenTypeMessageRef:
+		return `message reference`
+	case TokenTypePluralOffset:
+		return `pluralization offset`
+	case TokenTypePluralExactMatch:
+		return `pluralization exact match`
+	case TokenTypePluralOther:
+		return `pluralization other`
+	case TokenTypeBoolean:
+		return `boolean`
+	case TokenTypeSelect:
+		return `select`
+	case TokenTypeStringPlaceholder:
+		return `string placeholder`
+	case TokenTypeTimeZone:
+		return `time zone`
+	case TokenTypeRelativeTime:
+		return `relative time`
+	case TokenTypeNumberSpellout:
+		return `number spellout`
+	case TokenTypeTagOpen:
+		return `tag open`
+	case TokenTypeTagClose:
+		return `tag close`
+	case TokenTypeTagSelfClosing:
+		return `tag self-closing`
+	case TokenTypeRawICU:
+		return `raw ICU`
+	case TokenTypeRawICUArgument:
+		return `raw ICU argument`
+	case TokenTypeWeekOfYear:
+		return `week of year`
+	case TokenTypeQuarter:
+		return `quarter`
+	case TokenTypeNumberRange:
+		return `number range`
+	case TokenTypePhone:
+		return `phone`
+	case TokenTypeEmail:
+		return `email`
+	case TokenTypeURL:
+		return `url`
+	case TokenTypeUnit:
+		return `unit`
+	case TokenTypePermille:
+		return `permille`
+	case TokenTypeSkip:
+		return `skip`
+	case TokenTypeDateTime:
+		return `date time`
+	case TokenTypeNumberSkeleton:
+		return `number skeleton`
+	}
+	if t.IsCustom() {
+		return "custom " + strconv.Itoa(int(t-TokenTypeCustom))
 	}
 	return "unknown"
 }
 
+// isNameable returns true for placeholders that can be named
+// if Config.NamedPlaceholders is enabled.
+func (t TokenType) isNameable() bool {
+	switch t {
+	case TokenTypeCurrency, TokenTypeOrdinalPlural, TokenTypeBoolean,
+		TokenTypeSelect, TokenTypeStringPlaceholder, TokenTypeRawICUArgument,
+		TokenTypeCardinalPluralStart, TokenTypeUnit, TokenTypeSkip,
+		TokenTypeNumberSkeleton:
+		return false
+	}
+	return t.IsPlaceholder() && !t.IsCustom()
+}
+
+// IsPlaceholder returns true for placeholders that consume an argument,
+// including the start of a cardinal pluralization.
+func (t TokenType) IsPlaceholder() bool {
+	switch t {
+	case TokenTypeText, TokenTypeTextWithGender,
+		TokenTypeInteger, TokenTypeNumber,
+		TokenTypeCardinalPluralStart, TokenTypeOrdinalPlural,
+		TokenTypeCurrency, TokenTypeBoolean, TokenTypeSelect,
+		TokenTypeStringPlaceholder, TokenTypeRelativeTime,
+		TokenTypeNumberSpellout, TokenTypeRawICUArgument,
+		TokenTypeNumberRange, TokenTypePhone, TokenTypeEmail, TokenTypeURL,
+		TokenTypeUnit, TokenTypePermille, TokenTypeSkip, TokenTypeNumberSkeleton:
+		return true
+	}
+	return t.IsDate() || t.IsTime() || t.IsCustom()
+}
+
+// argCount returns the number of positional arguments
+// a token of type t consumes.
+func (t TokenType) argCount() int {
+	switch {
+	case t == TokenTypeNumberRange:
+		return 2
+	case t.IsPlaceholder():
+		return 1
+	}
+	return 0
+}
+
+// IsDate returns true for date, date time, week of year
+// and quarter placeholders.
+func (t TokenType) IsDate() bool {
+	return t >= TokenTypeDateFull && t <= TokenTypeDateShort ||
+		t == TokenTypeDateTime || t == TokenTypeWeekOfYear || t == TokenTypeQuarter
+}
+
+// IsTime returns true for time and time zone placeholders.
+func (t TokenType) IsTime() bool {
+	return t >= TokenTypeTimeFull && t <= TokenTypeTimeShort ||
+		t == TokenTypeTimeZone
+}
+
+// IsCurrency returns true for currency placeholders.
+func (t TokenType) IsCurrency() bool { return t == TokenTypeCurrency }
+
+// IsPlural returns true for tokens of cardinal and ordinal pluralizations,
+// including the end, the offset and the arms of a cardinal pluralization.
+func (t TokenType) IsPlural() bool {
+	switch t {
+	case TokenTypeCardinalPluralStart, TokenTypeCardinalPluralEnd,
+		TokenTypeOrdinalPlural, TokenTypePluralOffset,
+		TokenTypePluralExactMatch, TokenTypePluralOther:
+		return true
+	}
+	return false
+}
+
 // Token is a lexical TIK token.
 type Token struct {
 	// IndexStart defines the start index of this token in the original TIK.
@@ -114,21 +339,82 @@ type Token struct {
 	// IndexEnd defines the end index of this token in the original TIK.
 	IndexEnd int
 	Type     TokenType
+
+	// Value is the value carried by the placeholder, if any.
+	// For TokenTypeMessageRef it's the key of the referenced message.
+	// For TokenTypePluralOffset and TokenTypePluralExactMatch
+	// it's the decimal number.
+	// For TokenTypeBoolean it's the surface words separated by `/`.
+	// For TokenTypeSelect it's the argument name followed by
+	// the parenthesized comma-separated options.
+	// For TokenTypeStringPlaceholder it's the sample value without quotes.
+	// For markup tags it's the tag name.
+	// For TokenTypeCurrency it's the upper case ISO 4217 currency code
+	// if declared, such as `EUR` in `{currency:EUR}`.
+	// For TokenTypeOrdinalPlural it's the suffix overriding
+	// Config.OrdinalPluralOtherSuffix if declared, such as `º` in `{ordinal:º}`.
+	// For raw ICU it's the ICU without the `icu:` prefix.
+	// For TokenTypeUnit it's the unit such as `kilometer` in `{unit:kilometer}`.
+	// For TokenTypeNumberSkeleton it's the ICU number skeleton
+	// such as `.00` in `{number::.00}`.
+	// For named placeholders it's the name, such as `user` in `{text:user}`.
+	Value string
+
+	// Hint is the unescaped translator hint of a placeholder
+	// such as `the recipient's full name` in `{text # the recipient's full name}`.
+	Hint string
 }
 
 var replacerTokenStringify = strings.NewReplacer("\\\\", "\\", "\\{", "{", "\\}", "}")
 
+// replacerStringSample additionally unescapes the quotes
+// of the sample of string placeholders.
+var replacerStringSample = strings.NewReplacer(
+	"\\\\", "\\", "\\{", "{", "\\}", "}", `\"`, `"`)
+
 func (t Token) String(source string) string {
 	s := source[t.IndexStart:t.IndexEnd]
 	if strings.IndexByte(s, '\\') == -1 {
 		// Fast path, no reverse solidus
 		return s
 	}
+	if t.Type == TokenTypeStringPlaceholder {
+		return replacerStringSample.Replace(s)
+	}
 	return replacerTokenStringify.Replace(s)
 }
 
+// Directive returns the unescaped content of the placeholder,
+// message reference or markup tag token t in source without the curly
+// braces and the translator hint, such as "date-short" for
+// `{date-short # due date}` or "@help" for `{@help}`.
+// Placeholder keywords are returned in lower case and the start of a
+// cardinal pluralization returns "#" regardless of
+// Config.CardinalPluralNumberSign.
+// Returns an empty string for literals, the context
+// and the other tokens of cardinal pluralizations.
+func (t Token) Directive(source string) string {
+	switch t.Type {
+	case TokenTypeCardinalPluralStart:
+		return "#"
+	case TokenTypeLiteral, TokenTypeContext, TokenTypeCardinalPluralEnd,
+		TokenTypePluralOffset, TokenTypePluralExactMatch, TokenTypePluralOther:
+		return ""
+	}
+	d := source[t.IndexStart+1 : t.IndexEnd-1]
+	if t.Hint != "" {
+		name, _, _ := cutHint(d)
+		d = strings.TrimRightFunc(name, unicode.IsSpace)
+	}
+	if t.Type == TokenTypeStringPlaceholder {
+		return replacerStringSample.Replace(d)
+	}
+	return replacerTokenStringify.Replace(foldKeyword(d))
+}
+
 var (
 	ErrTextEmpty                   = errors.New("empty text body")
+	ErrInvalidUTF8                 = errors.New("invalid UTF-8")
 	ErrUnexpClosure                = errors.New("unexpected directive closure")
 	ErrUnknownPlaceholder          = errors.New("unknown placeholder")
 	ErrCardinalPluralEmpty         = errors.New("empty cardinal pluralization")
@@ -138,31 +424,473 @@ var (
 	ErrContextEmpty                = errors.New("empty context")
 	ErrContextInvalid              = errors.New("invalid context")
 	ErrContextNoSeparator          = errors.New("missing whitespace after context")
+	ErrContextSegmentEmpty         = errors.New("empty context segment")
+	ErrContextTooLong              = errors.New("context exceeds maximum length")
+	ErrTooComplex                  = errors.New("exceeds maximum complexity")
+	ErrTooManyPlaceholders         = errors.New("exceeds maximum number of placeholders")
 	ErrCardinalPluralTrailingSpace = errors.New(
 		"cardinal pluralization ends with whitespace")
 	ErrDirectiveStartsCardinalPlural = errors.New(
 		"directive starts a cardinal pluralization")
+	ErrInvalidEscape            = errors.New("invalid escape sequence")
+	ErrMessageRefInvalid        = errors.New("invalid message reference")
+	ErrHintInvalid              = errors.New("invalid placeholder hint")
+	ErrBooleanInvalid           = errors.New("invalid boolean placeholder")
+	ErrSelectInvalid            = errors.New("invalid select placeholder")
+	ErrStringPlaceholderInvalid = errors.New("invalid string placeholder")
+	ErrCurrencyCodeInvalid      = errors.New("invalid currency code")
+	ErrTagInvalid               = errors.New("invalid or unknown markup tag")
+	ErrTagUnbalanced            = errors.New("unbalanced markup tag")
+	ErrRawICUInvalid            = errors.New("invalid raw ICU")
+	ErrPlaceholderNameInvalid   = errors.New("invalid placeholder name")
+	ErrOrdinalSuffixInvalid     = errors.New("invalid ordinal suffix")
+	ErrUnitInvalid              = errors.New("invalid measurement unit")
+	ErrNumberSkeletonInvalid    = errors.New("invalid number skeleton")
+	ErrDuplicatePlaceholderName = errors.New("duplicate placeholder name")
+	ErrPluralArmInvalid         = errors.New("invalid pluralization arm")
+	ErrPluralOtherArmMissing    = errors.New("missing pluralization other arm")
 )
 
 // Config defines the TIK environment configuration.
 type Config struct {
-	OrdinalPluralOtherSuffix string
+	OrdinalPluralOtherSuffix string `json:"ordinalPluralOtherSuffix"`
+
+	// StrictEscapes enables rejecting dangling reverse solidus at the end of
+	// the text and reverse solidus followed by any character other than
+	// `{`, `}` or `\` with ErrInvalidEscape.
+	// By default, such reverse solidus are treated as literal text.
+	StrictEscapes bool `json:"strictEscapes"`
+
+	// ContextBrackets defines the opening and closing brackets of the context
+	// such as `【` and `】`. If zero, `[` and `]` apply.
+	ContextBrackets [2]rune `json:"-"`
+
+	// ICUVarPrefix is the prefix of positional argument names
+	// such as `arg` in `{arg0}`. If empty, `var` applies.
+	// The prefix must start with a letter or `_` followed by letters,
+	// digits or `_`.
+	ICUVarPrefix string `json:"icuVarPrefix"`
+
+	// CardinalPluralNumberSign is the sign starting a cardinal pluralization
+	// such as `№` in `{№ messages}`. If empty, `#` applies.
+	// The sign must not contain whitespace, `{`, `}`, `\`, `|` or `@`
+	// and must not be a prefix of a placeholder keyword.
+	CardinalPluralNumberSign string `json:"cardinalPluralNumberSign"`
+
+	// TimeZoneSkeleton is the ICU date skeleton of time zone placeholders
+	// such as `z` for "PDT". If empty, `zzzz` ("Pacific Daylight Time")
+	// applies. The skeleton must be one of `z`, `zzzz`, `O`, `OOOO`,
+	// `v` and `vvvv`.
+	TimeZoneSkeleton string `json:"timeZoneSkeleton"`
+
+	// AllowEmptyText enables accepting empty and whitespace-only TIKs
+	// as well as TIKs consisting of only a context such as `[spacer]`
+	// instead of rejecting them with ErrTextEmpty.
+	AllowEmptyText bool `json:"allowEmptyText"`
+
+	// PreserveEdgeWhitespace disables trimming whitespace at the start
+	// and the end of a TIK, which then becomes part of the edge literals,
+	// such as the separator `" · "`. Since leading whitespace isn't skipped,
+	// a context is only recognized at the very start of the TIK.
+	// The whitespace separating the context from the body is never part
+	// of the body. Whitespace-only TIKs remain empty.
+	PreserveEdgeWhitespace bool `json:"preserveEdgeWhitespace"`
+
+	// Normalize, if not nil, normalizes the input of Parser before
+	// tokenizing, such as nfc.Normalize of package
+	// github.com/romshark/tik/tik-go/nfc, which normalizes to Unicode
+	// normalization form C such that visually identical keys in composed
+	// and decomposed form are parsed equally.
+	// The raw string and the token indexes of the parsed TIK as well as
+	// the index of a ParseError then refer to the normalized input.
+	// Tokenizer.Tokenize never normalizes.
+	Normalize func(input string) string `json:"-"`
+
+	// NamedPlaceholders enables naming placeholders such as `user`
+	// in `{text:user}` and `count` in `{integer:count # number of items}`.
+	// Names must be ICU identifiers. Currency, ordinal, boolean, select,
+	// string and raw ICU placeholders can't be named.
+	// Parser rejects a name used for placeholders of different types,
+	// including the name of an enum select placeholder,
+	// with ErrDuplicatePlaceholderName.
+	NamedPlaceholders bool `json:"namedPlaceholders"`
+
+	// CaseInsensitiveKeywords enables matching placeholder keywords
+	// case-insensitively such that `{Date-Short}` and `{TEXT}` are accepted.
+	// Only the keyword is case-insensitive, values such as the options
+	// of `{select:status(a,b)}` or the sample of `{"John"}` are not.
+	// Keywords are case-sensitive by default.
+	CaseInsensitiveKeywords bool `json:"caseInsensitiveKeywords"`
+
+	// AllowNestedPlural enables cardinal pluralizations inside
+	// cardinal pluralizations such as `{# messages in {# folders}}`
+	// instead of rejecting them with ErrNestedPluralization.
+	// Each nested pluralization consumes its own argument.
+	AllowNestedPlural bool `json:"allowNestedPlural"`
+
+	// DisallowContext disables contexts such that a leading
+	// context bracket is part of the literal text:
+	// `[not a context] text` is a single literal.
+	DisallowContext bool `json:"disallowContext"`
+
+	// StrictContextSegments enables rejecting contexts with blank segments
+	// such as `[page::button]` or `[checkout/]` with ErrContextSegmentEmpty.
+	// Context segments are separated by `:` and `/`, see TIK.ContextSegments.
+	StrictContextSegments bool `json:"strictContextSegments"`
+
+	// BidiIsolateAddresses enables wrapping email and URL placeholders
+	// in the generated ICU message in a LEFT-TO-RIGHT ISOLATE (U+2066)
+	// and a POP DIRECTIONAL ISOLATE (U+2069) such that they're not
+	// reordered when embedded in right-to-left text.
+	BidiIsolateAddresses bool `json:"bidiIsolateAddresses"`
+
+	// BidiIsolatePlaceholders enables wrapping text, name, string, phone,
+	// email and URL placeholders in the generated ICU message in
+	// a FIRST STRONG ISOLATE (U+2068) and a POP DIRECTIONAL ISOLATE (U+2069)
+	// such that right-to-left values don't reorder surrounding left-to-right
+	// text and vice versa. Numbers and dates aren't isolated.
+	// BidiIsolateAddresses takes precedence for emails and URLs.
+	BidiIsolatePlaceholders bool `json:"bidiIsolatePlaceholders"`
+
+	// MaxContextLen is the maximum length of the context in bytes
+	// excluding the brackets. Longer contexts are rejected with
+	// ErrContextTooLong without scanning the input beyond the limit.
+	// Zero or less means unlimited.
+	MaxContextLen int `json:"maxContextLen"`
+
+	// MaxComplexity is the maximum TIK.Complexity of a TIK.
+	// More complex TIKs are rejected with ErrTooComplex at the index
+	// of the placeholder that pushes the score over the limit.
+	// Zero or less means unlimited.
+	MaxComplexity int `json:"maxComplexity"`
+
+	// MaxPlaceholders is the maximum number of placeholders of a TIK
+	// including cardinal pluralizations. Tokenization stops at the first
+	// placeholder exceeding the limit with ErrTooManyPlaceholders.
+	// Zero or less means unlimited.
+	MaxPlaceholders int `json:"maxPlaceholders"`
+
+	// UnknownPlaceholderAsLiteral enables treating unknown placeholders
+	// such as `{something}` as literal text including the braces
+	// instead of rejecting them with ErrUnknownPlaceholder,
+	// which helps migrating legacy keys. See TIK.UnknownPlaceholders.
+	UnknownPlaceholderAsLiteral bool `json:"unknownPlaceholderAsLiteral"`
+
+	// ScaffoldEnglishPlural enables generating ICU cardinal plurals with
+	// a `one` arm duplicating the content of the `other` arm such as
+	// `{var0, plural, one {# messages} other {# messages}}`, which gives
+	// translators of English source TIKs a more complete starting point.
+	ScaffoldEnglishPlural bool `json:"scaffoldEnglishPlural"`
+
+	// GenderSelect enables generating an ICU gender select for `{name}`
+	// placeholders such as `{var0_gender, select, female {{var0}}
+	// male {{var0}} other {{var0}}}` instead of `{var0}`, with an arm
+	// per gender category for translators to adjust. The arms are in
+	// the canonical order of SortGenderCategories. The select consumes
+	// the additional argument named after the placeholder's argument
+	// with the `_gender` suffix, see ICUTranslator.Args.
+	GenderSelect bool `json:"genderSelect"`
+
+	// GenderCategories are the selectors of the ICU gender select
+	// generated for `{name}` placeholders such as `female`, `male`
+	// and `other` or `animate`, `inanimate` and `other`.
+	// Must include `other` unless empty, in which case
+	// `female`, `male` and `other` are used.
+	GenderCategories []string `json:"genderCategories,omitempty"`
+
+	// CustomResolver, if not nil, resolves placeholders that aren't built in
+	// such as `{sku}` given the directive `sku` without the translator hint.
+	// It's consulted after the built-in keywords and returns false if
+	// the directive isn't a custom placeholder. The returned token type
+	// must either be custom (see TokenTypeCustom) or a built-in placeholder
+	// written by keyword alone such as TokenTypeText, otherwise the
+	// placeholder is rejected with ErrUnknownPlaceholder.
+	// The Value of custom tokens is the directive.
+	CustomResolver func(directive string) (TokenType, bool) `json:"-"`
+
+	// CustomICU, if not nil, returns the ICU of a custom placeholder
+	// referencing its argument as `{var` the same way raw ICU does,
+	// such as `{var, number, ::currency/EUR}`. Custom placeholders are
+	// generated as plain arguments such as `{var0}` if CustomICU is nil
+	// or returns false.
+	CustomICU func(t Token) (icu string, ok bool) `json:"-"`
+
+	// MarkupTags are the names of the markup tags recognized
+	// in `{<a>}`, `{</a>}` and `{<br/>}`. If empty, markup tags are disabled.
+	// Names must start with an ASCII letter followed by ASCII letters,
+	// digits or `-`.
+	MarkupTags []string `json:"markupTags,omitempty"`
 }
 
 var DefaultConfig = Config{
 	OrdinalPluralOtherSuffix: "th",
+	ContextBrackets:          [2]rune{'[', ']'},
+	ICUVarPrefix:             "var",
+	CardinalPluralNumberSign: "#",
+	TimeZoneSkeleton:         "zzzz",
+	GenderCategories:         []string{"female", "male", "other"},
+}
+
+var (
+	ErrConfigContextBrackets = errors.New("invalid context brackets")
+	ErrConfigICUVarPrefix    = errors.New("invalid ICU argument prefix")
+	ErrConfigPluralSign      = errors.New("invalid cardinal plural number sign")
+	ErrConfigTimeZone        = errors.New("invalid time zone skeleton")
+	ErrConfigMarkupTags      = errors.New("invalid markup tag name")
+	ErrConfigGender          = errors.New("invalid gender categories")
+)
+
+// Validate returns an error if c is invalid.
+func (c Config) Validate() error {
+	if c.ContextBrackets != [2]rune{} {
+		open, closing := c.ContextBrackets[0], c.ContextBrackets[1]
+		if open == closing {
+			return ErrConfigContextBrackets
+		}
+		for _, r := range c.ContextBrackets {
+			if r == 0 || !utf8.ValidRune(r) || unicode.IsSpace(r) ||
+				r == '{' || r == '}' || r == '\\' {
+				return ErrConfigContextBrackets
+			}
+		}
+	}
+	if c.ICUVarPrefix != "" && !isICUIdentifier(c.ICUVarPrefix) {
+		return ErrConfigICUVarPrefix
+	}
+	if sign := c.CardinalPluralNumberSign; sign != "" {
+		if !utf8.ValidString(sign) ||
+			strings.ContainsFunc(sign, unicode.IsSpace) ||
+			strings.ContainsAny(sign, "{}\\|@") {
+			return ErrConfigPluralSign
+		}
+		for _, e := range placeholderKeywords {
+			if strings.HasPrefix(e.keyword, sign) {
+				return ErrConfigPluralSign
+			}
+		}
+	}
+	if s := c.TimeZoneSkeleton; s != "" && timeZoneNames[s] == "" {
+		return ErrConfigTimeZone
+	}
+	for _, name := range c.MarkupTags {
+		if !isTagName(name) {
+			return ErrConfigMarkupTags
+		}
+	}
+	if len(c.GenderCategories) > 0 {
+		if !slices.Contains(c.GenderCategories, "other") {
+			return ErrConfigGender
+		}
+		for i, category := range c.GenderCategories {
+			if !isICUIdentifier(category) ||
+				slices.Contains(c.GenderCategories[:i], category) {
+				return ErrConfigGender
+			}
+		}
+	}
+	return nil
+}
+
+// timeZoneNames maps the supported ICU time zone skeletons
+// to the equivalent Intl.DateTimeFormat timeZoneName option.
+var timeZoneNames = map[string]string{
+	"z": "short", "zzzz": "long",
+	"O": "shortOffset", "OOOO": "longOffset",
+	"v": "shortGeneric", "vvvv": "longGeneric",
+}
+
+// timeZoneSkeleton returns the ICU date skeleton of time zone placeholders.
+func (c Config) timeZoneSkeleton() string {
+	if c.TimeZoneSkeleton == "" {
+		return "zzzz"
+	}
+	return c.TimeZoneSkeleton
+}
+
+// genderCategories returns the selectors of the ICU gender select.
+func (c Config) genderCategories() []string {
+	if len(c.GenderCategories) == 0 {
+		return []string{"female", "male", "other"}
+	}
+	return c.GenderCategories
+}
+
+// contextBrackets returns the opening and closing brackets of the context.
+func (c Config) contextBrackets() (open, closing rune) {
+	if c.ContextBrackets == [2]rune{} {
+		return '[', ']'
+	}
+	return c.ContextBrackets[0], c.ContextBrackets[1]
+}
+
+// pluralSign returns the sign starting a cardinal pluralization.
+func (c Config) pluralSign() string {
+	if c.CardinalPluralNumberSign == "" {
+		return "#"
+	}
+	return c.CardinalPluralNumberSign
+}
+
+// argName returns the name of the positional argument at index.
+func (c Config) argName(index int) string {
+	if c.ICUVarPrefix == "" {
+		return "var" + strconv.Itoa(index)
+	}
+	return c.ICUVarPrefix + strconv.Itoa(index)
+}
+
+// isArgName returns true if name is a positional argument name generated
+// by ICUTranslator such as "var0" or "var0_gender", which the names
+// of selects must not collide with.
+func (c Config) isArgName(name string) bool {
+	prefix := c.ICUVarPrefix
+	if prefix == "" {
+		prefix = "var"
+	}
+	n, ok := strings.CutPrefix(name, prefix)
+	n = strings.TrimSuffix(n, "_gender")
+	return ok && n != "" && strings.Trim(n, "0123456789") == ""
+}
+
+type Tokenizer struct {
+	// keywords is the keyword lookup of the last Config,
+	// which NewParser builds ahead of time.
+	keywords keywordLookup
+}
+
+// indexInvalidUTF8 returns the index of the first byte of s
+// that isn't part of a valid UTF-8 sequence, or -1 if s is valid.
+func indexInvalidUTF8(s string) int {
+	for i, r := range s {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return i
+			}
+		}
+	}
+	return -1
 }
 
-type Tokenizer struct{}
+// pluralState is the tokenizer state of a pluralization
+// enclosing a nested pluralization.
+type pluralState struct {
+	arms, other bool
+	tags, start int
+}
 
 // Tokenize appends all tokens from input to buffer and returns the buffer.
 // If c == nil the default configuration applies.
+//
+// Input must be valid UTF-8, otherwise ErrInvalidUTF8 is returned
+// at the index of the first invalid byte.
+//
+// A boolean placeholder defines the surface words of the true and the false
+// state: `Wi-Fi is {bool:on/off}`.
+//
+// An enum select placeholder defines the argument name and the options:
+// `{select:status(pending,shipped,delivered)}`. The name and the options
+// must be ICU identifiers, options must be unique and `other` is implicit.
+//
+// If c.NamedPlaceholders is enabled, placeholders may be named:
+// `{text:user}`. Tokenize doesn't check names for conflicting types,
+// Parser does.
+//
+// An ordinal placeholder may override the configured suffix: `{ordinal:º}`.
+// The suffix must not contain whitespace or any of `{}#'|\`.
+//
+// A currency placeholder may declare an ISO 4217 currency code
+// of three ASCII letters: `{currency:EUR}`.
+//
+// A number placeholder may declare an ICU number skeleton after `::`:
+// `{number::.00}`. The skeleton must not be blank, must not be surrounded
+// by whitespace and must not contain any of `{}\`.
+//
+// A string placeholder is a text placeholder with a quoted sample value:
+// `{"John"}`. The sample must not be empty and may contain `"`, `{`, `}`
+// and `\` only if escaped by a reverse solidus: `{"5\" \{wide\}"}`.
+//
+// A raw ICU placeholder such as `{icu:{var, number, ::percent}}` is emitted
+// verbatim into the ICU message. Its curly braces must be balanced,
+// ICU-quoted curly braces such as `'{'` don't count. If it references
+// `{var` followed by `,` or `}` it consumes one positional argument
+// and every reference is replaced by the argument name.
+// Raw ICU isn't validated beyond that.
+//
+// The context may contain `{`, `}`, `\` and the context brackets
+// only if escaped by a reverse solidus: `[array\[i\] label]`.
+//
+// Placeholders may carry a translator hint separated by whitespace and `#`:
+// `{text # the recipient's full name}`. Hints must not be blank and must not
+// contain unescaped `{` and `}`. Hints are stored in Token.Hint
+// and aren't part of the generated ICU message.
+//
+// A cardinal pluralization starts with c.CardinalPluralNumberSign (`#` by default).
+// A cardinal pluralization may define exact match arms that are followed by
+// the mandatory other arm: `{# |=0 no messages |=1 one message | messages}`.
+// Each exact match arm `|=N` must be followed by whitespace,
+// the content of the other arm follows `|` the same way regular
+// pluralization content follows `#`. Exact match arms may be preceded
+// by an offset: `{# offset:1 |=0 nobody |=1 {name} | others and {name}}`.
+// The content of arms must not contain `|`.
+//
+// Markup tags of c.MarkupTags such as `Click {<a>}here{</a>}{<br/>}` must be
+// balanced and tags opened inside a pluralization or one of its arms
+// must be closed within it.
 func (t *Tokenizer) Tokenize(buffer Tokens, s string, c Config) (Tokens, ParseError) {
-	inPluralDirective := false
+	if !utf8.ValidString(s) {
+		return nil, err(indexInvalidUTF8(s), ErrInvalidUTF8)
+	}
+	// pluralDepth is the number of open pluralizations,
+	// which is at most 1 unless c.AllowNestedPlural is enabled.
+	pluralDepth := 0
+	// tags are the buffer indexes of open markup tags, of which
+	// pluralTags were opened before the current pluralization.
+	var tags []int
+	pluralTags := 0
+	unbalanced := func() ParseError {
+		return err(buffer[tags[len(tags)-1]].IndexStart, ErrTagUnbalanced)
+	}
+	if !t.keywords.matches(c) {
+		t.keywords = newKeywordLookup(c)
+	}
+	pluralSign := t.keywords.pluralSign
+	// pluralArms is true when the current pluralization defines arms
+	// and pluralOther is true once its other arm was reached.
+	pluralArms, pluralOther := false, false
+	// pluralStart is the index of the current pluralization.
+	pluralStart := 0
+	// outerPlurals are the states of the pluralizations
+	// enclosing the current one.
+	var outerPlurals []pluralState
+	// placeholders is the number of placeholders tokenized so far.
+	placeholders := 0
+	tooManyPlaceholders := func() bool {
+		placeholders++
+		return c.MaxPlaceholders > 0 && placeholders > c.MaxPlaceholders
+	}
+	// startsPlural returns true if a directive at the end of buffer
+	// would be the first in a pluralization or its other arm.
+	startsPlural := func() bool {
+		b := buffer
+		if len(b) == 0 || pluralDepth == 0 {
+			return false
+		}
+		last := b[len(b)-1]
+		if last.Type == TokenTypeCardinalPluralStart ||
+			last.Type == TokenTypePluralOther {
+			return true
+		}
+		// A whitespace-only literal between plural start and directive
+		// still counts as "starts with a directive".
+		return last.Type == TokenTypeLiteral && len(b) > 1 &&
+			(b[len(b)-2].Type == TokenTypeCardinalPluralStart ||
+				b[len(b)-2].Type == TokenTypePluralOther) &&
+			strings.TrimSpace(s[last.IndexStart:last.IndexEnd]) == ""
+	}
 	offset := 0
 
 	// Skip prefix spaces.
-	for offset < len(s) {
+	for !c.PreserveEdgeWhitespace && offset < len(s) {
 		l, size := utf8.DecodeRuneInString(s[offset:])
 		if !unicode.IsSpace(l) {
 			break
@@ -170,27 +898,43 @@ func (t *Tokenizer) Tokenize(buffer Tokens, s string, c Config) (Tokens, ParseEr
 		offset += size
 	}
 
-	if offset >= len(s) {
+	if offset >= len(s) ||
+		c.PreserveEdgeWhitespace && strings.TrimSpace(s) == "" {
+		if c.AllowEmptyText {
+			return buffer, ParseError{}
+		}
 		return nil, err(0, ErrTextEmpty)
 	}
-	if s[offset] == '[' {
+	open, closing := c.contextBrackets()
+	if l, size := utf8.DecodeRuneInString(s[offset:]); l == open && !c.DisallowContext {
 		start := offset
-		offset++
+		offset += size
 		// TIK has context.
-		contextEnd := strings.IndexByte(s[offset:], ']')
+		search := s[offset:]
+		if c.MaxContextLen > 0 && len(search) > c.MaxContextLen+utf8.UTFMax {
+			search = search[:c.MaxContextLen+utf8.UTFMax]
+		}
+		contextEnd := indexUnescapedRune(search, closing)
+		if c.MaxContextLen > 0 && (contextEnd > c.MaxContextLen ||
+			contextEnd == -1 && len(search) < len(s)-offset) {
+			return nil, err(start, ErrContextTooLong)
+		}
 		if contextEnd == -1 {
-			return buffer, err(start, ErrContextUnclosed)
+			return nil, err(start, ErrContextUnclosed)
 		}
 
 		context := s[offset : offset+contextEnd]
 		if strings.TrimSpace(context) == "" {
-			return buffer, err(start, ErrContextEmpty)
+			return nil, err(start, ErrContextEmpty)
+		}
+		if i := indexInvalidContext(context, open, closing); i != -1 {
+			return nil, err(offset+i, ErrContextInvalid)
 		}
-		if strings.ContainsAny(context, "{}[]\\") {
-			// Contains either of: { } [ ] \
-			return buffer, err(start, ErrContextInvalid)
+		if c.StrictContextSegments &&
+			slices.Contains(contextSegments(context), "") {
+			return nil, err(start, ErrContextSegmentEmpty)
 		}
-		offset += contextEnd + 1
+		offset += contextEnd + utf8.RuneLen(closing)
 		buffer = append(buffer, Token{
 			IndexStart: start,
 			IndexEnd:   offset,
@@ -208,10 +952,13 @@ func (t *Tokenizer) Tokenize(buffer Tokens, s string, c Config) (Tokens, ParseEr
 		}
 
 		if offset >= len(s) {
-			return buffer, err(offset, ErrTextEmpty)
+			if c.AllowEmptyText {
+				return buffer, ParseError{}
+			}
+			return nil, err(offset, ErrTextEmpty)
 		}
 		if offset == contextEndOffset {
-			return buffer, err(offset, ErrContextNoSeparator)
+			return nil, err(offset, ErrContextNoSeparator)
 		}
 	}
 
@@ -222,13 +969,18 @@ func (t *Tokenizer) Tokenize(buffer Tokens, s string, c Config) (Tokens, ParseEr
 			// Fast path for simple inputs without {}.
 			indexEnd := len(s)
 			// Ignore suffix spaces.
-			for indexEnd >= 0 {
+			for !c.PreserveEdgeWhitespace && indexEnd >= 0 {
 				l, size := utf8.DecodeLastRuneInString(s[offset:indexEnd])
 				if !unicode.IsSpace(l) {
 					break
 				}
 				indexEnd -= size
 			}
+			if c.StrictEscapes {
+				if i := invalidEscape(s[offset:indexEnd]); i != -1 {
+					return nil, err(offset+i, ErrInvalidEscape)
+				}
+			}
 			return append(buffer, Token{
 				IndexStart: offset,
 				IndexEnd:   indexEnd,
@@ -241,20 +993,29 @@ func (t *Tokenizer) Tokenize(buffer Tokens, s string, c Config) (Tokens, ParseEr
 		var iDir int
 		for literalOffset := offset; ; {
 			// Read string literal before the next directive.
-			iDir = strings.IndexAny(s[offset:], "{}")
+			if pluralArms {
+				iDir = strings.IndexAny(s[offset:], "{}|")
+			} else {
+				iDir = strings.IndexAny(s[offset:], "{}")
+			}
 			if iDir == -1 {
 				// There is no next directive.
 				if literalOffset != len(s) {
 					// End of string literal.
 					indexEnd := len(s)
 					// Ignore suffix spaces.
-					for indexEnd >= 0 {
+					for !c.PreserveEdgeWhitespace && indexEnd >= 0 {
 						l, size := utf8.DecodeLastRuneInString(s[:indexEnd])
 						if !unicode.IsSpace(l) {
 							break
 						}
 						indexEnd -= size
 					}
+					if c.StrictEscapes {
+						if i := invalidEscape(s[literalOffset:indexEnd]); i != -1 {
+							return nil, err(literalOffset+i, ErrInvalidEscape)
+						}
+					}
 					buffer = append(buffer, Token{
 						IndexStart: literalOffset,
 						IndexEnd:   indexEnd,
@@ -262,20 +1023,99 @@ func (t *Tokenizer) Tokenize(buffer Tokens, s string, c Config) (Tokens, ParseEr
 					})
 				}
 				// End of TIK.
+				if pluralDepth > 0 {
+					return nil, err(pluralStart, ErrUnclosedPlaceholder)
+				}
+				if len(tags) > 0 {
+					return nil, unbalanced()
+				}
 				return buffer, ParseError{}
 			}
 
 			iDir += offset
-			if s[iDir] == '}' {
-				// A dangling } must be escaped if it was meant to just be a literal '}'.
-				if !inPluralDirective {
-					if isEscaped(s, iDir-1) {
-						// Escaped, continue reading literal.
-						offset = iDir + 1
+			if s[iDir] == '}' && isEscaped(s, iDir-1) {
+				// Escaped, continue reading literal.
+				offset = iDir + 1
+				continue
+			}
+			if s[iDir] == '|' {
+				// Pluralization arm delimiter.
+				if pluralOther {
+					// The other arm must be the last one.
+					return nil, err(iDir, ErrPluralArmInvalid)
+				}
+				if len(tags) > pluralTags {
+					return nil, unbalanced()
+				}
+				// Whitespace preceding the delimiter separates the arms.
+				indexEnd := skipSpaceBackward(s, literalOffset, iDir)
+				if literalOffset != indexEnd {
+					if c.StrictEscapes {
+						if i := invalidEscape(s[literalOffset:indexEnd]); i != -1 {
+							return nil, err(literalOffset+i, ErrInvalidEscape)
+						}
+					}
+					buffer = append(buffer, Token{
+						IndexStart: literalOffset,
+						IndexEnd:   indexEnd,
+						Type:       TokenTypeLiteral,
+					})
+				}
+				if !strings.HasPrefix(s[iDir:], "|=") {
+					buffer = append(buffer, Token{
+						IndexStart: iDir,
+						IndexEnd:   iDir + 1,
+						Type:       TokenTypePluralOther,
+					})
+					pluralOther = true
+					offset = iDir + 1
+					literalOffset = offset
+					continue
+				}
+				value, ok := parsePluralArmNumber(s, iDir+len("|="))
+				if !ok {
+					return nil, err(iDir, ErrPluralArmInvalid)
+				}
+				for i, nested := len(buffer)-1, 0; ; i-- {
+					switch buffer[i].Type {
+					case TokenTypeCardinalPluralEnd:
+						nested++ // Skip arms of nested pluralizations.
 						continue
+					case TokenTypeCardinalPluralStart:
+						nested--
+					case TokenTypePluralExactMatch:
+						// Values are canonical, equal numbers are equal strings.
+						if nested == 0 && buffer[i].Value == value {
+							// Duplicate exact match arm.
+							return nil, err(iDir, ErrPluralArmInvalid)
+						}
+					}
+					if nested < 0 {
+						break
 					}
+				}
+				indexEnd = iDir + len("|=") + len(value)
+				buffer = append(buffer, Token{
+					IndexStart: iDir,
+					IndexEnd:   indexEnd,
+					Type:       TokenTypePluralExactMatch,
+					Value:      value,
+				})
+				offset = skipSpace(s, indexEnd)
+				literalOffset = offset
+				continue
+			}
+			if s[iDir] == '}' {
+				// A dangling } must be escaped if it was meant to just be a literal '}'.
+				if pluralDepth == 0 {
 					return nil, err(iDir, ErrUnexpClosure)
 				}
+				if pluralArms && !pluralOther {
+					return nil, err(iDir, ErrPluralOtherArmMissing)
+				}
+				if len(tags) > pluralTags {
+					return nil, unbalanced()
+				}
 				if literalOffset != iDir {
 					content := s[literalOffset:iDir]
 					if strings.TrimSpace(content) == "" {
@@ -286,6 +1126,11 @@ func (t *Tokenizer) Tokenize(buffer Tokens, s string, c Config) (Tokens, ParseEr
 					if l, _ := utf8.DecodeLastRuneInString(content); unicode.IsSpace(l) {
 						return nil, err(iDir-1, ErrCardinalPluralTrailingSpace)
 					}
+					if c.StrictEscapes {
+						if i := invalidEscape(content); i != -1 {
+							return nil, err(literalOffset+i, ErrInvalidEscape)
+						}
+					}
 					// End of string literal.
 					buffer = append(buffer, Token{
 						IndexStart: literalOffset,
@@ -298,7 +1143,16 @@ func (t *Tokenizer) Tokenize(buffer Tokens, s string, c Config) (Tokens, ParseEr
 					IndexEnd:   iDir + 1,
 					Type:       TokenTypeCardinalPluralEnd,
 				})
-				inPluralDirective = false
+				pluralDepth--
+				pluralArms, pluralOther = false, false
+				pluralTags = 0
+				if n := len(outerPlurals); n > 0 {
+					// Continue the enclosing pluralization.
+					p := outerPlurals[n-1]
+					pluralArms, pluralOther, pluralTags = p.arms, p.other, p.tags
+					pluralStart = p.start
+					outerPlurals = outerPlurals[:n-1]
+				}
 
 				// Restart literal parsing cycle.
 				offset = iDir + 1
@@ -315,6 +1169,11 @@ func (t *Tokenizer) Tokenize(buffer Tokens, s string, c Config) (Tokens, ParseEr
 			}
 
 			if literalOffset != iDir {
+				if c.StrictEscapes {
+					if i := invalidEscape(s[literalOffset:iDir]); i != -1 {
+						return nil, err(literalOffset+i, ErrInvalidEscape)
+					}
+				}
 				buffer = append(buffer, Token{
 					IndexStart: literalOffset,
 					IndexEnd:   iDir,
@@ -324,20 +1183,64 @@ func (t *Tokenizer) Tokenize(buffer Tokens, s string, c Config) (Tokens, ParseEr
 			break
 		}
 
-		iDirClose := strings.IndexByte(s[iDir+1:], '}')
-		if iDirClose == -1 {
+		var iDirClose int
+		if strings.HasPrefix(s[iDir+1:], "icu:") {
+			// Raw ICU may contain balanced curly braces.
+			if iDirClose = indexRawICUEnd(s[iDir+1:]); iDirClose == -1 {
+				return nil, err(iDir, ErrRawICUInvalid)
+			}
+		} else if iDirClose = indexDirectiveEnd(s[iDir+1:]); iDirClose == -1 {
 			return nil, err(iDir, ErrUnclosedPlaceholder)
 		}
 		iDirClose += iDir
 
 		directive := s[iDir+1 : iDirClose+1]
-		tp, ln := match(directive)
+		var hint string
+		if directive != "" && directive[0] != '@' &&
+			!strings.HasPrefix(directive, pluralSign) &&
+			!strings.HasPrefix(directive, "icu:") {
+			if name, h, ok := cutHint(directive); ok {
+				// Placeholder with translator hint.
+				trimmed := strings.TrimRightFunc(name, unicode.IsSpace)
+				if trimmed == name || !isValidHint(h) {
+					return nil, err(iDir, ErrHintInvalid)
+				}
+				directive = trimmed
+				hint = replacerTokenStringify.Replace(strings.TrimSpace(h))
+			}
+		}
+		directive = t.keywords.fold(directive)
+		tp, ln := t.keywords.match(directive)
+		if tp == 0 && c.CustomResolver != nil {
+			if custom, ok := c.CustomResolver(directive); ok {
+				if !custom.IsCustom() && (custom.keyword() == "" ||
+					custom == TokenTypeCardinalPluralStart) {
+					return nil, err(iDir, ErrUnknownPlaceholder)
+				}
+				tp, ln = custom, len(directive)
+			}
+		}
+		var name string
 		switch tp {
 		case TokenTypeCardinalPluralStart:
-			if inPluralDirective {
-				return nil, err(iDir, ErrNestedPluralization)
+			if tooManyPlaceholders() {
+				return nil, err(iDir, ErrTooManyPlaceholders)
+			}
+			if pluralDepth > 0 {
+				if !c.AllowNestedPlural {
+					return nil, err(iDir, ErrNestedPluralization)
+				}
+				if startsPlural() {
+					return nil, err(iDir, ErrDirectiveStartsCardinalPlural)
+				}
+				outerPlurals = append(outerPlurals, pluralState{
+					arms: pluralArms, other: pluralOther,
+					tags: pluralTags, start: pluralStart,
+				})
+				pluralArms, pluralOther = false, false
 			}
-			inPluralDirective = true
+			pluralDepth++
+			pluralTags, pluralStart = len(tags), iDir
 			// +1 for the '{'.
 			buffer = append(buffer, Token{
 				IndexStart: iDir,
@@ -345,75 +1248,741 @@ func (t *Tokenizer) Tokenize(buffer Tokens, s string, c Config) (Tokens, ParseEr
 				Type:       TokenTypeCardinalPluralStart,
 			})
 			offset = iDir + ln + 1 // Skip only the plural block start.
+
+			// Check for exact match arms optionally preceded by an offset.
+			j := skipSpace(s, offset)
+			if v, ok := strings.CutPrefix(s[j:], "offset:"); ok {
+				value, ok := parsePluralArmNumber(v, 0)
+				if k := skipSpace(s, j+len("offset:")+len(value)); value != "" &&
+					strings.HasPrefix(s[k:], "|=") {
+					if !ok {
+						return nil, err(j, ErrPluralArmInvalid)
+					}
+					buffer = append(buffer, Token{
+						IndexStart: j,
+						IndexEnd:   j + len("offset:") + len(value),
+						Type:       TokenTypePluralOffset,
+						Value:      value,
+					})
+					j = k
+				}
+			}
+			if strings.HasPrefix(s[j:], "|=") {
+				pluralArms = true
+				offset = j
+			}
 			continue
 		case 0:
-			return nil, err(iDir, ErrUnknownPlaceholder)
-		}
-
-		if b := buffer; len(b) > 0 && inPluralDirective {
-			last := b[len(b)-1]
-			startsPlural := last.Type == TokenTypeCardinalPluralStart
-			if !startsPlural && last.Type == TokenTypeLiteral && len(b) > 1 &&
-				b[len(b)-2].Type == TokenTypeCardinalPluralStart &&
-				strings.TrimSpace(s[last.IndexStart:last.IndexEnd]) == "" {
-				// A whitespace-only literal between plural start and directive
-				// still counts as "starts with a directive".
-				startsPlural = true
+			keyword, n, ok := strings.Cut(directive, ":")
+			if ok && c.NamedPlaceholders {
+				tp, _ = t.keywords.match(keyword)
+			}
+			if !tp.isNameable() {
+				if !c.UnknownPlaceholderAsLiteral {
+					return nil, err(iDir, ErrUnknownPlaceholder)
+				}
+				buffer = append(buffer, Token{
+					IndexStart: iDir,
+					IndexEnd:   iDirClose + 2,
+					Type:       TokenTypeLiteral,
+				})
+				offset = iDirClose + 2
+				continue
+			}
+			if !isICUIdentifier(n) {
+				return nil, err(iDir, ErrPlaceholderNameInvalid)
+			}
+			name = n
+		case TokenTypeMessageRef:
+			if !isValidMessageRefKey(directive[ln:]) {
+				return nil, err(iDir, ErrMessageRefInvalid)
+			}
+		case TokenTypeBoolean:
+			if !isValidBooleanWords(directive[ln:]) {
+				return nil, err(iDir, ErrBooleanInvalid)
+			}
+		case TokenTypeSelect:
+			if !isValidSelect(directive[ln:]) ||
+				c.isArgName(directive[ln:strings.IndexByte(directive, '(')]) ||
+				conflictsSelect(buffer, directive[ln:]) {
+				return nil, err(iDir, ErrSelectInvalid)
+			}
+		case TokenTypeStringPlaceholder:
+			if !isValidStringSample(directive[ln:]) {
+				return nil, err(iDir, ErrStringPlaceholderInvalid)
+			}
+		case TokenTypeCurrency:
+			if ln > len("currency") && !isCurrencyCode(directive[ln:]) {
+				return nil, err(iDir, ErrCurrencyCodeInvalid)
+			}
+		case TokenTypeOrdinalPlural:
+			if ln > len("ordinal") && !isValidOrdinalSuffix(directive[ln:]) {
+				return nil, err(iDir, ErrOrdinalSuffixInvalid)
+			}
+		case TokenTypeUnit:
+			if !isUnitIdentifier(directive[ln:]) {
+				return nil, err(iDir, ErrUnitInvalid)
+			}
+		case TokenTypeNumberSkeleton:
+			if !isValidNumberSkeleton(directive[ln:]) {
+				return nil, err(iDir, ErrNumberSkeletonInvalid)
+			}
+		case TokenTypeRawICU:
+			if strings.TrimSpace(directive[ln:]) == "" {
+				return nil, err(iDir, ErrRawICUInvalid)
+			}
+			if hasRawICUArgRef(directive[ln:]) {
+				tp = TokenTypeRawICUArgument
+			}
+		case TokenTypeTagOpen, TokenTypeTagClose, TokenTypeTagSelfClosing:
+			name, ok := strings.CutSuffix(directive[ln:], ">")
+			if tp == TokenTypeTagSelfClosing {
+				name = strings.TrimSuffix(name, "/")
+			}
+			if !ok || !slices.Contains(c.MarkupTags, name) {
+				return nil, err(iDir, ErrTagInvalid)
 			}
-			if startsPlural {
-				// Cardinal pluralization block must not begin with another directive.
-				return nil, err(iDir, ErrDirectiveStartsCardinalPlural)
+			switch tp {
+			case TokenTypeTagOpen:
+				tags = append(tags, len(buffer))
+			case TokenTypeTagClose:
+				if len(tags) == pluralTags ||
+					buffer[tags[len(tags)-1]].Value != name {
+					return nil, err(iDir, ErrTagUnbalanced)
+				}
+				tags = tags[:len(tags)-1]
 			}
 		}
-		buffer = append(buffer, Token{
+
+		if tp.IsPlaceholder() && tooManyPlaceholders() {
+			return nil, err(iDir, ErrTooManyPlaceholders)
+		}
+		if startsPlural() {
+			// Cardinal pluralization block must not begin with another directive.
+			return nil, err(iDir, ErrDirectiveStartsCardinalPlural)
+		}
+		tok := Token{
 			IndexStart: iDir,
 			IndexEnd:   iDirClose + 2,
 			Type:       tp,
-		})
+			Value:      name,
+			Hint:       hint,
+		}
+		switch tp {
+		case TokenTypeMessageRef, TokenTypeBoolean, TokenTypeSelect,
+			TokenTypeRawICU, TokenTypeRawICUArgument, TokenTypeOrdinalPlural,
+			TokenTypeUnit, TokenTypeNumberSkeleton:
+			tok.Value = directive[ln:]
+		case TokenTypeStringPlaceholder:
+			tok.Value = replacerStringSample.Replace(directive[ln : len(directive)-1])
+		case TokenTypeCurrency:
+			tok.Value = strings.ToUpper(directive[ln:])
+		case TokenTypeTagOpen, TokenTypeTagClose, TokenTypeTagSelfClosing:
+			tok.Value = strings.TrimSuffix(strings.TrimSuffix(directive[ln:], ">"), "/")
+		}
+		if tp.IsCustom() {
+			tok.Value = directive
+		}
+		buffer = append(buffer, tok)
 		offset = iDirClose + 2
 	}
 }
 
-func match(s string) (tokenType TokenType, length int) {
-	switch s {
-	case "text":
-		return TokenTypeText, len("text")
-	case "name":
-		return TokenTypeTextWithGender, len("name")
-	case "integer":
-		return TokenTypeInteger, len("integer")
-	case "number":
-		return TokenTypeNumber, len("number")
-	case "ordinal":
-		return TokenTypeOrdinalPlural, len("ordinal")
-	case "time-full":
-		return TokenTypeTimeFull, len("time-full")
-	case "time-long":
-		return TokenTypeTimeLong, len("time-long")
-	case "time-medium":
-		return TokenTypeTimeMedium, len("time-medium")
-	case "time-short":
-		return TokenTypeTimeShort, len("time-short")
-	case "date-full":
-		return TokenTypeDateFull, len("date-full")
-	case "date-long":
-		return TokenTypeDateLong, len("date-long")
-	case "date-medium":
-		return TokenTypeDateMedium, len("date-medium")
-	case "date-short":
-		return TokenTypeDateShort, len("date-short")
-	case "currency":
-		return TokenTypeCurrency, len("currency")
-	}
-	if strings.HasPrefix(s, "#") {
-		return TokenTypeCardinalPluralStart, len("#")
-	}
-	return 0, 0
+// keywordEntry is a placeholder keyword of placeholderKeywords.
+type keywordEntry struct {
+	keyword   string
+	tokenType TokenType
+
+	// prefix is true for keywords followed by a value such as `unit:`,
+	// otherwise the keyword must match the entire directive.
+	prefix bool
 }
 
-// isEscaped expects i to point to index -1 relative to the subject byte.
-func isEscaped(s string, i int) bool {
-	pRevSol := 0
+// placeholderKeywords are the placeholder keywords in match order.
+// Keywords matching the entire directive precede prefixes
+// and `</` precedes `<`.
+var placeholderKeywords = [...]keywordEntry{
+	{"text", TokenTypeText, false},
+	{"name", TokenTypeTextWithGender, false},
+	{"integer", TokenTypeInteger, false},
+	{"number", TokenTypeNumber, false},
+	{"ordinal", TokenTypeOrdinalPlural, false},
+	{"time-full", TokenTypeTimeFull, false},
+	{"time-long", TokenTypeTimeLong, false},
+	{"time-medium", TokenTypeTimeMedium, false},
+	{"time-short", TokenTypeTimeShort, false},
+	{"date-full", TokenTypeDateFull, false},
+	{"date-long", TokenTypeDateLong, false},
+	{"date-medium", TokenTypeDateMedium, false},
+	{"date-short", TokenTypeDateShort, false},
+	{"date-time", TokenTypeDateTime, false},
+	{"time-zone", TokenTypeTimeZone, false},
+	{"relative-time", TokenTypeRelativeTime, false},
+	{"spellout", TokenTypeNumberSpellout, false},
+	{"week-of-year", TokenTypeWeekOfYear, false},
+	{"quarter", TokenTypeQuarter, false},
+	{"number-range", TokenTypeNumberRange, false},
+	{"phone", TokenTypePhone, false},
+	{"email", TokenTypeEmail, false},
+	{"url", TokenTypeURL, false},
+	{"permille", TokenTypePermille, false},
+	{"skip", TokenTypeSkip, false},
+	{"currency", TokenTypeCurrency, false},
+	{"@", TokenTypeMessageRef, true},
+	{"bool:", TokenTypeBoolean, true},
+	{"select:", TokenTypeSelect, true},
+	{"icu:", TokenTypeRawICU, true},
+	{"currency:", TokenTypeCurrency, true},
+	{"ordinal:", TokenTypeOrdinalPlural, true},
+	{"unit:", TokenTypeUnit, true},
+	{"number::", TokenTypeNumberSkeleton, true},
+	{"</", TokenTypeTagClose, true},
+	{"<", TokenTypeTagOpen, true},
+	{`"`, TokenTypeStringPlaceholder, true},
+}
+
+// keyword returns the placeholder keyword of t such as `date-short`
+// or an empty string if placeholders of type t can't be written
+// by keyword alone.
+func (t TokenType) keyword() string {
+	for _, e := range placeholderKeywords {
+		if !e.prefix && e.tokenType == t {
+			return e.keyword
+		}
+	}
+	return ""
+}
+
+// KeywordFor returns the keyword of placeholders of type t such as
+// `date-medium` for TokenTypeDateMedium and the configured
+// c.CardinalPluralNumberSign for TokenTypeCardinalPluralStart.
+// ok is false if t isn't a placeholder or if placeholders of type t
+// can't be written without a value, such as `{unit:kilometer}`.
+func (c Config) KeywordFor(t TokenType) (keyword string, ok bool) {
+	if t == TokenTypeCardinalPluralStart {
+		return c.pluralSign(), true
+	}
+	keyword = t.keyword()
+	return keyword, keyword != ""
+}
+
+// keywordHash returns the index of keyword s in keywordTable.exact.
+// The factors are chosen so that no two keywords collide,
+// which newKeywordTable checks.
+func keywordHash(s string) uint {
+	return (2*uint(s[0]) + 9*uint(s[len(s)-1]) + 2*uint(len(s))) % 64
+}
+
+// keywordTable indexes placeholderKeywords, which makes matching
+// a directive independent of the number of keywords.
+type keywordTable struct {
+	// exact are the keywords matching the entire directive by keywordHash.
+	exact [64]keywordEntry
+
+	// byFirst are all keywords by their first byte in match order.
+	byFirst [256][]keywordEntry
+}
+
+// keywords is the keyword table shared by all keyword lookups.
+var keywords = newKeywordTable()
+
+func newKeywordTable() *keywordTable {
+	t := new(keywordTable)
+	for _, e := range placeholderKeywords {
+		if !e.prefix {
+			h := keywordHash(e.keyword)
+			if t.exact[h].keyword != "" {
+				panic("keywordHash collision: " + e.keyword + " and " + t.exact[h].keyword)
+			}
+			t.exact[h] = e
+		}
+		t.byFirst[e.keyword[0]] = append(t.byFirst[e.keyword[0]], e)
+	}
+	return t
+}
+
+// keywordLookup matches directives against the placeholder keywords
+// and the cardinal plural sign of a Config.
+// NewParser builds it once per Config.
+type keywordLookup struct {
+	table      *keywordTable
+	pluralSign string
+
+	// caseInsensitive is true if keywords match case-insensitively.
+	caseInsensitive bool
+}
+
+func newKeywordLookup(c Config) keywordLookup {
+	return keywordLookup{
+		table:           keywords,
+		pluralSign:      c.pluralSign(),
+		caseInsensitive: c.CaseInsensitiveKeywords,
+	}
+}
+
+// matches returns true if l was built for c.
+func (l *keywordLookup) matches(c Config) bool {
+	return l.table != nil && l.caseInsensitive == c.CaseInsensitiveKeywords &&
+		l.pluralSign == c.pluralSign()
+}
+
+// toLowerASCII returns the lower-case of ASCII letter b or b itself.
+func toLowerASCII(b byte) byte {
+	if b >= 'A' && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+// foldKeyword returns directive with its leading placeholder keyword
+// in lower case if the keyword matches case-insensitively.
+func foldKeyword(directive string) string {
+	if directive == "" {
+		return directive
+	}
+	for _, e := range keywords.byFirst[toLowerASCII(directive[0])] {
+		k := e.keyword
+		if len(directive) < len(k) || !strings.EqualFold(directive[:len(k)], k) {
+			continue
+		}
+		if len(directive) == len(k) || e.prefix || directive[len(k)] == ':' {
+			if directive[:len(k)] == k {
+				return directive // Already in lower case.
+			}
+			return k + directive[len(k):]
+		}
+	}
+	return directive
+}
+
+// fold returns directive with its keyword folded by foldKeyword
+// if keywords match case-insensitively.
+func (l *keywordLookup) fold(directive string) string {
+	if !l.caseInsensitive {
+		return directive
+	}
+	return foldKeyword(directive)
+}
+
+// match matches directive s against the known placeholders
+// and cardinal pluralizations started with the plural sign.
+// Keywords matching the entire directive are looked up by keywordHash,
+// prefixes by the first byte of s.
+func (l *keywordLookup) match(s string) (tokenType TokenType, length int) {
+	if s == "" {
+		return 0, 0
+	}
+	if e := &l.table.exact[keywordHash(s)]; s == e.keyword {
+		return e.tokenType, len(s)
+	}
+	if strings.HasPrefix(s, l.pluralSign) {
+		return TokenTypeCardinalPluralStart, len(l.pluralSign)
+	}
+	for _, e := range l.table.byFirst[s[0]] {
+		if !e.prefix || !strings.HasPrefix(s, e.keyword) {
+			continue
+		}
+		if e.tokenType == TokenTypeTagOpen && strings.HasSuffix(s, "/>") {
+			return TokenTypeTagSelfClosing, len("<")
+		}
+		return e.tokenType, len(e.keyword)
+	}
+	return 0, 0
+}
+
+// indexRawICUEnd returns the index of the `}` closing the raw ICU directive
+// s starting after its `{`, or -1 if the curly braces aren't balanced.
+// Curly braces quoted according to the ICU apostrophe rules are skipped.
+func indexRawICUEnd(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			if i+1 < len(s) && strings.IndexByte("{}#|", s[i+1]) != -1 {
+				// Quoted text up to the next single apostrophe.
+				for i += 2; i < len(s); i++ {
+					if s[i] != '\'' {
+						continue
+					}
+					if i+1 < len(s) && s[i+1] == '\'' {
+						i++ // Doubled apostrophe in quoted text.
+						continue
+					}
+					break
+				}
+			}
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// hasRawICUArgRef returns true if raw ICU s references its argument.
+func hasRawICUArgRef(s string) bool {
+	for {
+		i := strings.Index(s, "{var")
+		if i == -1 {
+			return false
+		}
+		s = s[i+len("{var"):]
+		if s != "" && (s[0] == ',' || s[0] == '}') {
+			return true
+		}
+	}
+}
+
+// indexUnescapedRune returns the index of the first occurrence of r in s
+// that isn't escaped by a reverse solidus, or -1 if there's none.
+func indexUnescapedRune(s string, r rune) int {
+	escaped := false
+	for i, c := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == r:
+			return i
+		}
+	}
+	return -1
+}
+
+// indexInvalidContext returns the index of the first unescaped `{`, `}`
+// or opening bracket in context, or of the first reverse solidus that
+// doesn't escape `{`, `}`, `\` or either bracket.
+// Returns -1 if context is valid.
+func indexInvalidContext(context string, open, closing rune) int {
+	escape := -1 // Index of the pending reverse solidus.
+	for i, c := range context {
+		if escape != -1 {
+			switch c {
+			case '{', '}', '\\', open, closing:
+			default:
+				return escape
+			}
+			escape = -1
+			continue
+		}
+		switch c {
+		case '\\':
+			escape = i
+		case '{', '}', open:
+			return i
+		}
+	}
+	return escape
+}
+
+// isValidBooleanWords returns true if words consists of two non-empty
+// surface words separated by `/` that contain neither `{` nor `\`.
+func isValidBooleanWords(words string) bool {
+	on, off, ok := strings.Cut(words, "/")
+	return ok && on != "" && off != "" &&
+		!strings.ContainsAny(on, "{\\") && !strings.ContainsAny(off, "{\\/")
+}
+
+// isTagName returns true for `[a-zA-Z][a-zA-Z0-9-]*`.
+func isTagName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isUnitIdentifier returns true for CLDR unit identifiers such as
+// `kilometer` or `kilometer-per-hour` matching `[a-z][a-z0-9]*(-[a-z0-9]+)*`.
+func isUnitIdentifier(s string) bool {
+	if s == "" || s[0] < 'a' || s[0] > 'z' || s[len(s)-1] == '-' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == '-' && s[i-1] != '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isValidNumberSkeleton returns true if s is a non-blank ICU number
+// skeleton such as `.00` or `group-off .0#` without surrounding whitespace
+// and without `{`, `}` and `\`, which would leave the curly braces
+// of the ICU message unbalanced.
+func isValidNumberSkeleton(s string) bool {
+	return s != "" && strings.TrimSpace(s) == s && !strings.ContainsAny(s, "{}\\")
+}
+
+// isCurrencyCode returns true if code consists of three ASCII letters.
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if c := code[i] | 0x20; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidStringSample returns true if sample is a non-empty value
+// followed by the closing `"` that contains `"`, `{`, `}` and `\`
+// only if escaped by a reverse solidus.
+func isValidStringSample(sample string) bool {
+	v, ok := strings.CutSuffix(sample, `"`)
+	if !ok || v == "" {
+		return false
+	}
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case '\\':
+			if i++; i == len(v) || !strings.ContainsRune(`"{}\`, rune(v[i])) {
+				return false
+			}
+		case '"', '{', '}':
+			return false
+		}
+	}
+	return true
+}
+
+// selectOptions splits the value of a select placeholder such as
+// `status(pending,shipped)` into the argument name and the options.
+func selectOptions(value string) (name string, options []string, ok bool) {
+	name, list, ok := strings.Cut(value, "(")
+	if !ok || !strings.HasSuffix(list, ")") {
+		return "", nil, false
+	}
+	return name, strings.Split(list[:len(list)-1], ","), true
+}
+
+// isValidOrdinalSuffix returns true if s is a non-empty valid UTF-8 string
+// free of whitespace and characters that are special in ICU
+// or TIK directives.
+func isValidOrdinalSuffix(s string) bool {
+	return s != "" && utf8.ValidString(s) &&
+		!strings.ContainsFunc(s, unicode.IsSpace) &&
+		!strings.ContainsAny(s, "{}#'|\\")
+}
+
+// isValidSelect returns true if value is an ICU identifier followed by
+// a parenthesized list of unique comma-separated ICU identifiers
+// excluding the implicit `other`.
+func isValidSelect(value string) bool {
+	name, options, ok := selectOptions(value)
+	if !ok || !isICUIdentifier(name) {
+		return false
+	}
+	for i, o := range options {
+		if !isICUIdentifier(o) || o == "other" ||
+			slices.Contains(options[:i], o) {
+			return false
+		}
+	}
+	return true
+}
+
+// conflictsSelect returns true if the name of select value is already
+// used by a select in tokens with different options.
+func conflictsSelect(tokens Tokens, value string) bool {
+	name, options, _ := selectOptions(value)
+	for _, t := range tokens {
+		if t.Type != TokenTypeSelect {
+			continue
+		}
+		n, o, _ := selectOptions(t.Value)
+		if n == name && (len(o) != len(options) ||
+			slices.ContainsFunc(o, func(o string) bool { return !slices.Contains(options, o) })) {
+			return true
+		}
+	}
+	return false
+}
+
+// isICUIdentifier returns true if s is a non-empty sequence of letters,
+// digits and '_' not starting with a digit.
+func isICUIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// cutHint slices directive around the `#` separating the translator hint
+// and returns the untrimmed name and hint. found is false if there's no hint.
+// The sample of a string placeholder may contain `#`.
+// A number skeleton may contain `#` too, such as `.0#`,
+// hence its hint separator must be preceded by whitespace.
+func cutHint(directive string) (name, hint string, found bool) {
+	from := 0
+	if directive != "" && directive[0] == '"' {
+		from = indexUnescapedRune(directive[1:], '"') + 2
+	}
+	i := strings.IndexByte(directive[from:], '#')
+	if len(directive) > len("number::") &&
+		strings.EqualFold(directive[:len("number::")], "number::") {
+		for i != -1 {
+			if r, _ := utf8.DecodeLastRuneInString(directive[:from+i]); unicode.IsSpace(r) {
+				break
+			}
+			j := strings.IndexByte(directive[from+i+1:], '#')
+			if j == -1 {
+				i = -1
+				break
+			}
+			i += j + 1
+		}
+	}
+	if i == -1 || strings.TrimSpace(directive[:from+i]) == "" {
+		return directive, "", false
+	}
+	return directive[:from+i], directive[from+i+1:], true
+}
+
+// isValidHint returns true if hint isn't blank
+// and contains no unescaped `{` and `}`.
+func isValidHint(hint string) bool {
+	if strings.TrimSpace(hint) == "" {
+		return false
+	}
+	for i := 0; i < len(hint); i++ {
+		if (hint[i] == '{' || hint[i] == '}') && !isEscaped(hint, i-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidMessageRefKey returns true if key consists of one or more
+// dot-separated non-empty segments of ASCII letters, digits, '_' and '-'.
+func isValidMessageRefKey(key string) bool {
+	segmentLen := 0
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; {
+		case c == '.':
+			if segmentLen == 0 {
+				return false
+			}
+			segmentLen = 0
+			continue
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '_', c == '-':
+			segmentLen++
+			continue
+		}
+		return false
+	}
+	return segmentLen > 0
+}
+
+// parsePluralArmNumber returns the decimal number starting at s[i]
+// which must be followed by whitespace. ok is false if the number
+// has leading zeros or exceeds math.MaxInt32, in which case value
+// is the sequence of digits, so equal values are equal strings.
+func parsePluralArmNumber(s string, i int) (value string, ok bool) {
+	j := i
+	for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+		j++
+	}
+	value = s[i:j]
+	if value == "" || j >= len(s) || len(value) > 1 && value[0] == '0' {
+		return value, false
+	}
+	if _, err := strconv.ParseInt(value, 10, 32); err != nil {
+		return value, false // Out of range.
+	}
+	if l, _ := utf8.DecodeRuneInString(s[j:]); !unicode.IsSpace(l) {
+		return value, false
+	}
+	return value, true
+}
+
+// skipSpace returns the index of the first non-whitespace character
+// in s starting at i, or len(s).
+func skipSpace(s string, i int) int {
+	for i < len(s) {
+		l, size := utf8.DecodeRuneInString(s[i:])
+		if !unicode.IsSpace(l) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// skipSpaceBackward returns the end index of s[start:end]
+// with trailing whitespace excluded.
+func skipSpaceBackward(s string, start, end int) int {
+	for end > start {
+		l, size := utf8.DecodeLastRuneInString(s[start:end])
+		if !unicode.IsSpace(l) {
+			break
+		}
+		end -= size
+	}
+	return end
+}
+
+// invalidEscape returns the index of the first invalid escape sequence
+// in literal s, or -1 if all escape sequences are well-formed.
+func invalidEscape(s string) int {
+	for i := 0; ; i += 2 {
+		j := strings.IndexByte(s[i:], '\\')
+		if j == -1 {
+			return -1
+		}
+		i += j
+		if i+1 >= len(s) {
+			return i // Dangling reverse solidus.
+		}
+		switch s[i+1] {
+		case '\\', '{', '}':
+			continue
+		}
+		return i
+	}
+}
+
+// indexDirectiveEnd returns the index of the first '}' in s
+// that isn't escaped by a reverse-solidus or -1 if there's none.
+func indexDirectiveEnd(s string) int {
+	for i := 0; ; {
+		j := strings.IndexByte(s[i:], '}')
+		if j == -1 {
+			return -1
+		}
+		if i += j; !isEscaped(s, i-1) {
+			return i
+		}
+		i++
+	}
+}
+
+// isEscaped expects i to point to index -1 relative to the subject byte.
+func isEscaped(s string, i int) bool {
+	pRevSol := 0
 	for ; i >= 0; i, pRevSol = i-1, pRevSol+1 {
 		if s[i] != '\\' {
 			break
@@ -428,13 +1997,321 @@ type TIK struct {
 	Tokens Tokens
 }
 
+// Context returns the unescaped context of the TIK without the brackets,
+// or an empty string if the TIK has no context.
+func (t TIK) Context() string {
+	if len(t.Tokens) == 0 || t.Tokens[0].Type != TokenTypeContext {
+		return ""
+	}
+	s := t.Raw[t.Tokens[0].IndexStart:t.Tokens[0].IndexEnd]
+	_, openSize := utf8.DecodeRuneInString(s)
+	_, closeSize := utf8.DecodeLastRuneInString(s)
+	s = s[openSize : len(s)-closeSize]
+	if strings.IndexByte(s, '\\') == -1 {
+		// Fast path, no reverse solidus
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++ // Skip the escaping reverse solidus.
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// ContextSegments returns the segments of the context separated by
+// `:` and `/` with surrounding whitespace trimmed, such as
+// "page", "checkout" and "button" for `[page:checkout/button]`,
+// or nil if the TIK has no context.
+// A context without separators is a single segment.
+func (t TIK) ContextSegments() []string {
+	if len(t.Tokens) == 0 || t.Tokens[0].Type != TokenTypeContext {
+		return nil
+	}
+	return contextSegments(t.Context())
+}
+
+// contextSegments splits context at `:` and `/`
+// and trims whitespace from each segment.
+func contextSegments(context string) []string {
+	var segments []string
+	for {
+		i := strings.IndexAny(context, ":/")
+		if i == -1 {
+			return append(segments, strings.TrimSpace(context))
+		}
+		segments = append(segments, strings.TrimSpace(context[:i]))
+		context = context[i+1:]
+	}
+}
+
+// Equal returns true if t and o are semantically equal: their contexts
+// are equal and they consist of the same sequence of placeholders
+// with equal values and literals that are equal when runs of whitespace
+// are collapsed and whitespace at the start and the end of the text
+// is ignored. Raw differences such as trailing spaces or "\r\n" versus "\n"
+// as well as translator hints are ignored.
+func (t TIK) Equal(o TIK) bool {
+	tn, _ := t.normalized()
+	on, _ := o.normalized()
+	return t.Context() == o.Context() && slices.Equal(tn, on)
+}
+
+// normalToken is a token of a TIK normalized for comparison.
+type normalToken struct {
+	Type  TokenType
+	Value string
+}
+
+// normalized returns the tokens of t except the context in normalized form
+// and the index in t.Tokens each normalized token originates from.
+func (t TIK) normalized() (n []normalToken, index []int) {
+	tokens, first := t.Tokens, 0
+	if len(tokens) > 0 && tokens[0].Type == TokenTypeContext {
+		tokens, first = tokens[1:], 1
+	}
+	n = make([]normalToken, 0, len(tokens))
+	index = make([]int, 0, len(tokens))
+	for i, tok := range tokens {
+		if tok.Type != TokenTypeLiteral {
+			n = append(n, normalToken{Type: tok.Type, Value: tok.Value})
+			index = append(index, first+i)
+			continue
+		}
+		s := collapseSpace(tok.String(t.Raw))
+		if i == 0 {
+			s = strings.TrimLeft(s, " ")
+		}
+		if i == len(tokens)-1 {
+			s = strings.TrimRight(s, " ")
+		}
+		if s != "" {
+			n = append(n, normalToken{Type: TokenTypeLiteral, Value: s})
+			index = append(index, first+i)
+		}
+	}
+	return n, index
+}
+
+// collapseSpace replaces every run of whitespace in s with a single space.
+func collapseSpace(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			if !space {
+				b.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// PlaceholderCount returns the number of placeholders in ts, which is equal
+// to the number of positional arguments of the generated ICU message.
+// Cardinal pluralizations count once, number ranges count twice,
+// message references, markup tags and the context don't count.
+// Placeholders reusing a name count once.
+func (ts Tokens) PlaceholderCount() int {
+	var a argIndexer
+	for _, t := range ts {
+		if t.Type.IsPlaceholder() {
+			a.index(t)
+		}
+	}
+	return a.pos
+}
+
+// LiteralCount returns the number of string literal tokens in ts.
+func (ts Tokens) LiteralCount() int {
+	n := 0
+	for _, t := range ts {
+		if t.Type == TokenTypeLiteral {
+			n++
+		}
+	}
+	return n
+}
+
+// PluralEnd returns the index of the cardinal plural end token matching
+// the cardinal plural start token at ts[start], skipping the ends of
+// nested pluralizations. Returns false if ts[start] isn't the start
+// of a cardinal pluralization or if it's unclosed.
+func (ts Tokens) PluralEnd(start int) (end int, ok bool) {
+	if start < 0 || start >= len(ts) || ts[start].Type != TokenTypeCardinalPluralStart {
+		return 0, false
+	}
+	if end = pluralEnd(ts, start); end == len(ts) {
+		return 0, false
+	}
+	return end, true
+}
+
+// PluralBody returns the tokens between the cardinal plural start token
+// at ts[start] and its matching end, including the offset and the arms.
+// Returns false if ts[start] isn't the start of a cardinal pluralization
+// or if it's unclosed.
+func (ts Tokens) PluralBody(start int) (Tokens, bool) {
+	end, ok := ts.PluralEnd(start)
+	if !ok {
+		return nil, false
+	}
+	return ts[start+1 : end], true
+}
+
 // Placeholders returns an iterators that iterates over placeholder tokens.
+// Message references don't consume arguments and are therefore skipped.
+// The index is the position of the first argument the placeholder consumes,
+// number ranges consume two. Placeholders reusing the name of an earlier
+// named placeholder or enum select don't consume an argument and are
+// assigned the index of the first placeholder of the name.
 func (t TIK) Placeholders() iter.Seq2[int, Token] {
+	return func(yield func(int, Token) bool) {
+		var a argIndexer
+		for _, t := range t.Tokens {
+			if !t.Type.IsPlaceholder() {
+				continue
+			}
+			if !yield(a.index(t), t) {
+				break
+			}
+		}
+	}
+}
+
+// argIndexer assigns positional argument indexes
+// to placeholders in source order.
+type argIndexer struct {
+	pos   int
+	names map[string]int // Index by placeholder name.
+}
+
+// index returns the index of the first argument of placeholder t.
+func (a *argIndexer) index(t Token) int {
+	index := a.pos
+	if name := t.placeholderName(); name != "" {
+		if first, ok := a.names[name]; ok {
+			return first
+		}
+		if a.names == nil {
+			a.names = make(map[string]int)
+		}
+		a.names[name] = index
+	}
+	a.pos += t.Type.argCount()
+	return index
+}
+
+// argNames returns the index of the first argument of every placeholder
+// name in tokens by name, or nil if tokens contain no names.
+func argNames(tokens Tokens) map[string]int {
+	var a argIndexer
+	for _, t := range tokens {
+		if t.Type.IsPlaceholder() {
+			a.index(t)
+		}
+	}
+	return a.names
+}
+
+// placeholderName returns the name of a named placeholder
+// or the declared name of an enum select, otherwise an empty string.
+func (t Token) placeholderName() string {
+	switch {
+	case t.Type == TokenTypeSelect:
+		return t.selectName()
+	case t.Type.isNameable():
+		return t.Value
+	}
+	return ""
+}
+
+// IsPlural returns true if t contains a cardinal pluralization
+// or an ordinal placeholder, in which case translations need
+// plural categories.
+func (t TIK) IsPlural() bool {
+	for _, tok := range t.Tokens {
+		switch tok.Type {
+		case TokenTypeCardinalPluralStart, TokenTypeOrdinalPlural:
+			return true
+		}
+	}
+	return false
+}
+
+// PluralCount returns the number of cardinal pluralizations in t
+// including nested ones.
+func (t TIK) PluralCount() int {
+	n := 0
+	for _, tok := range t.Tokens {
+		if tok.Type == TokenTypeCardinalPluralStart {
+			n++
+		}
+	}
+	return n
+}
+
+// Complexity returns a score of how hard t is to translate,
+// which is the sum of:
+//
+//   - the number of placeholders including cardinal pluralizations,
+//   - the number of distinct placeholder types,
+//   - twice the maximum nesting depth of cardinal pluralizations.
+//
+// For example, `Hello` scores 0, `{text} and {text}` scores 3
+// and `{# files in {# folders}}` scores 2 + 1 + 4 = 7.
+func (t TIK) Complexity() int {
+	score, _ := t.complexity(-1)
+	return score
+}
+
+// complexity returns t.Complexity and the start index of the placeholder
+// that pushes the score over limit, or -1 if limit isn't exceeded.
+// Use a negative limit to not check for a limit.
+func (t TIK) complexity(limit int) (score, index int) {
+	var seen [256]bool
+	depth, maxDepth := 0, 0
+	index = -1
+	for _, tok := range t.Tokens {
+		switch tok.Type {
+		case TokenTypeCardinalPluralStart:
+			depth++
+			maxDepth = max(maxDepth, depth)
+		case TokenTypeCardinalPluralEnd:
+			depth--
+		}
+		if !tok.Type.IsPlaceholder() {
+			continue
+		}
+		score++
+		if !seen[tok.Type] {
+			seen[tok.Type] = true
+			score++
+		}
+		if index == -1 && limit >= 0 && score+2*maxDepth > limit {
+			index = tok.IndexStart
+		}
+	}
+	return score + 2*maxDepth, index
+}
+
+// Literals returns an iterator that iterates over literal tokens,
+// including the literals of cardinal pluralizations.
+// The context, placeholders, markup tags and plural ends are skipped.
+// The index is the position of the literal among all literals of t.
+func (t TIK) Literals() iter.Seq2[int, Token] {
 	return func(yield func(int, Token) bool) {
 		i := 0
 		for _, t := range t.Tokens {
-			switch t.Type {
-			case TokenTypeContext, TokenTypeLiteral, TokenTypeCardinalPluralEnd:
+			if t.Type != TokenTypeLiteral {
 				continue
 			}
 			if !yield(i, t) {
@@ -445,7 +2322,62 @@ func (t TIK) Placeholders() iter.Seq2[int, Token] {
 	}
 }
 
+// Surface returns the translatable surface of t for fuzzy lookups
+// in translation memories: the unescaped literals with every placeholder
+// replaced by the marker `⟦n⟧` where n is its positional argument index,
+// such as "Hello ⟦0⟧, you have ⟦1⟧ messages" for
+// `[inbox] Hello {name}, you have {# messages}`.
+// Literals of all arms of cardinal pluralizations are included and arms
+// are separated by a space. The context, message references, markup tags
+// and skipped arguments are omitted.
+func (t TIK) Surface() string {
+	var b strings.Builder
+	var a argIndexer
+	armStart := false
+	write := func(s string) {
+		if armStart && b.Len() > 0 {
+			last, _ := utf8.DecodeLastRuneInString(b.String())
+			first, _ := utf8.DecodeRuneInString(s)
+			if !unicode.IsSpace(last) && !unicode.IsSpace(first) {
+				b.WriteByte(' ')
+			}
+		}
+		armStart = false
+		b.WriteString(s)
+	}
+	for _, tok := range t.Tokens {
+		switch {
+		case tok.Type == TokenTypeLiteral:
+			write(tok.String(t.Raw))
+		case tok.Type == TokenTypePluralExactMatch, tok.Type == TokenTypePluralOther:
+			armStart = true
+		case tok.Type == TokenTypeSkip:
+			a.index(tok)
+		case tok.Type.IsPlaceholder():
+			write("⟦" + strconv.Itoa(a.index(tok)) + "⟧")
+		}
+	}
+	return b.String()
+}
+
+// UnknownPlaceholders returns the literal tokens of unknown placeholders
+// such as `{something}` enabled by Config.UnknownPlaceholderAsLiteral,
+// or nil if t has none.
+func (t TIK) UnknownPlaceholders() []Token {
+	var unknown []Token
+	for _, tok := range t.Literals() {
+		if t.Raw[tok.IndexStart] == '{' {
+			unknown = append(unknown, tok)
+		}
+	}
+	return unknown
+}
+
 // Parser is a TIK parser instance.
+//
+// Parser reuses its internal token buffer and is therefore not safe for
+// concurrent use. Use Clone to obtain an independent parser per goroutine
+// or use ParserPool instead.
 type Parser struct {
 	t      Tokenizer
 	tokBuf Tokens
@@ -455,11 +2387,16 @@ type Parser struct {
 // NewParser creates a new TIK parser instance.
 func NewParser(conf Config) *Parser {
 	return &Parser{
+		t:      Tokenizer{keywords: newKeywordLookup(conf)},
 		tokBuf: make(Tokens, 0, 16),
 		conf:   conf,
 	}
 }
 
+// Clone returns a new independent parser with the same configuration
+// and its own token buffer.
+func (p *Parser) Clone() *Parser { return NewParser(p.conf) }
+
 type ParseError struct {
 	Index int
 	Err   error
@@ -477,15 +2414,106 @@ func (e ParseError) Unwrap() error { return e.Err }
 // WARNING: Do not alias and use the token slice once fn returns!
 func (p *Parser) ParseFn(input string, fn func(tik TIK)) ParseError {
 	p.tokBuf = p.tokBuf[:0] // Reset buffer.
+	if p.conf.Normalize != nil {
+		input = p.conf.Normalize(input)
+	}
 	var err ParseError
 	p.tokBuf, err = p.t.Tokenize(p.tokBuf, input, p.conf)
 	if err.Err != nil {
 		return err
 	}
-	fn(TIK{Raw: input, Tokens: p.tokBuf})
+	if p.conf.NamedPlaceholders {
+		if err := checkPlaceholderNames(p.tokBuf); err.Err != nil {
+			return err
+		}
+	}
+	t := TIK{Raw: input, Tokens: p.tokBuf}
+	if p.conf.MaxComplexity > 0 {
+		if _, i := t.complexity(p.conf.MaxComplexity); i != -1 {
+			return ParseError{Index: i, Err: ErrTooComplex}
+		}
+	}
+	fn(t)
+	return ParseError{}
+}
+
+// PlaceholderNameError is the error of a ParseError returned
+// for a placeholder name used for placeholders of different types.
+type PlaceholderNameError struct {
+	Name string
+
+	// Index and Type are the start index and the type of the placeholder
+	// the name was first used for.
+	Index int
+	Type  TokenType
+
+	// DuplicateIndex and DuplicateType are the start index and the type
+	// of the conflicting placeholder.
+	DuplicateIndex int
+	DuplicateType  TokenType
+}
+
+func (e *PlaceholderNameError) Error() string {
+	return fmt.Sprintf("%v: %q is %s at index %d and %s at index %d",
+		ErrDuplicatePlaceholderName, e.Name,
+		e.Type, e.Index, e.DuplicateType, e.DuplicateIndex)
+}
+
+func (e *PlaceholderNameError) Unwrap() error { return ErrDuplicatePlaceholderName }
+
+// checkPlaceholderNames returns an error if a placeholder name is used
+// for placeholders of different types.
+// Reusing a name for placeholders of the same type is allowed.
+func checkPlaceholderNames(tokens Tokens) ParseError {
+	var names map[string]Token
+	for _, tok := range tokens {
+		name := tok.placeholderName()
+		if name == "" {
+			continue
+		}
+		first, ok := names[name]
+		if !ok {
+			if names == nil {
+				names = make(map[string]Token)
+			}
+			names[name] = tok
+			continue
+		}
+		if first.Type != tok.Type {
+			return err(tok.IndexStart, &PlaceholderNameError{
+				Name:           name,
+				Index:          first.IndexStart,
+				Type:           first.Type,
+				DuplicateIndex: tok.IndexStart,
+				DuplicateType:  tok.Type,
+			})
+		}
+	}
 	return ParseError{}
 }
 
+// ParseBatch parses each of inputs and calls fn with the index of the input
+// and either the parsed tik or the parse error (err.Err != nil).
+// Like ParseFn, it reuses the internal token buffer across all inputs.
+// ParseBatch stops once fn returns false.
+//
+// WARNING: Do not alias and use the token slice once fn returns!
+// Copy the slice if you need to keep it.
+func (p *Parser) ParseBatch(inputs []string, fn func(i int, tik TIK, err ParseError) bool) {
+	for i, input := range inputs {
+		cont := true
+		errParser := p.ParseFn(input, func(tik TIK) {
+			cont = fn(i, tik, ParseError{})
+		})
+		if errParser.Err != nil {
+			cont = fn(i, TIK{}, errParser)
+		}
+		if !cont {
+			return
+		}
+	}
+}
+
 // Parse parses input and returns a validated TIK, otherwise returns an error.
 // The tokens slice in the returned TIK is a copy of the buffer and doesn't alias
 // the internal parser buffer.
@@ -494,7 +2522,7 @@ func (p *Parser) Parse(input string) (tik TIK, err error) {
 	errParser := p.ParseFn(input, func(ref TIK) {
 		cp := make(Tokens, len(ref.Tokens))
 		copy(cp, ref.Tokens)
-		tik.Raw, tik.Tokens = input, cp
+		tik.Raw, tik.Tokens = ref.Raw, cp
 	})
 	if errParser.Err != nil {
 		return TIK{}, errParser
@@ -502,6 +2530,14 @@ func (p *Parser) Parse(input string) (tik TIK, err error) {
 	return tik, nil
 }
 
+// Validate parses input only to check its validity and returns
+// the same error as Parse would, or a zero ParseError if input is valid.
+// Unlike Parse, it doesn't copy the token buffer and doesn't allocate
+// once the internal buffer of the parser has grown to fit input.
+func (p *Parser) Validate(input string) ParseError {
+	return p.ParseFn(input, func(TIK) {})
+}
+
 func err(index int, err error) ParseError {
 	return ParseError{Index: index, Err: err}
 }
@@ -1,11 +1,20 @@
 package tik_test
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
+	"io"
 	"os"
 	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	tik "github.com/romshark/tik/tik-go"
 )
@@ -39,6 +48,7 @@ func TestParse(t *testing.T) {
 		requireNoErr(t, err)
 		actual := ToTestTokens(input, got.Tokens)
 		requireDeepEqual(t, expect, actual)
+		requireDeepEqual(t, tik.ParseError{}, parser.Validate(input))
 	}
 
 	// String literal only
@@ -208,6 +218,217 @@ func TestParse(t *testing.T) {
 		Token{"{time-short}", tik.TokenTypeTimeShort},
 	)
 
+	// Pluralization arms.
+	f(t, `{# |=0 no messages |=1 one message | messages}`,
+		Token{"{#", tik.TokenTypeCardinalPluralStart},
+		Token{"|=0", tik.TokenTypePluralExactMatch},
+		Token{"no messages", tik.TokenTypeLiteral},
+		Token{"|=1", tik.TokenTypePluralExactMatch},
+		Token{"one message", tik.TokenTypeLiteral},
+		Token{"|", tik.TokenTypePluralOther},
+		Token{" messages", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd},
+	)
+	f(t, `{#|=0  |=1 {name}  |件}`,
+		Token{"{#", tik.TokenTypeCardinalPluralStart},
+		Token{"|=0", tik.TokenTypePluralExactMatch},
+		Token{"|=1", tik.TokenTypePluralExactMatch},
+		Token{"{name}", tik.TokenTypeTextWithGender},
+		Token{"|", tik.TokenTypePluralOther},
+		Token{"件", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd},
+	)
+	f(t, `{# offset:1 |=0 nobody | others and {name}}`,
+		Token{"{#", tik.TokenTypeCardinalPluralStart},
+		Token{"offset:1", tik.TokenTypePluralOffset},
+		Token{"|=0", tik.TokenTypePluralExactMatch},
+		Token{"nobody", tik.TokenTypeLiteral},
+		Token{"|", tik.TokenTypePluralOther},
+		Token{" others and ", tik.TokenTypeLiteral},
+		Token{"{name}", tik.TokenTypeTextWithGender},
+		Token{"}", tik.TokenTypeCardinalPluralEnd},
+	)
+	// Without arms `|` and `offset:` are literal content.
+	f(t, `{# offset:1 users | admins}`,
+		Token{"{#", tik.TokenTypeCardinalPluralStart},
+		Token{" offset:1 users | admins", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd},
+	)
+
+	// Directives directly after the end of a pluralization are regular
+	// directives outside of it.
+	f(t, `{# files}{name}`,
+		Token{"{#", tik.TokenTypeCardinalPluralStart},
+		Token{" files", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd},
+		Token{"{name}", tik.TokenTypeTextWithGender},
+	)
+	f(t, `{# files}{# folders}`,
+		Token{"{#", tik.TokenTypeCardinalPluralStart},
+		Token{" files", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd},
+		Token{"{#", tik.TokenTypeCardinalPluralStart},
+		Token{" folders", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd},
+	)
+	f(t, `{# files}{date-short}`,
+		Token{"{#", tik.TokenTypeCardinalPluralStart},
+		Token{" files", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd},
+		Token{"{date-short}", tik.TokenTypeDateShort},
+	)
+	f(t, `{# |=0 none | files}{@help}`,
+		Token{"{#", tik.TokenTypeCardinalPluralStart},
+		Token{"|=0", tik.TokenTypePluralExactMatch},
+		Token{"none", tik.TokenTypeLiteral},
+		Token{"|", tik.TokenTypePluralOther},
+		Token{" files", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd},
+		Token{"{@help}", tik.TokenTypeMessageRef},
+	)
+
+	// Message references.
+	f(t, `see {@help.intro} and {@faq}`,
+		Token{"see ", tik.TokenTypeLiteral},
+		Token{"{@help.intro}", tik.TokenTypeMessageRef},
+		Token{" and ", tik.TokenTypeLiteral},
+		Token{"{@faq}", tik.TokenTypeMessageRef},
+	)
+
+	// Booleans.
+	f(t, `Wi-Fi is {bool:on/off}, {bool:ein geschaltet/aus}`,
+		Token{"Wi-Fi is ", tik.TokenTypeLiteral},
+		Token{"{bool:on/off}", tik.TokenTypeBoolean},
+		Token{", ", tik.TokenTypeLiteral},
+		Token{"{bool:ein geschaltet/aus}", tik.TokenTypeBoolean},
+	)
+
+	// Enum selects.
+	f(t, `Order {select:status(pending,shipped,delivered)}`,
+		Token{"Order ", tik.TokenTypeLiteral},
+		Token{"{select:status(pending,shipped,delivered)}", tik.TokenTypeSelect},
+	)
+
+	// String placeholders.
+	f(t, `Hello {"John"} and {"#1 fan"}`,
+		Token{"Hello ", tik.TokenTypeLiteral},
+		Token{`{"John"}`, tik.TokenTypeStringPlaceholder},
+		Token{" and ", tik.TokenTypeLiteral},
+		Token{`{"#1 fan"}`, tik.TokenTypeStringPlaceholder},
+	)
+	f(t, `Buy {"5\" screen"} or {"\{x\}"} or {"C:\\"}`,
+		Token{"Buy ", tik.TokenTypeLiteral},
+		Token{`{"5" screen"}`, tik.TokenTypeStringPlaceholder},
+		Token{" or ", tik.TokenTypeLiteral},
+		Token{`{"{x}"}`, tik.TokenTypeStringPlaceholder},
+		Token{" or ", tik.TokenTypeLiteral},
+		Token{`{"C:\"}`, tik.TokenTypeStringPlaceholder},
+	)
+
+	// Time zones.
+	f(t, `{time-short} ({time-zone})`,
+		Token{"{time-short}", tik.TokenTypeTimeShort},
+		Token{" (", tik.TokenTypeLiteral},
+		Token{"{time-zone}", tik.TokenTypeTimeZone},
+		Token{")", tik.TokenTypeLiteral},
+	)
+
+	// Relative times.
+	f(t, `updated {relative-time}`,
+		Token{"updated ", tik.TokenTypeLiteral},
+		Token{"{relative-time}", tik.TokenTypeRelativeTime},
+	)
+
+	// Spellouts.
+	f(t, `pay {spellout} dollars`,
+		Token{"pay ", tik.TokenTypeLiteral},
+		Token{"{spellout}", tik.TokenTypeNumberSpellout},
+		Token{" dollars", tik.TokenTypeLiteral},
+	)
+
+	// Week of year and quarter.
+	f(t, `Week {week-of-year}, {quarter} {number}`,
+		Token{"Week ", tik.TokenTypeLiteral},
+		Token{"{week-of-year}", tik.TokenTypeWeekOfYear},
+		Token{", ", tik.TokenTypeLiteral},
+		Token{"{quarter}", tik.TokenTypeQuarter},
+		Token{" ", tik.TokenTypeLiteral},
+		Token{"{number}", tik.TokenTypeNumber},
+	)
+
+	// Number range.
+	f(t, `{number-range} items, {number}`,
+		Token{"{number-range}", tik.TokenTypeNumberRange},
+		Token{" items, ", tik.TokenTypeLiteral},
+		Token{"{number}", tik.TokenTypeNumber},
+	)
+
+	// Phone numbers.
+	f(t, `Call {phone} or {number}`,
+		Token{"Call ", tik.TokenTypeLiteral},
+		Token{"{phone}", tik.TokenTypePhone},
+		Token{" or ", tik.TokenTypeLiteral},
+		Token{"{number}", tik.TokenTypeNumber},
+	)
+
+	// Emails and URLs.
+	f(t, `Sent to {email}, visit {url}`,
+		Token{"Sent to ", tik.TokenTypeLiteral},
+		Token{"{email}", tik.TokenTypeEmail},
+		Token{", visit ", tik.TokenTypeLiteral},
+		Token{"{url}", tik.TokenTypeURL},
+	)
+
+	// Per mille.
+	f(t, `{permille} or {number}`,
+		Token{"{permille}", tik.TokenTypePermille},
+		Token{" or ", tik.TokenTypeLiteral},
+		Token{"{number}", tik.TokenTypeNumber},
+	)
+
+	// Date and time.
+	f(t, `Sent {date-time}`,
+		Token{"Sent ", tik.TokenTypeLiteral},
+		Token{"{date-time}", tik.TokenTypeDateTime},
+	)
+
+	// Skipped arguments.
+	f(t, `{skip}Sent {number}`,
+		Token{"{skip}", tik.TokenTypeSkip},
+		Token{"Sent ", tik.TokenTypeLiteral},
+		Token{"{number}", tik.TokenTypeNumber},
+	)
+
+	// Measurement units.
+	f(t, `{unit:kilometer} at {unit:celsius}`,
+		Token{"{unit:kilometer}", tik.TokenTypeUnit},
+		Token{" at ", tik.TokenTypeLiteral},
+		Token{"{unit:celsius}", tik.TokenTypeUnit},
+	)
+
+	// Number skeletons.
+	f(t, `{number::.00} or {number::group-off .0#}`,
+		Token{"{number::.00}", tik.TokenTypeNumberSkeleton},
+		Token{" or ", tik.TokenTypeLiteral},
+		Token{"{number::group-off .0#}", tik.TokenTypeNumberSkeleton},
+	)
+
+	// Currency codes.
+	f(t, `{currency} or {currency:EUR}`,
+		Token{"{currency}", tik.TokenTypeCurrency},
+		Token{" or ", tik.TokenTypeLiteral},
+		Token{"{currency:EUR}", tik.TokenTypeCurrency},
+	)
+
+	// Raw ICU.
+	f(t, `{icu:{var, number, ::percent}} of {icu:{var2}} and {icu:'{'x}`,
+		Token{"{icu:{var, number, ::percent}}", tik.TokenTypeRawICUArgument},
+		Token{" of ", tik.TokenTypeLiteral},
+		Token{"{icu:{var2}}", tik.TokenTypeRawICU},
+		Token{" and ", tik.TokenTypeLiteral},
+		Token{"{icu:'{'x}", tik.TokenTypeRawICU},
+	)
+
 	// Escape sequences.
 	f(t, `\{not a placeholder\}`,
 		Token{`{not a placeholder}`, tik.TokenTypeLiteral},
@@ -245,6 +466,7 @@ func TestParseErr(t *testing.T) {
 			actualSuffix := input[pErr.Index:]
 			requireEqual(t, expectAtSuffix, actualSuffix)
 		}
+		requireDeepEqual(t, pErr, parser.Validate(input))
 	}
 
 	f(t, tik.ErrTextEmpty, ``, ``)
@@ -252,6 +474,11 @@ func TestParseErr(t *testing.T) {
 	f(t, tik.ErrTextEmpty, ``, `[context]`)
 	f(t, tik.ErrTextEmpty, ``, `[context]   `)
 	f(t, tik.ErrTextEmpty, "\t\r\n ", "\t\r\n ")
+	f(t, tik.ErrInvalidUTF8, "\xff", "\xff")
+	f(t, tik.ErrInvalidUTF8, "\xff world", "hello \xff world")
+	f(t, tik.ErrInvalidUTF8, "\xc3", "{text}\xc3")
+	f(t, tik.ErrInvalidUTF8, "\xed\xa0\x80 {text}", "\xed\xa0\x80 {text}")
+	f(t, tik.ErrInvalidUTF8, "\xc0\xaf files}", "[ctx] {# \xc0\xaf files}")
 	f(t, tik.ErrContextNoSeparator, `Text`, `[context]Text`)
 	f(t, tik.ErrContextNoSeparator, `[b]okay`, `[c][b]okay`)
 	f(t, tik.ErrContextNoSeparator, `Текст`, `[контекст]Текст`)
@@ -260,14 +487,16 @@ func TestParseErr(t *testing.T) {
 	f(t, tik.ErrContextEmpty, `[]`, "[]")
 	f(t, tik.ErrContextEmpty, `[  ] Text`, `[  ] Text`)
 	f(t, tik.ErrContextEmpty, "[\r\n\t ] Text", "[\r\n\t ] Text")
-	f(t, tik.ErrContextInvalid, `[not escaped\] Text`, `[not escaped\] Text`)
-	f(t, tik.ErrContextInvalid, `[{invalid}] Text`, `[{invalid}] Text`)
-	f(t, tik.ErrContextInvalid, `[{] Text`, `[{] Text`)
-	f(t, tik.ErrContextInvalid, `[}] Text`, `[}] Text`)
-	f(t, tik.ErrContextInvalid, `[[]] Text`, `[[]] Text`)
-	f(t, tik.ErrContextInvalid, `[[nope]] Text`, `[[nope]] Text`)
-	f(t, tik.ErrContextInvalid, `[a[b]c] Text`, `[a[b]c] Text`)
-	f(t, tik.ErrContextInvalid, `[a\[b\]c] Text`, `[a\[b\]c] Text`)
+	f(t, tik.ErrContextUnclosed, `[escaped\] Text`, `[escaped\] Text`)
+	f(t, tik.ErrContextInvalid, `\b] Text`, `[a\b] Text`)
+	f(t, tik.ErrContextInvalid, `\ b] Text`, `[a\ b] Text`)
+	f(t, tik.ErrContextInvalid, `{invalid}] Text`, `[{invalid}] Text`)
+	f(t, tik.ErrContextInvalid, `{] Text`, `[{] Text`)
+	f(t, tik.ErrContextInvalid, `}] Text`, `[}] Text`)
+	f(t, tik.ErrContextInvalid, `[]] Text`, `[[]] Text`)
+	f(t, tik.ErrContextInvalid, `[nope]] Text`, `[[nope]] Text`)
+	f(t, tik.ErrContextInvalid, `[b]c] Text`, `[a[b]c] Text`)
+	f(t, tik.ErrContextInvalid, `[c] Text`, `[a\[b[c] Text`)
 	f(t, tik.ErrContextUnclosed, `[`, "[")
 	f(t, tik.ErrContextUnclosed, `[abc`, "[abc")
 	f(t, tik.ErrContextUnclosed, "[\t\r\n ", "[\t\r\n ")
@@ -276,9 +505,96 @@ func TestParseErr(t *testing.T) {
 	f(t, tik.ErrCardinalPluralTrailingSpace, "\t}", "trailing tab: {# messages\t}")
 	f(t, tik.ErrUnknownPlaceholder, `{April 21}`, `unknown placeholder: {April 21}`)
 	f(t, tik.ErrUnknownPlaceholder, `{8/16/99}`, `unknown placeholder: {8/16/99}`)
+	f(t, tik.ErrMessageRefInvalid, `{@}`, `empty key: {@}`)
+	f(t, tik.ErrMessageRefInvalid, `{@a..b}`, `empty segment: {@a..b}`)
+	f(t, tik.ErrMessageRefInvalid, `{@.a}`, `leading dot: {@.a}`)
+	f(t, tik.ErrMessageRefInvalid, `{@a.}`, `trailing dot: {@a.}`)
+	f(t, tik.ErrMessageRefInvalid, `{@a b}`, `space: {@a b}`)
+	f(t, tik.ErrHintInvalid, `{text#hint}`, `no space: {text#hint}`)
+	f(t, tik.ErrBooleanInvalid, `{bool:}`, `no words: {bool:}`)
+	f(t, tik.ErrBooleanInvalid, `{bool:on}`, `one word: {bool:on}`)
+	f(t, tik.ErrBooleanInvalid, `{bool:/off}`, `empty true: {bool:/off}`)
+	f(t, tik.ErrBooleanInvalid, `{bool:on/}`, `empty false: {bool:on/}`)
+	f(t, tik.ErrBooleanInvalid, `{bool:a/b/c}`, `three words: {bool:a/b/c}`)
+	f(t, tik.ErrBooleanInvalid, `{bool:a{/b}`, `brace: {bool:a{/b}`)
+	f(t, tik.ErrUnknownPlaceholder, `{bool}`, `no colon: {bool}`)
+	f(t, tik.ErrSelectInvalid, `{select:}`, `empty: {select:}`)
+	f(t, tik.ErrSelectInvalid, `{select:status}`, `no options: {select:status}`)
+	f(t, tik.ErrSelectInvalid, `{select:(a,b)}`, `no name: {select:(a,b)}`)
+	f(t, tik.ErrSelectInvalid, `{select:s()}`, `empty option: {select:s()}`)
+	f(t, tik.ErrSelectInvalid, `{select:s(a,)}`, `empty option: {select:s(a,)}`)
+	f(t, tik.ErrSelectInvalid, `{select:s(a,a)}`, `duplicate: {select:s(a,a)}`)
+	f(t, tik.ErrSelectInvalid, `{select:s(a,other)}`,
+		`implicit other: {select:s(a,other)}`)
+	f(t, tik.ErrSelectInvalid, `{select:s(a, b)}`, `space: {select:s(a, b)}`)
+	f(t, tik.ErrSelectInvalid, `{select:s(1a)}`, `digit: {select:s(1a)}`)
+	f(t, tik.ErrSelectInvalid, `{select:1s(a)}`, `digit: {select:1s(a)}`)
+	f(t, tik.ErrSelectInvalid, `{select:s(a-b)}`, `dash: {select:s(a-b)}`)
+	f(t, tik.ErrSelectInvalid, `{select:s(a)x}`, `trailing: {select:s(a)x}`)
+	f(t, tik.ErrSelectInvalid, `{select:var0(a,b)}`, `arg name: {text} {select:var0(a,b)}`)
+	f(t, tik.ErrSelectInvalid, `{select:var12(a)}`, `arg name: {select:var12(a)}`)
+	f(t, tik.ErrSelectInvalid, `{select:var0_gender(a)}`,
+		`arg name: {select:var0_gender(a)}`)
+	f(t, tik.ErrSelectInvalid, `{select:s(c,d)}`,
+		`conflicting options: {select:s(a,b)} {select:s(c,d)}`)
+	f(t, tik.ErrSelectInvalid, `{select:s(a)}`,
+		`conflicting options: {select:s(a,b)} {select:s(a)}`)
+	f(t, tik.ErrStringPlaceholderInvalid, `{""}`, `empty: {""}`)
+	f(t, tik.ErrRawICUInvalid, `{icu:}`, `empty: {icu:}`)
+	f(t, tik.ErrRawICUInvalid, `{icu: }`, `blank: {icu: }`)
+	f(t, tik.ErrRawICUInvalid, `{icu:{var, number}`, `unbalanced: {icu:{var, number}`)
+	f(t, tik.ErrRawICUInvalid, `{icu:{'}'}`, `quoted: {icu:{'}'}`)
+	f(t, tik.ErrUnexpClosure, `}`, `stray close: {icu:{var}}}`)
+	f(t, tik.ErrCurrencyCodeInvalid, `{currency:}`, `empty: {currency:}`)
+	f(t, tik.ErrCurrencyCodeInvalid, `{currency:EU}`, `short: {currency:EU}`)
+	f(t, tik.ErrCurrencyCodeInvalid, `{currency:EURO}`, `long: {currency:EURO}`)
+	f(t, tik.ErrCurrencyCodeInvalid, `{currency:E1R}`, `digit: {currency:E1R}`)
+	f(t, tik.ErrCurrencyCodeInvalid, `{currency:E@R}`, `symbol: {currency:E@R}`)
+	f(t, tik.ErrUnitInvalid, `{unit:}`, `empty: {unit:}`)
+	f(t, tik.ErrUnitInvalid, `{unit:Meter}`, `upper: {unit:Meter}`)
+	f(t, tik.ErrUnitInvalid, `{unit:kilo meter}`, `space: {unit:kilo meter}`)
+	f(t, tik.ErrUnitInvalid, `{unit:-meter}`, `dash: {unit:-meter}`)
+	f(t, tik.ErrUnitInvalid, `{unit:meter-}`, `dash: {unit:meter-}`)
+	f(t, tik.ErrUnitInvalid, `{unit:meter--per}`, `dash: {unit:meter--per}`)
+	f(t, tik.ErrNumberSkeletonInvalid, `{number::}`, `empty: {number::}`)
+	f(t, tik.ErrNumberSkeletonInvalid, `{number:: .00}`, `space: {number:: .00}`)
+	f(t, tik.ErrNumberSkeletonInvalid, `{number::{.00}`, `brace: {number::{.00}`)
+	f(t, tik.ErrNumberSkeletonInvalid, `{number::.00\}}`, `brace: {number::.00\}}`)
+	f(t, tik.ErrStringPlaceholderInvalid, `{"}`, `unclosed: {"}`)
+	f(t, tik.ErrStringPlaceholderInvalid, `{"John}`, `unclosed: {"John}`)
+	f(t, tik.ErrStringPlaceholderInvalid, `{"a"b"}`, `quote: {"a"b"}`)
+	f(t, tik.ErrStringPlaceholderInvalid, `{"a"x}`, `trailing: {"a"x}`)
+	f(t, tik.ErrHintInvalid, `{text # }`, `blank hint: {text # }`)
+	f(t, tik.ErrHintInvalid, `{text # a { b}`, `unescaped: {text # a { b}`)
+	f(t, tik.ErrStringPlaceholderInvalid, `{"a\b"}`, `escape: {"a\b"}`)
+	f(t, tik.ErrStringPlaceholderInvalid, `{"a\"}`, `escaped quote: {"a\"}`)
+	f(t, tik.ErrStringPlaceholderInvalid, `{"a}b"}`, `close: {"a}b"}`)
+	f(t, tik.ErrUnclosedPlaceholder, `{date-short\}`, `escaped close: {date-short\}`)
+	f(t, tik.ErrUnknownPlaceholder, `{date-short\}}`, `escaped close: {date-short\}}`)
+	f(t, tik.ErrUnknownPlaceholder, `{texts # hint}`, `unknown: {texts # hint}`)
+	f(t, tik.ErrMessageRefInvalid, `{@key # hint}`, `ref hint: {@key # hint}`)
+	f(t, tik.ErrPluralOtherArmMissing, `}`, `missing other: {# |=0 none}`)
+	f(t, tik.ErrPluralArmInvalid, `|=1 one}`, `after other: {# |=0 none | x |=1 one}`)
+	f(t, tik.ErrPluralArmInvalid, `| y}`, `two others: {# |=0 none | x | y}`)
+	f(t, tik.ErrPluralArmInvalid, `|=x | y}`, `not a number: {# |=0 none |=x | y}`)
+	f(t, tik.ErrPluralArmInvalid, `|=1| y}`, `no separator: {# |=0 none |=1| y}`)
+	f(t, tik.ErrPluralArmInvalid, `|=0 | y}`, `duplicate: {# |=0 none |=0 | y}`)
+	f(t, tik.ErrPluralArmInvalid, `|=01 b | c}`, `leading zero: {# |=1 a |=01 b | c}`)
+	f(t, tik.ErrPluralArmInvalid, `|=00 a | b}`, `leading zero: {# |=00 a | b}`)
+	f(t, tik.ErrPluralArmInvalid, `|=2147483648 a | b}`,
+		`out of range: {# |=2147483648 a | b}`)
+	f(t, tik.ErrPluralArmInvalid, `|=99999999999999999999 a | b}`,
+		`out of range: {# |=99999999999999999999 a | b}`)
+	f(t, tik.ErrPluralArmInvalid, `offset:01 |=0 a | b}`, `leading zero: {# offset:01 |=0 a | b}`)
+	f(t, tik.ErrPluralArmInvalid, `offset:99999999999999999999 |=0 a | b}`,
+		`out of range: {# offset:99999999999999999999 |=0 a | b}`)
+	f(t, tik.ErrDirectiveStartsCardinalPlural, `{text}}`,
+		`directive starts other: {# |=0 none | {text}}`)
+	f(t, tik.ErrCardinalPluralTrailingSpace, ` }`, `trailing space: {# |=0 none | x }`)
 	f(t, tik.ErrUnclosedPlaceholder, `{`, `unexpected EOF: {`)
 	f(t, tik.ErrUnclosedPlaceholder, `{x`, `unexpected EOF: {x`)
 	f(t, tik.ErrUnclosedPlaceholder, `{{`, `unexpected EOF: {{`)
+	f(t, tik.ErrUnclosedPlaceholder, `{# x {text}`, `unclosed plural: {# x {text}`)
 	f(t, tik.ErrNestedPluralization, `{# folders}}`, `nested pluralization: {# messages in {# folders}}`)
 	f(t, tik.ErrCardinalPluralEmpty, ` }`, `empty pluralization: {# }`)
 	f(t, tik.ErrCardinalPluralEmpty, "\t}", "empty pluralization: {#\t}")
@@ -302,201 +618,2929 @@ func TestParseErr(t *testing.T) {
 	f(t, tik.ErrDirectiveStartsCardinalPlural, `{currency}}`, `illegal: {#{currency}}`)
 }
 
-func TestTokenizeErrMsg(t *testing.T) {
+func TestParseAllowEmptyText(t *testing.T) {
 	t.Parallel()
 
-	parser := tik.NewParser(tik.DefaultConfig)
+	conf := tik.DefaultConfig
+	conf.AllowEmptyText = true
+	p := tik.NewParser(conf)
+	strict := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewICUTranslator(conf)
+
+	f := func(t *testing.T, expectContext, input string) {
+		t.Helper()
+		_, err := strict.Parse(input)
+		requireErrIs(t, tik.ErrTextEmpty, err)
+
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expectContext, tk.Context())
+		if expectContext == "" {
+			requireEqual(t, 0, len(tk.Tokens))
+		} else {
+			requireEqual(t, 1, len(tk.Tokens))
+		}
+		requireEqual(t, "", translator.TIK2ICU(tk))
+	}
+
+	f(t, "", ``)
+	f(t, "", "\t\r\n ")
+	f(t, "spacer", `[spacer]`)
+	f(t, "spacer", `  [spacer]   `)
+
+	// Non-empty TIKs are unaffected.
+	tk, err := p.Parse(`[ctx] hello`)
+	requireNoErr(t, err)
+	requireDeepEqual(t, []Token{
+		{"[ctx]", tik.TokenTypeContext},
+		{"hello", tik.TokenTypeLiteral},
+	}, ToTestTokens(tk.Raw, tk.Tokens))
+	_, err = p.Parse(`[] hello`)
+	requireErrIs(t, tik.ErrContextEmpty, err)
+}
+
+func TestParsePreserveEdgeWhitespace(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.PreserveEdgeWhitespace = true
+	preserve := tik.NewParser(conf)
+	trim := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, p *tik.Parser, input string, expect ...Token) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireDeepEqual(t, expect, ToTestTokens(input, tk.Tokens))
+	}
+
+	f(t, trim, "  hello  ", Token{"hello", tik.TokenTypeLiteral})
+	f(t, preserve, "  hello  ", Token{"  hello  ", tik.TokenTypeLiteral})
+	f(t, preserve, " · ", Token{" · ", tik.TokenTypeLiteral})
+	f(t, trim, " {text}: ",
+		Token{"{text}", tik.TokenTypeText},
+		Token{":", tik.TokenTypeLiteral})
+	f(t, preserve, " {text}: ",
+		Token{" ", tik.TokenTypeLiteral},
+		Token{"{text}", tik.TokenTypeText},
+		Token{": ", tik.TokenTypeLiteral})
+
+	// The separator following the context isn't part of the body.
+	f(t, trim, "[ctx]  hello ",
+		Token{"[ctx]", tik.TokenTypeContext},
+		Token{"hello", tik.TokenTypeLiteral})
+	f(t, preserve, "[ctx]  hello ",
+		Token{"[ctx]", tik.TokenTypeContext},
+		Token{"hello ", tik.TokenTypeLiteral})
+
+	// A context is only recognized at the very start.
+	f(t, trim, " [ctx] hello",
+		Token{"[ctx]", tik.TokenTypeContext},
+		Token{"hello", tik.TokenTypeLiteral})
+	f(t, preserve, " [ctx] hello", Token{" [ctx] hello", tik.TokenTypeLiteral})
+
+	// Whitespace-only TIKs remain empty.
+	_, err := preserve.Parse("   ")
+	requireErrIs(t, tik.ErrTextEmpty, err)
+}
+
+func TestParseNormalize(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.Normalize = strings.NewReplacer("\u00a0", " ", "\u2026", "...").Replace
+	p := tik.NewParser(conf)
+	a, err := p.Parse("[ctx] wait\u2026 {text}")
+	requireNoErr(t, err)
+	b, err := p.Parse("[ctx] wait... {text}")
+	requireNoErr(t, err)
+	requireDeepEqual(t, a, b)
+	requireEqual(t, "[ctx] wait... {text}", a.Raw)
+	// Token indexes refer to the normalized input.
+	requireDeepEqual(t, []Token{
+		{"[ctx]", tik.TokenTypeContext},
+		{"wait... ", tik.TokenTypeLiteral},
+		{"{text}", tik.TokenTypeText},
+	}, ToTestTokens(a.Raw, a.Tokens))
+
+	// Error indexes refer to the normalized input.
+	_, err = p.Parse("\u2026\u00a0{unknown}")
+	requireDeepEqual(t, error(tik.ParseError{
+		Index: len("... "), Err: tik.ErrUnknownPlaceholder,
+	}), err)
+}
+
+func TestParseNamedPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	// Names are disabled by default.
+	_, err := tik.NewParser(tik.DefaultConfig).Parse(`{text:user}`)
+	requireDeepEqual(t, error(tik.ParseError{
+		Index: 0, Err: tik.ErrUnknownPlaceholder,
+	}), err)
+
+	conf := tik.DefaultConfig
+	conf.NamedPlaceholders = true
+	p := tik.NewParser(conf)
+
+	f := func(t *testing.T, input string, expect ...tik.Token) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireDeepEqual(t, tik.Tokens(expect), tk.Tokens)
+	}
+
+	f(t, `{text:user} has {integer:count # number of items}`,
+		tik.Token{IndexStart: 0, IndexEnd: 11, Type: tik.TokenTypeText, Value: "user"},
+		tik.Token{IndexStart: 11, IndexEnd: 16, Type: tik.TokenTypeLiteral},
+		tik.Token{
+			IndexStart: 16, IndexEnd: 49, Type: tik.TokenTypeInteger,
+			Value: "count", Hint: "number of items",
+		})
+	// Reusing a name for placeholders of the same type is allowed.
+	f(t, `{text:user} {date-short:day} {text:user} {text}`,
+		tik.Token{IndexStart: 0, IndexEnd: 11, Type: tik.TokenTypeText, Value: "user"},
+		tik.Token{IndexStart: 11, IndexEnd: 12, Type: tik.TokenTypeLiteral},
+		tik.Token{IndexStart: 12, IndexEnd: 28, Type: tik.TokenTypeDateShort, Value: "day"},
+		tik.Token{IndexStart: 28, IndexEnd: 29, Type: tik.TokenTypeLiteral},
+		tik.Token{IndexStart: 29, IndexEnd: 40, Type: tik.TokenTypeText, Value: "user"},
+		tik.Token{IndexStart: 40, IndexEnd: 41, Type: tik.TokenTypeLiteral},
+		tik.Token{IndexStart: 41, IndexEnd: 47, Type: tik.TokenTypeText})
+	f(t, `{select:status(a,b)} {select:status(a,b)}`,
+		tik.Token{
+			IndexStart: 0, IndexEnd: 20, Type: tik.TokenTypeSelect, Value: "status(a,b)",
+		},
+		tik.Token{IndexStart: 20, IndexEnd: 21, Type: tik.TokenTypeLiteral},
+		tik.Token{
+			IndexStart: 21, IndexEnd: 41, Type: tik.TokenTypeSelect, Value: "status(a,b)",
+		})
+	// Currency codes are not names.
+	f(t, `{currency:EUR}`,
+		tik.Token{IndexStart: 0, IndexEnd: 14, Type: tik.TokenTypeCurrency, Value: "EUR"})
+
+	// Names don't change the generated ICU.
+	tk, err := p.Parse(`{text:user} has {# items}`)
+	requireNoErr(t, err)
+	requireEqual(t, `{var0} has {var1, plural, other {# items}}`,
+		tik.NewICUTranslator(conf).TIK2ICU(tk))
+}
+
+func TestParseNamedPlaceholdersErr(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.NamedPlaceholders = true
+	p := tik.NewParser(conf)
+
+	f := func(t *testing.T, expect tik.ParseError, input string) {
+		t.Helper()
+		_, err := p.Parse(input)
+		requireDeepEqual(t, error(expect), err)
+	}
+
+	f(t, tik.ParseError{Index: 0, Err: tik.ErrPlaceholderNameInvalid}, `{text:}`)
+	f(t, tik.ParseError{Index: 0, Err: tik.ErrPlaceholderNameInvalid}, `{text:1st}`)
+	f(t, tik.ParseError{Index: 0, Err: tik.ErrPlaceholderNameInvalid}, `{text:a b}`)
+	f(t, tik.ParseError{Index: 0, Err: tik.ErrUnknownPlaceholder}, `{texts:user}`)
+	f(t, tik.ParseError{Index: 0, Err: tik.ErrUnknownPlaceholder}, `{link:user}`)
+	f(t, tik.ParseError{Index: 0, Err: tik.ErrCurrencyCodeInvalid}, `{currency:user}`)
+
+	f(t, tik.ParseError{Index: 16, Err: &tik.PlaceholderNameError{
+		Name: "user", Index: 0, Type: tik.TokenTypeText,
+		DuplicateIndex: 16, DuplicateType: tik.TokenTypeInteger,
+	}}, `{text:user} and {integer:user}`)
+	f(t, tik.ParseError{Index: 21, Err: &tik.PlaceholderNameError{
+		Name: "status", Index: 0, Type: tik.TokenTypeSelect,
+		DuplicateIndex: 21, DuplicateType: tik.TokenTypeText,
+	}}, `{select:status(a,b)} {text:status}`)
+
+	_, err := p.Parse(`{name:x} {text:x}`)
+	requireErrIs(t, tik.ErrDuplicatePlaceholderName, err)
+	requireEqual(t, `at index 9: duplicate placeholder name: `+
+		`"x" is text with gender at index 0 and text at index 9`, err.Error())
+}
+
+func TestParseCaseInsensitiveKeywords(t *testing.T) {
+	t.Parallel()
+
+	// Keywords are case-sensitive by default.
+	p := tik.NewParser(tik.DefaultConfig)
+	for _, input := range []string{`{Date-Short}`, `{TEXT}`, `{Bool:on/off}`} {
+		_, err := p.Parse(input)
+		requireDeepEqual(t, error(tik.ParseError{
+			Index: 0, Err: tik.ErrUnknownPlaceholder,
+		}), err)
+	}
+
+	conf := tik.DefaultConfig
+	conf.CaseInsensitiveKeywords = true
+	conf.NamedPlaceholders = true
+	p = tik.NewParser(conf)
+
+	f := func(t *testing.T, input string, expect ...tik.Token) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireDeepEqual(t, tik.Tokens(expect), tk.Tokens)
+	}
+
+	f(t, `{Date-Short}`,
+		tik.Token{IndexStart: 0, IndexEnd: 12, Type: tik.TokenTypeDateShort})
+	f(t, `{TEXT # a Hint}`,
+		tik.Token{IndexStart: 0, IndexEnd: 15, Type: tik.TokenTypeText, Hint: "a Hint"})
+	f(t, `{Text:User}`,
+		tik.Token{IndexStart: 0, IndexEnd: 11, Type: tik.TokenTypeText, Value: "User"})
+	// Values keep their case.
+	f(t, `{BOOL:On/Off}`,
+		tik.Token{IndexStart: 0, IndexEnd: 13, Type: tik.TokenTypeBoolean, Value: "On/Off"})
+	f(t, `{Select:Status(A,b)}`,
+		tik.Token{
+			IndexStart: 0, IndexEnd: 20, Type: tik.TokenTypeSelect, Value: "Status(A,b)",
+		})
+	f(t, `{Currency:eur}`,
+		tik.Token{IndexStart: 0, IndexEnd: 14, Type: tik.TokenTypeCurrency, Value: "EUR"})
+
+	_, err := p.Parse(`{Texts}`)
+	requireDeepEqual(t, error(tik.ParseError{
+		Index: 0, Err: tik.ErrUnknownPlaceholder,
+	}), err)
+}
+
+func TestParseNestedPlural(t *testing.T) {
+	t.Parallel()
+
+	const input = `{# messages in {# folders}}`
+
+	// Nested pluralizations are rejected by default.
+	_, err := tik.NewParser(tik.DefaultConfig).Parse(input)
+	requireDeepEqual(t, error(tik.ParseError{
+		Index: len(`{# messages in `), Err: tik.ErrNestedPluralization,
+	}), err)
+
+	conf := tik.DefaultConfig
+	conf.AllowNestedPlural = true
+	conf.MarkupTags = []string{"b"}
+	p := tik.NewParser(conf)
+	translator := tik.NewICUTranslator(conf)
+
+	tk, err := p.Parse(input)
+	requireNoErr(t, err)
+	requireDeepEqual(t, []Token{
+		{"{#", tik.TokenTypeCardinalPluralStart},
+		{" messages in ", tik.TokenTypeLiteral},
+		{"{#", tik.TokenTypeCardinalPluralStart},
+		{" folders", tik.TokenTypeLiteral},
+		{"}", tik.TokenTypeCardinalPluralEnd},
+		{"}", tik.TokenTypeCardinalPluralEnd},
+	}, ToTestTokens(tk.Raw, tk.Tokens))
+
+	f := func(t *testing.T, expect, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expect, translator.TIK2ICU(tk))
+	}
+
+	f(t, "{var0, plural, other {# messages in {var1, plural, other {# folders}}}}",
+		input)
+	f(t, "{var0} has {var1, plural, =0 {no files} "+
+		"other {# files in {var2, plural, =1 {one folder} other {# folders}}}} "+
+		"on {var3, date, short}",
+		`{name} has {# |=0 no files | files in {# |=1 one folder | folders}} on {date-short}`)
+	// Arms of the enclosing pluralization continue after the nested one.
+	f(t, "{var0, plural, =0 {none} =1 {one {var1, plural, =0 {x} =1 {y} other {# z}}} "+
+		"=2 {two} other {# more}}",
+		`{# |=0 none |=1 one {# |=0 x |=1 y | z} |=2 two | more}`)
+	f(t, "{var0, plural, other {# a {var1, plural, other {# b {var2, plural, other {# c}}}} d}} "+
+		"e {var3, plural, other {# f}}",
+		`{# a {# b {# c}} d} e {# f}`)
+	f(t, "{var0, plural, other {# x <0>a {var1, plural, other {# b}}</0>}}",
+		`{# x {<b>}a {# b}{</b>}}`)
+
+	fErr := func(t *testing.T, expect tik.ParseError, input string) {
+		t.Helper()
+		_, err := p.Parse(input)
+		requireDeepEqual(t, error(expect), err)
+	}
+
+	fErr(t, tik.ParseError{Index: 3, Err: tik.ErrDirectiveStartsCardinalPlural},
+		`{# {# x}}`)
+	fErr(t, tik.ParseError{Index: 14, Err: tik.ErrDirectiveStartsCardinalPlural},
+		`{# |=0 none | {# x}}`)
+	fErr(t, tik.ParseError{Index: 0, Err: tik.ErrUnclosedPlaceholder}, `{# x {# y}`)
+	fErr(t, tik.ParseError{Index: 5, Err: tik.ErrUnclosedPlaceholder}, `{# x {# y {text}`)
+	// Exact match arms of nested pluralizations don't conflict with the
+	// arms of the enclosing one, duplicates in the enclosing one still do.
+	fErr(t, tik.ParseError{Index: 25, Err: tik.ErrPluralArmInvalid},
+		`{# |=1 one {# |=1 x | y} |=1 dup | more}`)
+	fErr(t, tik.ParseError{Index: 14, Err: tik.ErrTagUnbalanced},
+		`{# a {<b>}{# x{</b>}}}`)
+}
+
+func TestParseMarkupTags(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.MarkupTags = []string{"a", "b", "br"}
+	p := tik.NewParser(conf)
+	translator := tik.NewICUTranslator(conf)
+
+	f := func(t *testing.T, expectICU, input string, expect ...Token) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireDeepEqual(t, expect, ToTestTokens(input, tk.Tokens))
+		requireEqual(t, expectICU, translator.TIK2ICU(tk))
+	}
+
+	f(t, "Click <0>here</0> to continue",
+		`Click {<a>}here{</a>} to continue`,
+		Token{"Click ", tik.TokenTypeLiteral},
+		Token{"{<a>}", tik.TokenTypeTagOpen},
+		Token{"here", tik.TokenTypeLiteral},
+		Token{"{</a>}", tik.TokenTypeTagClose},
+		Token{" to continue", tik.TokenTypeLiteral})
+
+	// Literal angle brackets are quoted.
+	f(t, "a '<' b <0/> c '<'/x> '<{'d'}'",
+		`a < b {<br/>} c </x> <\{d\}`,
+		Token{"a < b ", tik.TokenTypeLiteral},
+		Token{"{<br/>}", tik.TokenTypeTagSelfClosing},
+		Token{" c </x> <{d}", tik.TokenTypeLiteral})
+
+	// Nested and self-closing tags.
+	f(t, "<0>hello <1>{var0}</1></0><2/>bye",
+		`{<a>}hello {<b>}{name}{</b>}{</a>}{<br/>}bye`,
+		Token{"{<a>}", tik.TokenTypeTagOpen},
+		Token{"hello ", tik.TokenTypeLiteral},
+		Token{"{<b>}", tik.TokenTypeTagOpen},
+		Token{"{name}", tik.TokenTypeTextWithGender},
+		Token{"{</b>}", tik.TokenTypeTagClose},
+		Token{"{</a>}", tik.TokenTypeTagClose},
+		Token{"{<br/>}", tik.TokenTypeTagSelfClosing},
+		Token{"bye", tik.TokenTypeLiteral})
+
+	// Tags inside pluralizations.
+	f(t, "{var0, plural, =0 {no <0>items</0>} other {# many <1>items</1>}}",
+		`{# |=0 no {<b>}items{</b>} | many {<b>}items{</b>}}`,
+		Token{"{#", tik.TokenTypeCardinalPluralStart},
+		Token{"|=0", tik.TokenTypePluralExactMatch},
+		Token{"no ", tik.TokenTypeLiteral},
+		Token{"{<b>}", tik.TokenTypeTagOpen},
+		Token{"items", tik.TokenTypeLiteral},
+		Token{"{</b>}", tik.TokenTypeTagClose},
+		Token{"|", tik.TokenTypePluralOther},
+		Token{" many ", tik.TokenTypeLiteral},
+		Token{"{<b>}", tik.TokenTypeTagOpen},
+		Token{"items", tik.TokenTypeLiteral},
+		Token{"{</b>}", tik.TokenTypeTagClose},
+		Token{"}", tik.TokenTypeCardinalPluralEnd})
+
+	fErr := func(t *testing.T, expect error, expectAtSuffix, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireErrIs(t, expect, err)
+		requireDeepEqual(t, tik.TIK{}, tk)
+		var pErr tik.ParseError
+		if errors.As(err, &pErr) {
+			requireEqual(t, expectAtSuffix, input[pErr.Index:])
+		}
+	}
+
+	fErr(t, tik.ErrTagInvalid, `{<i>}x{</i>}`, `unknown {<i>}x{</i>}`)
+	fErr(t, tik.ErrTagInvalid, `{<a}`, `malformed {<a}`)
+	fErr(t, tik.ErrTagInvalid, `{<>}`, `empty {<>}`)
+	fErr(t, tik.ErrTagUnbalanced, `{<a>}x`, `unclosed {<a>}x`)
+	fErr(t, tik.ErrTagUnbalanced, `{</a>}`, `unopened {</a>}`)
+	fErr(t, tik.ErrTagUnbalanced, `{</a>}{</b>}`, `{<a>}{<b>}x{</a>}{</b>}`)
+	fErr(t, tik.ErrTagUnbalanced, `{<a>}| x}`, `{# |=0 {<a>}| x}`)
+	fErr(t, tik.ErrTagUnbalanced, `{<b>}x}`, `{# items {<b>}x}`)
+	fErr(t, tik.ErrTagUnbalanced, `{</a>}}`, `{<a>}x {# items {</a>}}`)
+
+	// Tags are unknown unless configured.
+	_, err := tik.NewParser(tik.DefaultConfig).Parse(`{<a>}x{</a>}`)
+	requireErrIs(t, tik.ErrTagInvalid, err)
+}
+
+func TestParseStrictEscapes(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.StrictEscapes = true
+	strict := tik.NewParser(conf)
+	lenient := tik.NewParser(tik.DefaultConfig)
+
+	fOK := func(t *testing.T, input string, expect ...Token) {
+		t.Helper()
+		got, err := strict.Parse(input)
+		requireNoErr(t, err)
+		requireDeepEqual(t, expect, ToTestTokens(input, got.Tokens))
+	}
+	fErr := func(t *testing.T, expectAtSuffix string, input string) {
+		t.Helper()
+		// Lenient mode accepts the same input.
+		_, err := lenient.Parse(input)
+		requireNoErr(t, err)
+
+		tk, err := strict.Parse(input)
+		requireErrIs(t, tik.ErrInvalidEscape, err)
+		requireDeepEqual(t, tik.TIK{}, tk)
+		var pErr tik.ParseError
+		if errors.As(err, &pErr) {
+			requireEqual(t, expectAtSuffix, input[pErr.Index:])
+		}
+	}
+
+	fOK(t, `\{not a placeholder\} \\`,
+		Token{`{not a placeholder} \`, tik.TokenTypeLiteral})
+	fOK(t, `\\{text} {# \{x\\}`,
+		Token{`\`, tik.TokenTypeLiteral},
+		Token{`{text}`, tik.TokenTypeText},
+		Token{` `, tik.TokenTypeLiteral},
+		Token{`{#`, tik.TokenTypeCardinalPluralStart},
+		Token{` {x\`, tik.TokenTypeLiteral},
+		Token{`}`, tik.TokenTypeCardinalPluralEnd})
+
+	// Dangling reverse solidus.
+	fErr(t, `\`, `text\`)
+	fErr(t, `\  `, `text\  `)
+	fErr(t, `\`, `{text} text\`)
+	// Invalid escape sequences.
+	fErr(t, `\n`, `line\n`)
+	fErr(t, `\t {text}`, `tab\t {text}`)
+	fErr(t, `\[ {text}`, `{text} \[ {text}`)
+	fErr(t, `\n}`, `{# new\n}`)
+}
+
+func TestParsePluralSign(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.CardinalPluralNumberSign = "№"
+	p := tik.NewParser(conf)
+	icu := tik.NewICUTranslator(conf)
+
+	f := func(t *testing.T, expectICU, input string, expect ...Token) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireDeepEqual(t, expect, ToTestTokens(input, tk.Tokens))
+		requireEqual(t, expectICU, icu.TIK2ICU(tk))
+	}
+
+	f(t, "{var0, plural, other {# messages}}", `{№ messages}`,
+		Token{"{№", tik.TokenTypeCardinalPluralStart},
+		Token{" messages", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd})
+	f(t, "{var0, plural, other {#件}}", `{№件}`,
+		Token{"{№", tik.TokenTypeCardinalPluralStart},
+		Token{"件", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd})
+	f(t, "{var0, plural, =0 {none} other {# items}}", `{№ |=0 none | items}`,
+		Token{"{№", tik.TokenTypeCardinalPluralStart},
+		Token{"|=0", tik.TokenTypePluralExactMatch},
+		Token{"none", tik.TokenTypeLiteral},
+		Token{"|", tik.TokenTypePluralOther},
+		Token{" items", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd})
+	// `#` is literal text inside of the body.
+	f(t, "{var0, plural, other {# items '#'1}}", `{№ items #1}`,
+		Token{"{№", tik.TokenTypeCardinalPluralStart},
+		Token{" items #1", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd})
+	// Hints are still separated by `#`.
+	f(t, "{var0}", `{text # a hint}`,
+		Token{"{text # a hint}", tik.TokenTypeText})
+
+	_, err := p.Parse(`{# messages}`)
+	requireErrIs(t, tik.ErrUnknownPlaceholder, err)
+}
+
+func TestParseHints(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+
+	tk, err := p.Parse(`[ctx] {name # the sender} sent {# messages to {text ` +
+		`#  the recipient's \{full name }} on {date-short}`)
+	requireNoErr(t, err)
+	requireDeepEqual(t, []Token{
+		{"[ctx]", tik.TokenTypeContext},
+		{"{name # the sender}", tik.TokenTypeTextWithGender},
+		{" sent ", tik.TokenTypeLiteral},
+		{"{#", tik.TokenTypeCardinalPluralStart},
+		{" messages to ", tik.TokenTypeLiteral},
+		{`{text #  the recipient's {full name }`, tik.TokenTypeText},
+		{"}", tik.TokenTypeCardinalPluralEnd},
+		{" on ", tik.TokenTypeLiteral},
+		{"{date-short}", tik.TokenTypeDateShort},
+	}, ToTestTokens(tk.Raw, tk.Tokens))
+	requireEqual(t, "the sender", tk.Tokens[1].Hint)
+	requireEqual(t, `the recipient's {full name`, tk.Tokens[5].Hint)
+	requireEqual(t, "", tk.Tokens[8].Hint)
+
+	// Hints aren't part of the ICU message.
+	icu := tik.NewICUTranslator(tik.DefaultConfig)
+	requireEqual(t, "{var0} sent {var1, plural, other {# messages to {var2}}} "+
+		"on {var3, date, short}", icu.TIK2ICU(tk))
+	requireDeepEqual(t, map[string]string{
+		"var0": "the sender",
+		"var2": `the recipient's {full name`,
+	}, icu.ArgHints(tk))
+
+	tk, err = p.Parse(`no hints {text}`)
+	requireNoErr(t, err)
+	requireDeepEqual(t, map[string]string(nil), icu.ArgHints(tk))
+}
+
+func TestParseEscapedClose(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expectHint, input string, expect ...Token) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireDeepEqual(t, expect, ToTestTokens(tk.Raw, tk.Tokens))
+		requireEqual(t, expectHint, tk.Tokens[0].Hint)
+	}
+
+	// An escaped closing curly brace doesn't end the directive.
+	f(t, "a } b", `{text # a \} b} x`,
+		Token{"{text # a } b}", tik.TokenTypeText},
+		Token{" x", tik.TokenTypeLiteral},
+	)
+	f(t, "{full name}", `{text # \{full name\}}`,
+		Token{"{text # {full name}}", tik.TokenTypeText},
+	)
+	f(t, "a } b", `{text # a \} b}{# files \}}`,
+		Token{"{text # a } b}", tik.TokenTypeText},
+		Token{"{#", tik.TokenTypeCardinalPluralStart},
+		Token{" files }", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd},
+	)
+	f(t, "", `{# |=0 none\} | files}`,
+		Token{"{#", tik.TokenTypeCardinalPluralStart},
+		Token{"|=0", tik.TokenTypePluralExactMatch},
+		Token{"none}", tik.TokenTypeLiteral},
+		Token{"|", tik.TokenTypePluralOther},
+		Token{" files", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd},
+	)
+
+	// An escaped reverse-solidus doesn't escape the closing curly brace.
+	f(t, `a\`, `{text # a\\} x`,
+		Token{`{text # a\}`, tik.TokenTypeText},
+		Token{" x", tik.TokenTypeLiteral},
+	)
+
+	// An escaped closing curly brace in a string placeholder sample
+	// is part of the sample.
+	f(t, "", `{"a\}b"}{"\{c\}" # d \}}`,
+		Token{`{"a}b"}`, tik.TokenTypeStringPlaceholder},
+		Token{`{"{c}" # d }}`, tik.TokenTypeStringPlaceholder},
+	)
+
+	icu := tik.NewICUTranslator(tik.DefaultConfig)
+	tk, err := p.Parse(`{text # a \} b} and {text}`)
+	requireNoErr(t, err)
+	requireEqual(t, "{var0} and {var1}", icu.TIK2ICU(tk))
+	tk, err = p.Parse(`{"a\}b"}{"\{c\}" # d \}}`)
+	requireNoErr(t, err)
+	requireEqual(t, "{var0}{var1}", icu.TIK2ICU(tk))
+	requireEqual(t, "a}b", tk.Tokens[0].Value)
+	requireEqual(t, "{c}", tk.Tokens[1].Value)
+	requireEqual(t, "d }", tk.Tokens[1].Hint)
+	tk, err = p.Parse(`{# files \}}`)
+	requireNoErr(t, err)
+	requireEqual(t, "{var0, plural, other {# files '}'}}", icu.TIK2ICU(tk))
+}
+
+func TestParseContextBrackets(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.ContextBrackets = [2]rune{'【', '】'}
+	requireNoErr(t, conf.Validate())
+	parser := tik.NewParser(conf)
+
+	f := func(t *testing.T, input string, expect ...Token) {
+		t.Helper()
+		got, err := parser.Parse(input)
+		requireNoErr(t, err)
+		requireDeepEqual(t, expect, ToTestTokens(input, got.Tokens))
+	}
+	fErr := func(t *testing.T, expectErr error, expectAtSuffix, input string) {
+		t.Helper()
+		_, err := parser.Parse(input)
+		requireErrIs(t, expectErr, err)
+		var pErr tik.ParseError
+		if errors.As(err, &pErr) {
+			requireEqual(t, expectAtSuffix, input[pErr.Index:])
+		}
+	}
+
+	f(t, "【文脈】 テキスト",
+		Token{"【文脈】", tik.TokenTypeContext},
+		Token{"テキスト", tik.TokenTypeLiteral},
+	)
+	f(t, "  【c】 {text} [b]",
+		Token{"【c】", tik.TokenTypeContext},
+		Token{"{text}", tik.TokenTypeText},
+		Token{" [b]", tik.TokenTypeLiteral},
+	)
+	f(t, "[not a context] text",
+		Token{"[not a context] text", tik.TokenTypeLiteral},
+	)
+	f(t, "【a[b]c】 text",
+		Token{"【a[b]c】", tik.TokenTypeContext},
+		Token{"text", tik.TokenTypeLiteral},
+	)
+	fErr(t, tik.ErrContextNoSeparator, "text", "【c】text")
+	fErr(t, tik.ErrContextEmpty, "【 】 text", "【 】 text")
+	fErr(t, tik.ErrContextUnclosed, "【c text", "【c text")
+	fErr(t, tik.ErrContextInvalid, "【b】 text", "【a【b】 text")
+}
+
+func TestTokenizeErrContext(t *testing.T) {
+	t.Parallel()
+
+	var tokenizer tik.Tokenizer
+	conf := tik.DefaultConfig
+	conf.StrictContextSegments = true
+	conf.MaxContextLen = 16
+
+	f := func(t *testing.T, expect tik.ParseError, input string) {
+		t.Helper()
+		buffer := make(tik.Tokens, 1, 8)
+		tokens, err := tokenizer.Tokenize(buffer, input, conf)
+		requireDeepEqual(t, expect, err)
+		requireDeepEqual(t, tik.Tokens(nil), tokens)
+	}
+
+	f(t, tik.ParseError{Index: 0, Err: tik.ErrContextUnclosed}, `[context text`)
+	f(t, tik.ParseError{Index: 2, Err: tik.ErrContextUnclosed}, `  [context\] text`)
+	f(t, tik.ParseError{Index: 0, Err: tik.ErrContextEmpty}, `[] text`)
+	f(t, tik.ParseError{Index: 1, Err: tik.ErrContextEmpty}, "\t[ ] text")
+	f(t, tik.ParseError{Index: 2, Err: tik.ErrContextInvalid}, `[a{b] text`)
+	f(t, tik.ParseError{Index: 5, Err: tik.ErrContextInvalid}, `   [a\b] text`)
+	f(t, tik.ParseError{Index: 8, Err: tik.ErrContextInvalid}, `[ok\] ok[] text`)
+	f(t, tik.ParseError{Index: 0, Err: tik.ErrContextSegmentEmpty}, `[a::b] text`)
+	f(t, tik.ParseError{Index: 0, Err: tik.ErrContextTooLong},
+		`[a context that is too long] text`)
+	f(t, tik.ParseError{Index: 9, Err: tik.ErrContextNoSeparator}, `[context]text`)
+	f(t, tik.ParseError{Index: 10, Err: tik.ErrTextEmpty}, `[context] `)
+}
+
+func TestParseMaxContextLen(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.MaxContextLen = 8
+	p := tik.NewParser(conf)
+
+	f := func(t *testing.T, expectContext, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expectContext, tk.Context())
+	}
+	fErr := func(t *testing.T, expect error, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireDeepEqual(t, error(tik.ParseError{Index: 0, Err: expect}), err)
+		requireDeepEqual(t, tik.TIK{}, tk)
+	}
+
+	f(t, "12345678", `[12345678] text`)
+	f(t, "ÄÄÄÄ", `[ÄÄÄÄ] text`)
+	f(t, "1234]67", `[1234\]67] text`)
+
+	fErr(t, tik.ErrContextTooLong, `[123456789] text`)
+	fErr(t, tik.ErrContextTooLong, `[ÄÄÄÄÄ] text`)
+	fErr(t, tik.ErrContextTooLong, `[1234\]678] text`)
+	fErr(t, tik.ErrContextTooLong, "["+strings.Repeat("a", 1<<20)+" text")
+	fErr(t, tik.ErrContextTooLong, "["+strings.Repeat("a", 1<<20)+"] text")
+	fErr(t, tik.ErrContextUnclosed, `[1234 text`)
+	fErr(t, tik.ErrContextUnclosed, `[12345678`)
+
+	// Zero is unlimited.
+	tk, err := tik.NewParser(tik.DefaultConfig).Parse(
+		"[" + strings.Repeat("a", 1<<16) + "] text")
+	requireNoErr(t, err)
+	requireEqual(t, 1<<16, len(tk.Context()))
+}
+
+func TestParseUnknownPlaceholderAsLiteral(t *testing.T) {
+	t.Parallel()
+
+	const input = `Hello {user_name}, you have {# {count} messages}`
+
+	// Unknown placeholders are rejected by default.
+	_, err := tik.NewParser(tik.DefaultConfig).Parse(input)
+	requireDeepEqual(t, error(tik.ParseError{
+		Index: 6, Err: tik.ErrUnknownPlaceholder,
+	}), err)
+
+	conf := tik.DefaultConfig
+	conf.UnknownPlaceholderAsLiteral = true
+	p := tik.NewParser(conf)
+
+	f := func(t *testing.T, input, expectICU string, expectUnknown []string, expect ...Token) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireDeepEqual(t, expect, ToTestTokens(tk.Raw, tk.Tokens))
+		requireEqual(t, expectICU, tik.NewICUTranslator(conf).TIK2ICU(tk))
+		var unknown []string
+		for _, tok := range tk.UnknownPlaceholders() {
+			unknown = append(unknown, tok.String(tk.Raw))
+		}
+		requireDeepEqual(t, expectUnknown, unknown)
+	}
+
+	f(t, input, "Hello '{'user_name'}', you have "+
+		"{var0, plural, other {# '{'count'}' messages}}",
+		[]string{"{user_name}", "{count}"},
+		Token{"Hello ", tik.TokenTypeLiteral},
+		Token{"{user_name}", tik.TokenTypeLiteral},
+		Token{", you have ", tik.TokenTypeLiteral},
+		Token{"{#", tik.TokenTypeCardinalPluralStart},
+		Token{" ", tik.TokenTypeLiteral},
+		Token{"{count}", tik.TokenTypeLiteral},
+		Token{" messages", tik.TokenTypeLiteral},
+		Token{"}", tik.TokenTypeCardinalPluralEnd},
+	)
+	f(t, `{0}{text}`, "'{'0'}'{var0}", []string{"{0}"},
+		Token{"{0}", tik.TokenTypeLiteral},
+		Token{"{text}", tik.TokenTypeText},
+	)
+	f(t, `{text} \{escaped\}`, "{var0} '{'escaped'}'", nil,
+		Token{"{text}", tik.TokenTypeText},
+		Token{` {escaped}`, tik.TokenTypeLiteral},
+	)
+
+	// Other errors still apply.
+	for _, input := range []string{`{unclosed`, `{text # }`, `{bool:x}`} {
+		if _, err := p.Parse(input); err == nil {
+			t.Fatalf("expected error for %q", input)
+		}
+	}
+}
+
+func TestParseDisallowContext(t *testing.T) {
+	t.Parallel()
+
+	const input = `[not a context] text {text}`
+
+	tk, err := tik.NewParser(tik.DefaultConfig).Parse(input)
+	requireNoErr(t, err)
+	requireEqual(t, "not a context", tk.Context())
+
+	conf := tik.DefaultConfig
+	conf.DisallowContext = true
+	p := tik.NewParser(conf)
+
+	f := func(t *testing.T, input string, expect ...Token) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireDeepEqual(t, expect, ToTestTokens(tk.Raw, tk.Tokens))
+		requireEqual(t, "", tk.Context())
+	}
+
+	f(t, input,
+		Token{"[not a context] text ", tik.TokenTypeLiteral},
+		Token{"{text}", tik.TokenTypeText},
+	)
+	f(t, `  [x]`, Token{"[x]", tik.TokenTypeLiteral})
+	f(t, `[x]text`, Token{"[x]text", tik.TokenTypeLiteral})
+	f(t, `[`, Token{"[", tik.TokenTypeLiteral})
+
+	// Errors of contexts don't apply.
+	_, err = p.Parse(`[] text`)
+	requireNoErr(t, err)
+
+	tk, err = p.Parse(input)
+	requireNoErr(t, err)
+	requireEqual(t, "[not a context] text {var0}",
+		tik.NewICUTranslator(conf).TIK2ICU(tk))
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, expect error, modify func(c *tik.Config)) {
+		t.Helper()
+		c := tik.DefaultConfig
+		modify(&c)
+		requireErrIs(t, expect, c.Validate())
+	}
+
+	f(t, nil, func(c *tik.Config) {})
+	f(t, nil, func(c *tik.Config) { c.ContextBrackets = [2]rune{} })
+	f(t, nil, func(c *tik.Config) { c.ContextBrackets = [2]rune{'（', '）'} })
+	f(t, tik.ErrConfigContextBrackets,
+		func(c *tik.Config) { c.ContextBrackets = [2]rune{'{', '}'} })
+	f(t, tik.ErrConfigContextBrackets,
+		func(c *tik.Config) { c.ContextBrackets = [2]rune{'\\', ']'} })
+	f(t, tik.ErrConfigContextBrackets,
+		func(c *tik.Config) { c.ContextBrackets = [2]rune{'|', '|'} })
+	f(t, tik.ErrConfigContextBrackets,
+		func(c *tik.Config) { c.ContextBrackets = [2]rune{' ', ']'} })
+	f(t, tik.ErrConfigContextBrackets,
+		func(c *tik.Config) { c.ContextBrackets = [2]rune{'[', 0} })
+	f(t, nil, func(c *tik.Config) { c.ICUVarPrefix = "" })
+	f(t, nil, func(c *tik.Config) { c.ICUVarPrefix = "arg" })
+	f(t, nil, func(c *tik.Config) { c.ICUVarPrefix = "_" })
+	f(t, nil, func(c *tik.Config) { c.ICUVarPrefix = "p_1x" })
+	f(t, tik.ErrConfigICUVarPrefix, func(c *tik.Config) { c.ICUVarPrefix = "0" })
+	f(t, tik.ErrConfigICUVarPrefix, func(c *tik.Config) { c.ICUVarPrefix = "a-b" })
+	f(t, tik.ErrConfigICUVarPrefix, func(c *tik.Config) { c.ICUVarPrefix = "a b" })
+	f(t, tik.ErrConfigICUVarPrefix, func(c *tik.Config) { c.ICUVarPrefix = "a}" })
+	f(t, nil, func(c *tik.Config) { c.CardinalPluralNumberSign = "" })
+	f(t, nil, func(c *tik.Config) { c.CardinalPluralNumberSign = "№" })
+	f(t, nil, func(c *tik.Config) { c.CardinalPluralNumberSign = "count" })
+	f(t, tik.ErrConfigPluralSign, func(c *tik.Config) { c.CardinalPluralNumberSign = "n" })
+	f(t, tik.ErrConfigPluralSign, func(c *tik.Config) { c.CardinalPluralNumberSign = "date" })
+	f(t, tik.ErrConfigPluralSign, func(c *tik.Config) { c.CardinalPluralNumberSign = "a b" })
+	f(t, tik.ErrConfigPluralSign, func(c *tik.Config) { c.CardinalPluralNumberSign = "@" })
+	f(t, tik.ErrConfigPluralSign, func(c *tik.Config) { c.CardinalPluralNumberSign = "{" })
+	f(t, tik.ErrConfigPluralSign, func(c *tik.Config) { c.CardinalPluralNumberSign = "\xff" })
+	f(t, nil, func(c *tik.Config) { c.MarkupTags = []string{"a", "b", "h1", "x-y"} })
+	f(t, tik.ErrConfigMarkupTags, func(c *tik.Config) { c.MarkupTags = []string{""} })
+	f(t, tik.ErrConfigMarkupTags, func(c *tik.Config) { c.MarkupTags = []string{"1a"} })
+	f(t, tik.ErrConfigMarkupTags, func(c *tik.Config) { c.MarkupTags = []string{"a>"} })
+	f(t, nil, func(c *tik.Config) { c.GenderCategories = nil })
+	f(t, nil, func(c *tik.Config) {
+		c.GenderCategories = []string{"animate", "inanimate", "other"}
+	})
+	f(t, nil, func(c *tik.Config) {
+		c.GenderCategories = []string{"masculine", "feminine", "neuter", "other"}
+	})
+	f(t, tik.ErrConfigGender, func(c *tik.Config) { c.GenderCategories = []string{"male"} })
+	f(t, tik.ErrConfigGender, func(c *tik.Config) {
+		c.GenderCategories = []string{"male", "other", "male"}
+	})
+	f(t, tik.ErrConfigGender, func(c *tik.Config) {
+		c.GenderCategories = []string{"other", "non binary"}
+	})
+	f(t, nil, func(c *tik.Config) { c.TimeZoneSkeleton = "" })
+	f(t, nil, func(c *tik.Config) { c.TimeZoneSkeleton = "z" })
+	f(t, nil, func(c *tik.Config) { c.TimeZoneSkeleton = "vvvv" })
+	f(t, tik.ErrConfigTimeZone, func(c *tik.Config) { c.TimeZoneSkeleton = "zz" })
+	f(t, tik.ErrConfigTimeZone, func(c *tik.Config) { c.TimeZoneSkeleton = "VVVV" })
+}
+
+func TestTokenizeErrMsg(t *testing.T) {
+	t.Parallel()
+
+	parser := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, input string, expectErrMsg string) {
+		t.Helper()
+		tk, err := parser.Parse(input)
+		requireEqual(t, expectErrMsg, err.Error())
+		requireDeepEqual(t, tik.TIK{}, tk)
+	}
+
+	// String literal only.
+	f(t, "hello world {", "at index 12: unclosed placeholder")
+	f(t, "{unknown}", "at index 0: unknown placeholder")
+	f(t, "{# messages in {# folders}}", "at index 15: nested pluralization")
+}
+
+func TestTIKContext(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, expect string, conf tik.Config, input string) {
+		t.Helper()
+		tk, err := tik.NewParser(conf).Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expect, tk.Context())
+	}
+
+	f(t, "", tik.DefaultConfig, `no context`)
+	f(t, "context", tik.DefaultConfig, `[context] text`)
+	f(t, " с пробелами ", tik.DefaultConfig, `[ с пробелами ] text`)
+
+	conf := tik.DefaultConfig
+	conf.ContextBrackets = [2]rune{'【', '】'}
+	f(t, "文脈", conf, `【文脈】 テキスト`)
+	f(t, "【文脈】", conf, `【\【文脈\】】 テキスト`)
+
+	// Escaped brackets, braces and reverse solidus.
+	f(t, "array[i] label", tik.DefaultConfig, `[array\[i\] label] text`)
+	f(t, "use {placeholder}", tik.DefaultConfig, `[use \{placeholder\}] text`)
+	f(t, `back\slash`, tik.DefaultConfig, `[back\\slash] text`)
+	f(t, `trailing\`, tik.DefaultConfig, `[trailing\\] text`)
+}
+
+func TestTIKContextSegments(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, expect []string, input string) {
+		t.Helper()
+		tk, err := tik.NewParser(tik.DefaultConfig).Parse(input)
+		requireNoErr(t, err)
+		requireDeepEqual(t, expect, tk.ContextSegments())
+	}
+
+	f(t, nil, `no context`)
+	f(t, []string{"button"}, `[button] text`)
+	f(t, []string{"page", "checkout", "button"}, `[page:checkout/button] text`)
+	f(t, []string{"page", "checkout"}, `[ page : checkout ] text`)
+	f(t, []string{"a[0]", "b"}, `[a\[0\]/b] text`)
+	// Blank segments are kept unless Config.StrictContextSegments is set.
+	f(t, []string{"https", "", "", "example.com"}, `[https://example.com] text`)
+	f(t, []string{"page", ""}, `[page/] text`)
+}
+
+func TestParseStrictContextSegments(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.StrictContextSegments = true
+	p := tik.NewParser(conf)
+
+	tk, err := p.Parse(`[page:checkout/button] text`)
+	requireNoErr(t, err)
+	requireEqual(t, "page:checkout/button", tk.Context())
+
+	f := func(t *testing.T, input string) {
+		t.Helper()
+		_, err := p.Parse(input)
+		requireDeepEqual(t, error(tik.ParseError{
+			Index: 0, Err: tik.ErrContextSegmentEmpty,
+		}), err)
+	}
+
+	f(t, `[page::button] text`)
+	f(t, `[page/ /button] text`)
+	f(t, `[:page] text`)
+	f(t, `[page/] text`)
+	f(t, `[/] text`)
+}
+
+func TestTIKPlaceholdersIter(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+
+	tk, err := p.Parse(`[context]
+		{date-full}
+		{date-long}
+		{date-medium}
+		{date-short}
+		{time-short}
+		{time-medium}
+		{time-long}
+		{time-full}
+		{currency}
+		{# messages}{ordinal}`)
+	requireNoErr(t, err)
+	expect := []tik.Token{
+		// 0 is a context.
+		tk.Tokens[1],
+		// 2 is a string literal.
+		tk.Tokens[3],
+		// 4 is a string literal.
+		tk.Tokens[5],
+		// 6 is a string literal.
+		tk.Tokens[7],
+		// 8 is a string literal.
+		tk.Tokens[9],
+		// 10 is a string literal.
+		tk.Tokens[11],
+		// 12 is a string literal.
+		tk.Tokens[13],
+		// 14 is a string literal.
+		tk.Tokens[15],
+		// 16 is a string literal.
+		tk.Tokens[17],
+		// 18 is a string literal.
+		tk.Tokens[19],
+		// 20 is a string literal.
+		// 21 is a cardinal plural end.
+		tk.Tokens[22],
+	}
+
+	var actual []tik.Token
+	for i, tok := range tk.Placeholders() {
+		requireEqual(t, i, len(actual))
+		actual = append(actual, tok)
+	}
+
+	requireDeepEqual(t, expect, actual)
+
+	// Test break
+	{
+		counter := 0
+		for range tk.Placeholders() {
+			counter++
+			break
+		}
+		requireEqual(t, 1, counter)
+	}
+}
+
+func TestTIKIsPlural(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.AllowNestedPlural = true
+	p := tik.NewParser(conf)
+
+	f := func(t *testing.T, expectPlural bool, expectCount int, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expectPlural, tk.IsPlural())
+		requireEqual(t, expectCount, tk.PluralCount())
+	}
+
+	f(t, false, 0, `Hello {name}`)
+	f(t, false, 0, `Sent {number} of {integer}`)
+	f(t, true, 0, `You're {ordinal}`)
+	f(t, true, 1, `You have {# messages}`)
+	f(t, true, 1, `{# |=0 nothing | items} by {ordinal:º}`)
+	f(t, true, 2, `{# files} in {# folders}`)
+	f(t, true, 2, `{# files in {# folders}}`)
+}
+
+func TestTIKComplexity(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.AllowNestedPlural = true
+	p := tik.NewParser(conf)
+
+	f := func(t *testing.T, expect int, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expect, tk.Complexity())
+	}
+
+	f(t, 0, `Hello`)
+	f(t, 0, `[context] Hello {@greeting}`)
+	f(t, 2, `Hello {name}`)
+	f(t, 3, `{text} and {text}`)
+	f(t, 4, `{text} and {name}`)
+	f(t, 4, `You have {# messages}`)
+	f(t, 6, `{# messages} from {text}`)
+	f(t, 7, `{# files in {# folders}}`)
+	f(t, 11, `{name} sent {# files in {# folders}} on {date-medium}`)
+}
+
+func TestParseMaxComplexity(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.MaxComplexity = 4
+	p := tik.NewParser(conf)
+
+	f := func(t *testing.T, expect error, expectIndex int, input string) {
+		t.Helper()
+		_, err := p.Parse(input)
+		requireErrIs(t, expect, err)
+		if expect != nil {
+			requireEqual(t, expectIndex, err.(tik.ParseError).Index)
+		}
+	}
+
+	f(t, nil, 0, `Hello`)
+	f(t, nil, 0, `{text} and {name}`)
+	f(t, nil, 0, `You have {# messages}`)
+	f(t, tik.ErrTooComplex, 18, `{# messages} from {text}`)
+	f(t, tik.ErrTooComplex, 27, `{text}, {text}, {text} and {text}`)
+	f(t, tik.ErrTooComplex, 7, `{text} {# messages}`)
+}
+
+func TestParseMaxPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.MaxPlaceholders = 2
+	p := tik.NewParser(conf)
+
+	f := func(t *testing.T, expect error, expectIndex int, input string) {
+		t.Helper()
+		_, err := p.Parse(input)
+		requireErrIs(t, expect, err)
+		if expect != nil {
+			requireEqual(t, expectIndex, err.(tik.ParseError).Index)
+		}
+	}
+
+	f(t, nil, 0, `Hello {@greeting}`)
+	f(t, nil, 0, `{text} and {name}`)
+	f(t, nil, 0, `{# messages from {name}}`)
+	f(t, tik.ErrTooManyPlaceholders, 18, `{text} and {name} {integer}`)
+	f(t, tik.ErrTooManyPlaceholders, 19, `{text} and {name}, {# messages}`)
+	f(t, tik.ErrTooManyPlaceholders, 24, `{# messages from {name} {text}}`)
+}
+
+func TestTIKLiteralsIter(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.MarkupTags = []string{"b"}
+	p := tik.NewParser(conf)
+
+	tk, err := p.Parse(`[context] Hello {name}, you have {# |=0 no | new} messages{<b>}!{</b>}`)
+	requireNoErr(t, err)
+	expect := []tik.Token{
+		// 0 is a context.
+		tk.Tokens[1], // "Hello "
+		// 2 is a text with gender placeholder.
+		tk.Tokens[3], // ", you have "
+		// 4 is a cardinal plural start.
+		// 5 is a cardinal plural exact match.
+		tk.Tokens[6], // "no"
+		// 7 is a cardinal plural other arm.
+		tk.Tokens[8], // " new"
+		// 9 is a cardinal plural end.
+		tk.Tokens[10], // " messages"
+		// 11 is a markup tag.
+		tk.Tokens[12], // "!"
+		// 13 is a markup tag.
+	}
+
+	var actual []tik.Token
+	for i, tok := range tk.Literals() {
+		requireEqual(t, i, len(actual))
+		requireEqual(t, tik.TokenTypeLiteral, tok.Type)
+		actual = append(actual, tok)
+	}
+
+	requireDeepEqual(t, expect, actual)
+	requireEqual(t, tk.Tokens.LiteralCount(), len(actual))
+
+	// Test break
+	{
+		counter := 0
+		for range tk.Literals() {
+			counter++
+			break
+		}
+		requireEqual(t, 1, counter)
+	}
+}
+
+func TestTIKSurface(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.MarkupTags = []string{"b"}
+	p := tik.NewParser(conf)
+
+	f := func(t *testing.T, expect, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expect, tk.Surface())
+	}
+
+	f(t, "Hello", `Hello`)
+	f(t, "Hello ⟦0⟧, you have ⟦1⟧ messages",
+		`[inbox] Hello {name}, you have {# messages}`)
+	f(t, "⟦0⟧ {x} on ⟦1⟧ by ⟦2⟧", `{text} \{x\} on {date-short} by {"John"}`)
+	f(t, "⟦0⟧ no one new messages", `{# |=0 no |=1 one | new} messages`)
+	f(t, "⟦0⟧ nobody and # others liked it",
+		`{# offset:1 |=0 nobody | and # others} liked it`)
+	f(t, "⟦0⟧件", `{#件}`)
+	f(t, "Price ⟦0⟧ in ⟦2⟧", `Price {number-range} in {currency:EUR}`)
+	f(t, "See  now, bold", `See {@help} now, {<b>}bold{</b>}`)
+}
+
+func TestTokenDirective(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, conf tik.Config, input string, expect ...string) {
+		t.Helper()
+		tk, err := tik.NewParser(conf).Parse(input)
+		requireNoErr(t, err)
+		var actual []string
+		for _, tok := range tk.Tokens {
+			actual = append(actual, tok.Directive(tk.Raw))
+		}
+		requireDeepEqual(t, expect, actual)
+	}
+
+	conf := tik.DefaultConfig
+	f(t, conf, `[ctx] on {date-short # due date}, {text}`,
+		"", "", "date-short", "", "text")
+	f(t, conf, `{# |=0 none | files} {number-range} {ordinal:º}`,
+		"#", "", "", "", "", "", "", "number-range", "", "ordinal:º")
+	f(t, conf, `{# offset:1 |=0 you | you and # others}`,
+		"#", "", "", "", "", "", "")
+	f(t, conf, `{currency:EUR}{bool:on/off}{select:s(a,b)}{"John # Doe"}`,
+		"currency:EUR", "bool:on/off", "select:s(a,b)", `"John # Doe"`)
+	f(t, conf, `{"John" # hint}{@help.intro}{icu:{var, number, ::percent}}`,
+		`"John"`, "@help.intro", "icu:{var, number, ::percent}")
+	f(t, conf, `{"5\" \{wide\}" # size}`, `"5" {wide}"`)
+
+	conf.MarkupTags = []string{"a", "br"}
+	f(t, conf, `{<a>}x{</a>}{<br/>}`, "<a>", "", "</a>", "<br/>")
+
+	conf = tik.DefaultConfig
+	conf.CardinalPluralNumberSign = "%"
+	conf.CaseInsensitiveKeywords = true
+	conf.NamedPlaceholders = true
+	f(t, conf, `{% files} {Date-Short} {Text:user # the user}`,
+		"#", "", "", "", "date-short", "", "text:user")
+}
+
+func TestTokensCount(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	reVar := regexp.MustCompile(`\bvar(\d+)\b`)
+
+	f := func(t *testing.T, expectPlaceholders, expectLiterals int, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expectPlaceholders, tk.Tokens.PlaceholderCount())
+		requireEqual(t, expectLiterals, tk.Tokens.LiteralCount())
+
+		// Cross-check with the positional arguments of the ICU message.
+		maxIndex := -1
+		for _, m := range reVar.FindAllStringSubmatch(translator.TIK2ICU(tk), -1) {
+			i, err := strconv.Atoi(m[1])
+			requireNoErr(t, err)
+			maxIndex = max(maxIndex, i)
+		}
+		requireEqual(t, maxIndex+1, tk.Tokens.PlaceholderCount())
+	}
+
+	f(t, 0, 1, `hello world`)
+	f(t, 0, 1, `[context] hello world`)
+	f(t, 0, 2, `see {@help} now`)
+	f(t, 1, 1, `hello {text}`)
+	f(t, 1, 2, `You have {# messages}`)
+	f(t, 2, 3, `{# |=0 none |=1 one | by {name}}`)
+	f(t, 3, 4, `[ctx] {name} has {# offset:1 |=0 nobody | others} on {date-short}`)
+	f(t, 4, 0, `{integer}{currency:EUR}{bool:on/off}{ordinal}`)
+}
+
+func TestTokensPluralEnd(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.AllowNestedPlural = true
+	p := tik.NewParser(conf)
+
+	f := func(t *testing.T, input string, startEnd ...int) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		for i := 0; i < len(startEnd); i += 2 {
+			end, ok := tk.Tokens.PluralEnd(startEnd[i])
+			requireEqual(t, true, ok)
+			requireEqual(t, startEnd[i+1], end)
+		}
+	}
+
+	f(t, `You have {# messages}`, 1, 3)
+	f(t, `{# |=0 none | files}`, 0, 5)
+	f(t, `{# files}{# folders}`, 0, 2, 3, 5)
+	f(t, `{# files} and {# folders}`, 0, 2, 4, 6)
+	f(t, `{# files in {# folders} by {text}}`, 0, 7, 2, 4)
+	f(t, `{# |=0 {# folders} | files}!`, 0, 7, 2, 4)
+
+	tk, err := p.Parse(`{text} {# files}`)
+	requireNoErr(t, err)
+	for _, start := range []int{-1, 0, 1, 3, 4, 5} {
+		end, ok := tk.Tokens.PluralEnd(start)
+		requireEqual(t, false, ok)
+		requireEqual(t, 0, end)
+	}
+
+	// Unclosed.
+	end, ok := tk.Tokens[:4].PluralEnd(2)
+	requireEqual(t, false, ok)
+	requireEqual(t, 0, end)
+}
+
+func TestTokensPluralBody(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.AllowNestedPlural = true
+	p := tik.NewParser(conf)
+
+	tk, err := p.Parse(`{# messages from {text}}`)
+	requireNoErr(t, err)
+	body, ok := tk.Tokens.PluralBody(0)
+	requireEqual(t, true, ok)
+	requireDeepEqual(t, []tik.Token{
+		{IndexStart: 2, IndexEnd: 17, Type: tik.TokenTypeLiteral},
+		{IndexStart: 17, IndexEnd: 23, Type: tik.TokenTypeText},
+	}, []tik.Token(body))
+	requireEqual(t, 1, body.PlaceholderCount())
+
+	tk, err = p.Parse(`{text}: {# offset:1 |=0 none | files {# in folders} by {integer}}`)
+	requireNoErr(t, err)
+	body, ok = tk.Tokens.PluralBody(2)
+	requireEqual(t, true, ok)
+	requireEqual(t, tik.TokenTypePluralOffset, body[0].Type)
+	requireEqual(t, 2, body.PlaceholderCount())
+	body, ok = tk.Tokens.PluralBody(8)
+	requireEqual(t, true, ok)
+	requireEqual(t, 1, len(body))
+
+	for _, start := range []int{-1, 0, 1, 3, len(tk.Tokens)} {
+		body, ok := tk.Tokens.PluralBody(start)
+		requireEqual(t, false, ok)
+		requireEqual(t, 0, len(body))
+	}
+}
+
+func TestTIKEqual(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect bool, a, b string) {
+		t.Helper()
+		ta, err := p.Parse(a)
+		requireNoErr(t, err)
+		ta.Tokens = slices.Clone(ta.Tokens)
+		tb, err := p.Parse(b)
+		requireNoErr(t, err)
+		requireEqual(t, expect, ta.Equal(tb))
+		requireEqual(t, expect, tb.Equal(ta))
+	}
+
+	f(t, true, `hello {text}`, `hello {text}`)
+	f(t, true, `hello {text}`, `hello {text}  `)
+	f(t, true, "hello\r\n{text}", "hello\n{text}")
+	f(t, true, "hello  \t world", "hello world")
+	f(t, true, `[ctx] hello`, `[ctx]  hello`)
+	f(t, true, `hello {text # the name}`, `hello {text}`)
+	f(t, true, `\{a\}`, `\{a\}`)
+	f(t, true, `{# |=0 none | items}`, `{#  |=0 none |  items}`)
+	f(t, false, `hello {text}`, `hello {name}`)
+	f(t, false, `{text} {integer}`, `{integer} {text}`)
+	f(t, false, `hello {text}`, `hello {text} {text}`)
+	f(t, false, `hello {text}`, `hello{text}`)
+	f(t, false, `hello`, `Hello`)
+	f(t, false, `[a] hello`, `[b] hello`)
+	f(t, false, `[a] hello`, `hello`)
+	f(t, false, `{bool:on/off}`, `{bool:yes/no}`)
+	f(t, false, `{# |=0 none | items}`, `{# |=1 none | items}`)
+	f(t, false, `see {@a}`, `see {@b}`)
+}
+
+func TestTIKArgs(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+
+	tk, err := p.Parse(`[ctx] {name} had {# |=0 no {text} | messages} ` +
+		`on {date-short}, see {@help}`)
+	requireNoErr(t, err)
+	requireDeepEqual(t, []tik.Arg{
+		{Index: 0, Type: tik.TokenTypeTextWithGender, Kind: tik.FormatKindTextWithGender},
+		{Index: 1, Type: tik.TokenTypeCardinalPluralStart},
+		{Index: 2, Type: tik.TokenTypeText, Kind: tik.FormatKindText},
+		{Index: 3, Type: tik.TokenTypeDateShort, Kind: tik.FormatKindDateShort},
+	}, tk.Args())
+	requireEqual(t, "var3", tk.Args()[3].Name())
+
+	tk, err = p.Parse(`{text} is {select:status(pending,shipped)}`)
+	requireNoErr(t, err)
+	requireEqual(t, "var0", tk.Args()[0].Name())
+	requireEqual(t, "status", tk.Args()[1].Name())
+	requireEqual(t, tik.TokenTypeSelect, tk.Args()[1].Type)
+
+	tk, err = p.Parse(`no arguments`)
+	requireNoErr(t, err)
+	requireEqual(t, 0, len(tk.Args()))
+}
+
+func TestTIKArgOrder(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, expect []int, conf tik.Config, input string) {
+		t.Helper()
+		tk, err := tik.NewParser(conf).Parse(input)
+		requireNoErr(t, err)
+		order := tk.ArgOrder()
+		requireDeepEqual(t, expect, order)
+
+		// The order must match the arguments of the generated ICU message.
+		icu := tik.NewICUTranslator(conf).TIK2ICU(tk)
+		i := 0
+		for _, p := range tk.Tokens {
+			if !p.Type.IsPlaceholder() {
+				continue
+			}
+			index := order[i]
+			i++
+			name := "{var" + strconv.Itoa(index)
+			switch p.Type {
+			case tik.TokenTypeSkip:
+				if strings.Contains(icu, name+"}") || strings.Contains(icu, name+",") {
+					t.Fatalf("skipped argument %d found in %q", index, icu)
+				}
+				continue
+			case tik.TokenTypeSelect:
+				name = "{" + tk.Args()[index].Name()
+			}
+			if !strings.Contains(icu, name+"}") && !strings.Contains(icu, name+",") {
+				t.Fatalf("argument %d not found in %q", index, icu)
+			}
+		}
+	}
+
+	named := tik.DefaultConfig
+	named.NamedPlaceholders = true
+
+	f(t, []int(nil), tik.DefaultConfig, `no arguments, see {@help}`)
+	f(t, []int{0, 1, 2, 3}, tik.DefaultConfig,
+		`[ctx] {name} had {# |=0 no {text} | messages} on {date-short}`)
+	f(t, []int{0, 1, 3}, tik.DefaultConfig, `{text} {number-range} {date-short}`)
+	f(t, []int{0, 1, 0}, named, `{text:user} and {name} met {text:user}`)
+	f(t, []int{0, 0, 1, 2, 0, 2}, named,
+		`{text:user}{text:user} {# |=0 {date-short:d} | by {text:user}} {date-short:d}`)
+	f(t, []int{0, 1, 2}, tik.DefaultConfig, `{skip}{select:s(a,b)} {text}`)
+	f(t, []int{0, 1, 2, 4}, tik.DefaultConfig,
+		`{text} {skip} {number-range} {select:status(on)}`)
+	f(t, []int{0, 1, 0, 3}, tik.DefaultConfig,
+		`{select:s(a,b)} {number-range} {select:s(a,b)} {text}`)
+}
+
+func TestReusedPlaceholderName(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.NamedPlaceholders = true
+	tk, err := tik.NewParser(conf).Parse(
+		`{text:user # who} and {name} met {text:user # ignored} ` +
+			`{# |=0 none | by {text:user} and {integer}}`)
+	requireNoErr(t, err)
+
+	translator := tik.NewICUTranslator(conf)
+	requireEqual(t, "{var0} and {var1} met {var0} "+
+		"{var2, plural, =0 {none} other {# by {var0} and {var3, number, integer}}}",
+		translator.TIK2ICU(tk))
+	requireEqual(t, 4, tk.Tokens.PlaceholderCount())
+	requireEqual(t, "⟦0⟧ and ⟦1⟧ met ⟦0⟧ ⟦2⟧ none by ⟦0⟧ and ⟦3⟧", tk.Surface())
+	requireDeepEqual(t, map[string]string{"var0": "who"}, translator.ArgHints(tk))
+
+	args := tk.Args()
+	requireEqual(t, 4, len(args))
+	for i, a := range args {
+		requireEqual(t, i, a.Index)
+	}
+	requireEqual(t, tik.TokenTypeText, args[0].Type)
+	requireEqual(t, tik.TokenTypeTextWithGender, args[1].Type)
+	requireEqual(t, tik.TokenTypeCardinalPluralStart, args[2].Type)
+	requireEqual(t, tik.TokenTypeInteger, args[3].Type)
+
+	requireNoErr(t, tk.CheckArgs("Alice", "Bob", 2, 3))
+	requireErrIs(t, tik.ErrArgCount, tk.CheckArgs("Alice", "Bob", "Alice", 2, 3))
+}
+
+func TestParserClone(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	c := p.Clone()
+
+	err := p.ParseFn(`{# messages}`, func(a tik.TIK) {
+		err := c.ParseFn(`hello {text}`, func(b tik.TIK) {
+			requireEqual(t, tik.TokenTypeLiteral, b.Tokens[0].Type)
+		})
+		requireNoErr(t, err.Err)
+		// The clone must not have overwritten the original buffer.
+		requireEqual(t, tik.TokenTypeCardinalPluralStart, a.Tokens[0].Type)
+	})
+	requireNoErr(t, err.Err)
+}
+
+func TestParserParseBatch(t *testing.T) {
+	t.Parallel()
+
+	inputs := []string{
+		`hello {text}`,
+		`{# messages}`,
+		`{unknown}`,
+		`[context] {name}`,
+	}
+
+	p := tik.NewParser(tik.DefaultConfig)
+	var indexes []int
+	p.ParseBatch(inputs, func(i int, tk tik.TIK, err tik.ParseError) bool {
+		indexes = append(indexes, i)
+		expect, expectErr := tik.NewParser(tik.DefaultConfig).Parse(inputs[i])
+		if expectErr != nil {
+			requireDeepEqual(t, expectErr, error(err))
+			requireDeepEqual(t, tik.TIK{}, tk)
+			return true
+		}
+		requireNoErr(t, err.Err)
+		requireDeepEqual(t, expect, tk)
+		return true
+	})
+	requireDeepEqual(t, []int{0, 1, 2, 3}, indexes)
+
+	// Stop early.
+	indexes = indexes[:0]
+	p.ParseBatch(inputs, func(i int, _ tik.TIK, err tik.ParseError) bool {
+		indexes = append(indexes, i)
+		return err.Err == nil
+	})
+	requireDeepEqual(t, []int{0, 1, 2}, indexes)
+}
+
+func TestParserPoolConcurrent(t *testing.T) {
+	t.Parallel()
+
+	pool := tik.NewParserPool(tik.DefaultConfig)
+	inputs := []string{
+		`hello world`,
+		`[context] {name} had {# messages} on {date-medium}`,
+		`{text} {text}, {text}{text}`,
+		`You're {ordinal} out of {# contenders}`,
+	}
+	expect := make([]tik.TIK, len(inputs))
+	for i, input := range inputs {
+		var err error
+		expect[i], err = tik.NewParser(tik.DefaultConfig).Parse(input)
+		requireNoErr(t, err)
+	}
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 200 {
+				n := (g + i) % len(inputs)
+				actual, err := pool.Parse(inputs[n])
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if !reflect.DeepEqual(expect[n], actual) {
+					t.Errorf("\nexpected: %#v;\nreceived: %#v", expect[n], actual)
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestTokenType_String(t *testing.T) {
+	f := func(t *testing.T, expect string, value tik.TokenType) {
+		t.Helper()
+		requireDeepEqual(t, expect, value.String())
+	}
+
+	f(t, `unknown`, 0)
+	f(t, `unknown`, 127)
+	f(t, `context`, tik.TokenTypeContext)
+	f(t, `literal`, tik.TokenTypeLiteral)
+	f(t, `text`, tik.TokenTypeText)
+	f(t, `text with gender`, tik.TokenTypeTextWithGender)
+	f(t, `integer`, tik.TokenTypeInteger)
+	f(t, `number`, tik.TokenTypeNumber)
+	f(t, `pluralization`, tik.TokenTypeCardinalPluralStart)
+	f(t, `pluralization block end`, tik.TokenTypeCardinalPluralEnd)
+	f(t, `ordinal plural`, tik.TokenTypeOrdinalPlural)
+	f(t, `date full`, tik.TokenTypeDateFull)
+	f(t, `date long`, tik.TokenTypeDateLong)
+	f(t, `date medium`, tik.TokenTypeDateMedium)
+	f(t, `date short`, tik.TokenTypeDateShort)
+	f(t, `time full`, tik.TokenTypeTimeFull)
+	f(t, `time long`, tik.TokenTypeTimeLong)
+	f(t, `time medium`, tik.TokenTypeTimeMedium)
+	f(t, `time short`, tik.TokenTypeTimeShort)
+	f(t, `currency`, tik.TokenTypeCurrency)
+	f(t, `message reference`, tik.TokenTypeMessageRef)
+	f(t, `pluralization offset`, tik.TokenTypePluralOffset)
+	f(t, `pluralization exact match`, tik.TokenTypePluralExactMatch)
+	f(t, `pluralization other`, tik.TokenTypePluralOther)
+	f(t, `boolean`, tik.TokenTypeBoolean)
+	f(t, `select`, tik.TokenTypeSelect)
+	f(t, `string placeholder`, tik.TokenTypeStringPlaceholder)
+	f(t, `time zone`, tik.TokenTypeTimeZone)
+	f(t, `relative time`, tik.TokenTypeRelativeTime)
+	f(t, `number spellout`, tik.TokenTypeNumberSpellout)
+	f(t, `tag open`, tik.TokenTypeTagOpen)
+	f(t, `tag close`, tik.TokenTypeTagClose)
+	f(t, `tag self-closing`, tik.TokenTypeTagSelfClosing)
+	f(t, `raw ICU`, tik.TokenTypeRawICU)
+	f(t, `raw ICU argument`, tik.TokenTypeRawICUArgument)
+	f(t, `week of year`, tik.TokenTypeWeekOfYear)
+	f(t, `quarter`, tik.TokenTypeQuarter)
+	f(t, `number range`, tik.TokenTypeNumberRange)
+	f(t, `phone`, tik.TokenTypePhone)
+	f(t, `email`, tik.TokenTypeEmail)
+	f(t, `url`, tik.TokenTypeURL)
+	f(t, `unit`, tik.TokenTypeUnit)
+	f(t, `permille`, tik.TokenTypePermille)
+	f(t, `skip`, tik.TokenTypeSkip)
+	f(t, `date time`, tik.TokenTypeDateTime)
+	f(t, `number skeleton`, tik.TokenTypeNumberSkeleton)
+	f(t, `custom 0`, tik.TokenTypeCustom)
+	f(t, `custom 127`, 255)
+}
+
+func TestConfigKeywordFor(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.CardinalPluralNumberSign = "count"
+
+	// Placeholders that require a value have no keyword of their own.
+	requiresValue := map[tik.TokenType]bool{
+		tik.TokenTypeBoolean:           true,
+		tik.TokenTypeSelect:            true,
+		tik.TokenTypeStringPlaceholder: true,
+		tik.TokenTypeRawICUArgument:    true,
+		tik.TokenTypeUnit:              true,
+		tik.TokenTypeNumberSkeleton:    true,
+	}
+	p := tik.NewParser(conf)
+	for tp := tik.TokenType(1); tp.String() != "unknown"; tp++ {
+		keyword, ok := conf.KeywordFor(tp)
+		if !tp.IsPlaceholder() || requiresValue[tp] {
+			requireEqual(t, false, ok)
+			requireEqual(t, "", keyword)
+			continue
+		}
+		requireEqual(t, true, ok)
+		input := "{" + keyword + "}"
+		if tp == tik.TokenTypeCardinalPluralStart {
+			input = "{" + keyword + " messages}"
+		}
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, tp, tk.Tokens[0].Type)
+	}
+
+	f := func(t *testing.T, expect string, c tik.Config, tp tik.TokenType) {
+		t.Helper()
+		keyword, ok := c.KeywordFor(tp)
+		requireEqual(t, true, ok)
+		requireEqual(t, expect, keyword)
+	}
+	f(t, "date-medium", tik.DefaultConfig, tik.TokenTypeDateMedium)
+	f(t, "name", tik.DefaultConfig, tik.TokenTypeTextWithGender)
+	f(t, "ordinal", tik.DefaultConfig, tik.TokenTypeOrdinalPlural)
+	f(t, "currency", tik.DefaultConfig, tik.TokenTypeCurrency)
+	f(t, "#", tik.DefaultConfig, tik.TokenTypeCardinalPluralStart)
+	f(t, "#", tik.Config{}, tik.TokenTypeCardinalPluralStart)
+	f(t, "count", conf, tik.TokenTypeCardinalPluralStart)
+}
+
+func TestTokenTypeClassification(t *testing.T) {
+	t.Parallel()
+
+	type class struct{ placeholder, date, time, currency, plural bool }
+	expect := map[tik.TokenType]class{
+		tik.TokenTypeContext:             {},
+		tik.TokenTypeLiteral:             {},
+		tik.TokenTypeText:                {placeholder: true},
+		tik.TokenTypeTextWithGender:      {placeholder: true},
+		tik.TokenTypeInteger:             {placeholder: true},
+		tik.TokenTypeNumber:              {placeholder: true},
+		tik.TokenTypeCardinalPluralStart: {placeholder: true, plural: true},
+		tik.TokenTypeCardinalPluralEnd:   {plural: true},
+		tik.TokenTypeOrdinalPlural:       {placeholder: true, plural: true},
+		tik.TokenTypeDateFull:            {placeholder: true, date: true},
+		tik.TokenTypeDateLong:            {placeholder: true, date: true},
+		tik.TokenTypeDateMedium:          {placeholder: true, date: true},
+		tik.TokenTypeDateShort:           {placeholder: true, date: true},
+		tik.TokenTypeTimeFull:            {placeholder: true, time: true},
+		tik.TokenTypeTimeLong:            {placeholder: true, time: true},
+		tik.TokenTypeTimeMedium:          {placeholder: true, time: true},
+		tik.TokenTypeTimeShort:           {placeholder: true, time: true},
+		tik.TokenTypeCurrency:            {placeholder: true, currency: true},
+		tik.TokenTypeMessageRef:          {},
+		tik.TokenTypePluralOffset:        {plural: true},
+		tik.TokenTypePluralExactMatch:    {plural: true},
+		tik.TokenTypePluralOther:         {plural: true},
+		tik.TokenTypeBoolean:             {placeholder: true},
+		tik.TokenTypeSelect:              {placeholder: true},
+		tik.TokenTypeStringPlaceholder:   {placeholder: true},
+		tik.TokenTypeTimeZone:            {placeholder: true, time: true},
+		tik.TokenTypeRelativeTime:        {placeholder: true},
+		tik.TokenTypeNumberSpellout:      {placeholder: true},
+		tik.TokenTypeTagOpen:             {},
+		tik.TokenTypeTagClose:            {},
+		tik.TokenTypeTagSelfClosing:      {},
+		tik.TokenTypeRawICU:              {},
+		tik.TokenTypeRawICUArgument:      {placeholder: true},
+		tik.TokenTypeWeekOfYear:          {placeholder: true, date: true},
+		tik.TokenTypeQuarter:             {placeholder: true, date: true},
+		tik.TokenTypeNumberRange:         {placeholder: true},
+		tik.TokenTypePhone:               {placeholder: true},
+		tik.TokenTypeEmail:               {placeholder: true},
+		tik.TokenTypeURL:                 {placeholder: true},
+		tik.TokenTypeUnit:                {placeholder: true},
+		tik.TokenTypePermille:            {placeholder: true},
+		tik.TokenTypeSkip:                {placeholder: true},
+		tik.TokenTypeDateTime:            {placeholder: true, date: true},
+		tik.TokenTypeNumberSkeleton:      {placeholder: true},
+	}
+
+	// Every defined token type must be classified.
+	for tp := tik.TokenType(1); tp.String() != "unknown"; tp++ {
+		c, ok := expect[tp]
+		if !ok {
+			t.Fatalf("token type %d (%s) isn't classified", tp, tp)
+		}
+		requireEqual(t, c.placeholder, tp.IsPlaceholder())
+		requireEqual(t, c.date, tp.IsDate())
+		requireEqual(t, c.time, tp.IsTime())
+		requireEqual(t, c.currency, tp.IsCurrency())
+		requireEqual(t, c.plural, tp.IsPlural())
+		delete(expect, tp)
+	}
+	requireEqual(t, 0, len(expect))
+
+	var zero tik.TokenType
+	requireEqual(t, false, zero.IsPlaceholder())
+	requireEqual(t, false, zero.IsPlural())
+}
+
+func TestICUTranslator(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect, tikInput string) {
+		t.Helper()
+		tk, err := p.Parse(tikInput)
+		requireNoErr(t, err)
+		actual := translator.TIK2ICU(tk)
+		requireEqual(t, expect, actual)
+	}
+
+	f(t, "hello world", "hello world")
+	f(t, "hello world", "[context] hello world")
+	f(t, "hello {var0}", `hello {text}`)
+	f(t, "hello {var0}", `[more context] hello {text}`)
+	f(t, "Привiт, земля", "Привiт, земля")
+	f(t, "{var0, plural, other {# files}}{var1}", `{# files}{name}`)
+	f(t, "{var0, plural, other {# files}}{var1, plural, other {# folders}}",
+		`{# files}{# folders}`)
+	f(t, "{var0, plural, other {# files}}{var1, date, short}", `{# files}{date-short}`)
+	f(t,
+		"today''s lucky number is {var0, number, integer}",
+		`today's lucky number is {integer}`)
+	f(t,
+		"it''s {var0, number} degrees",
+		`it's {number} degrees`)
+	f(t, "a < b and <c>", `a < b and <c>`) // Unquoted without markup tags.
+	f(t,
+		"{var0, number, integer} of {var1, number} liters",
+		`{integer} of {number} liters`)
+	f(t,
+		"your account balance: {var0, number, ::currency/auto}",
+		`your account balance: {currency}`)
+	f(t,
+		"updated {var0, relativetime} on {var1, date, long}",
+		`updated {relative-time} on {date-long}`)
+	f(t,
+		"Week {var0, date, ::w} of {var1, date, ::qqq} {var2, number}",
+		`Week {week-of-year} of {quarter} {number}`)
+	f(t,
+		"pay {var0, spellout} ({var1, number, integer}) dollars",
+		`pay {spellout} ({integer}) dollars`)
+	f(t,
+		"{var0, number, ::currency/EUR} or {var1, number, ::currency/USD}",
+		`{currency:EUR} or {currency:usd}`)
+	f(t,
+		`today is {var0, date, full}`,
+		"today is {date-full}")
+	f(t,
+		`today is {var0, date, long}`,
+		"today is {date-long}")
+	f(t,
+		`today is {var0, date, medium}`,
+		"today is {date-medium}")
+	f(t,
+		`today is {var0, date, short}`,
+		"today is {date-short}")
+	f(t,
+		`current time is {var0, time, full}`,
+		"current time is {time-full}")
+	f(t,
+		`current time is {var0, time, long}`,
+		"current time is {time-long}")
+	f(t,
+		`current time is {var0, time, medium}`,
+		"current time is {time-medium}")
+	f(t,
+		`current time is {var0, time, short}`,
+		"current time is {time-short}")
+	f(t,
+		"You''re {var0, selectordinal, other {#th}}",
+		`You're {ordinal}`)
+	f(t,
+		"hello {var0} and {var1}",
+		`hello {text} and {text}`)
+	f(t,
+		"{var0} received the message",
+		`{name} received the message`)
+	f(t,
+		"it''s {var0, date, long}, {var1, time, short}",
+		`it's {date-long}, {time-short}`)
+	f(t,
+		"You have {var0, plural, other {# messages}}",
+		`You have {# messages}`)
+	f(t,
+		"You have {var0, plural, other {# messages}} "+
+			"in {var1, plural, other {# folders}}.",
+		`You have {# messages} in {# folders}.`)
+
+	// Blank pluralization.
+	f(t,
+		"{var0, plural, other {#}}",
+		`{#}`)
+	f(t,
+		"{var0, plural, other {#}}{var1, plural, other {#}}",
+		`{#}{#}`)
+	f(t,
+		"あなたには{var0, plural, other {#}}件のメッセージがあります。",
+		`あなたには{#}件のメッセージがあります。`)
+
+	// Message references don't consume an argument.
+	f(t,
+		"{var0} {@help.intro} {var1, plural, other {# in {@folder.name}}}",
+		`{text} {@help.intro} {# in {@folder.name}}`)
+
+	// Pluralization arms.
+	f(t,
+		"{var0, plural, =0 {no messages} =1 {one message} other {# messages}}",
+		`{# |=0 no messages |=1 one message | messages}`)
+	f(t,
+		"{var0, plural, offset:1 =0 {nobody} =1 {{var1}} "+
+			"other {# others and {var2}}}",
+		`{# offset:1 |=0 nobody |=1 {name} | others and {name}}`)
+	// Exact match arms are emitted in canonical order while arguments are
+	// indexed in order of occurrence.
+	f(t,
+		"{var0, plural, =0 {none} =1 {one by {var2}} =2 {two by {var1}} "+
+			"other {# by {var3}}} {var4, time, short}",
+		`{# |=2 two by {text} |=1 one by {text} |=0 none | by {text}} {time-short}`)
+
+	// ICU quoting.
+	f(t,
+		"it''s {var0, plural, other {# items}}",
+		`it's {# items}`)
+	f(t,
+		"50% off {var0}",
+		`50% off {name}`)
+	f(t,
+		"'{'not a placeholder'}' #1",
+		`\{not a placeholder\} #1`)
+	f(t,
+		"'{}' and '{''' and '''}'",
+		`\{\} and \{' and '\}`)
+	f(t,
+		"{var0, plural, other {# items tagged '#'hot}}",
+		`{# items tagged #hot}`)
+	f(t,
+		"#1 {var0, plural, other {# x '##' y}} #2",
+		`#1 {# x ## y} #2`)
+	f(t,
+		"{var0, plural, =0 {no '#'tags} other {# '#'tags in '{'{var1}}}",
+		`{# |=0 no #tags | #tags in \{{text}}`)
+
+	// Raw ICU is emitted verbatim.
+	f(t,
+		"{var0} is {var1, number, ::percent scale/100} of {var2, number} '{'",
+		`{text} is {icu:{var, number, ::percent scale/100}} of {number} {icu:'{'}`)
+	f(t,
+		"{var0, plural, other {# x {var1, select, a {b} other {{var1}}}}} {varX}",
+		`{# x {icu:{var, select, a {b} other {{var}}}}} {icu:{varX}}`)
+
+	// Context
+	f(t, `Message`, `[context] Message`)
+	// Context
+	f(t, `Текст сообщения.`, `[текст контекста] Текст сообщения.`)
+}
+
+func TestDiffICU(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	var tiks []tik.TIK
+	for _, input := range []string{
+		`hello world`,
+		`You're {ordinal} out of {# contenders}`,
+		`{text} has {# messages}`,
+		`[context] {ordinal} place`,
+	} {
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		tiks = append(tiks, tk)
+	}
+
+	newConf := tik.DefaultConfig
+	newConf.OrdinalPluralOtherSuffix = "."
+
+	requireDeepEqual(t, []tik.ICUChange(nil),
+		tik.DiffICU(tiks, tik.DefaultConfig, tik.DefaultConfig))
+	requireDeepEqual(t, []tik.ICUChange{
+		{
+			Index: 1,
+			TIK:   tiks[1],
+			Old: "You''re {var0, selectordinal, other {#th}} " +
+				"out of {var1, plural, other {# contenders}}",
+			New: "You''re {var0, selectordinal, other {#.}} " +
+				"out of {var1, plural, other {# contenders}}",
+		},
+		{
+			Index: 3,
+			TIK:   tiks[3],
+			Old:   "{var0, selectordinal, other {#th}} place",
+			New:   "{var0, selectordinal, other {#.}} place",
+		},
+	}, tik.DiffICU(tiks, tik.DefaultConfig, newConf))
+}
+
+func TestICUTranslatorBoolean(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect, tikInput string) {
+		t.Helper()
+		tk, err := p.Parse(tikInput)
+		requireNoErr(t, err)
+		requireEqual(t, expect, translator.TIK2ICU(tk))
+	}
+
+	f(t, "Wi-Fi is {var0, select, true {on} false {off} other {}}",
+		`Wi-Fi is {bool:on/off}`)
+	f(t, "{var0} {var1, select, true {isn''t} false {is} other {}} "+
+		"{var2, plural, other {# times}}",
+		`{name} {bool:isn't/is} {# times}`)
+}
+
+func TestICUTranslatorStringPlaceholder(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	p := tik.NewParser(tik.DefaultConfig)
+
+	tk, err := p.Parse(`Order is {"status"}`)
+	requireNoErr(t, err)
+	requireEqual(t, "Order is {var0}", translator.TIK2ICU(tk))
+	requireEqual(t, "status", tk.Tokens[1].Value)
+
+	tk, err = p.Parse(`{"John" # the customer} and {text}`)
+	requireNoErr(t, err)
+	requireEqual(t, "{var0} and {var1}", translator.TIK2ICU(tk))
+	requireEqual(t, "John", tk.Tokens[0].Value)
+	requireEqual(t, "the customer", tk.Tokens[0].Hint)
+
+	// Escaped quotes and curly braces are unescaped in the sample.
+	tk, err = p.Parse(`{"5\" \{wide\}" # the \"size\"} screen`)
+	requireNoErr(t, err)
+	requireEqual(t, "{var0} screen", translator.TIK2ICU(tk))
+	requireEqual(t, `5" {wide}`, tk.Tokens[0].Value)
+	requireEqual(t, `the \"size\"`, tk.Tokens[0].Hint)
+}
+
+func TestICUTranslatorSelect(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect, tikInput string) {
+		t.Helper()
+		tk, err := p.Parse(tikInput)
+		requireNoErr(t, err)
+		requireEqual(t, expect, translator.TIK2ICU(tk))
+	}
+
+	f(t, "Order {status, select, pending {} shipped {} delivered {} other {}}",
+		`Order {select:status(pending,shipped,delivered)}`)
+	f(t, "{var0} {state, select, on {} other {}} {var2, plural, other {# times}}",
+		`{name} {select:state(on)} {# times}`)
+	// A select name may be reused with the same options.
+	f(t, "{s, select, a {} b {} other {}} {s, select, b {} a {} other {}}",
+		`{select:s(a,b)} {select:s(b,a)}`)
+	f(t, "{var0} {variant, select, a {} other {}} {var10x, select, a {} other {}}",
+		`{text} {select:variant(a)} {select:var10x(a)}`)
+
+	conf := tik.DefaultConfig
+	conf.ICUVarPrefix = "arg"
+	_, err := tik.NewParser(conf).Parse(`{text} {select:arg0(a)}`)
+	requireErrIs(t, tik.ErrSelectInvalid, err)
+	_, err = tik.NewParser(conf).Parse(`{text} {select:var0(a)}`)
+	requireNoErr(t, err)
+
+	tk, err := p.Parse(`{select:status(pending,shipped) # shipment status}`)
+	requireNoErr(t, err)
+	requireDeepEqual(t, map[string]string{"status": "shipment status"},
+		translator.ArgHints(tk))
+}
+
+func TestICUTranslatorOrdinalSuffix(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect, tikInput string) {
+		t.Helper()
+		tk, err := p.Parse(tikInput)
+		requireNoErr(t, err)
+		requireEqual(t, expect, translator.TIK2ICU(tk))
+	}
+
+	f(t, "You''re {var0, selectordinal, other {#th}}", `You're {ordinal}`)
+	f(t, "{var0, selectordinal, other {#º}} and {var1, selectordinal, other {#th}}",
+		`{ordinal:º} and {ordinal}`)
+	f(t, "{var0, plural, other {# times, {var1, selectordinal, other {#.}}}}",
+		`{# times, {ordinal:.}}`)
+
+	tk, err := p.Parse(`{ordinal:º # position}`)
+	requireNoErr(t, err)
+	requireDeepEqual(t, tik.Tokens{{
+		IndexStart: 0, IndexEnd: len(`{ordinal:º # position}`),
+		Type: tik.TokenTypeOrdinalPlural, Value: "º", Hint: "position",
+	}}, tk.Tokens)
+
+	for _, input := range []string{
+		`{ordinal:}`, `{ordinal:a b}`, `{ordinal:'}`, `{ordinal:|}`,
+	} {
+		_, err := p.Parse(input)
+		requireDeepEqual(t, error(tik.ParseError{
+			Index: 0, Err: tik.ErrOrdinalSuffixInvalid,
+		}), err)
+	}
+}
+
+func TestICUTranslatorNumberRange(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	p := tik.NewParser(tik.DefaultConfig)
+
+	tk, err := p.Parse(`{number-range # price} for {# items} by {name}`)
+	requireNoErr(t, err)
+	requireEqual(t, "{var0, number}–{var1, number} for "+
+		"{var2, plural, other {# items}} by {var3}", translator.TIK2ICU(tk))
+	requireEqual(t, 4, tk.Tokens.PlaceholderCount())
+	requireDeepEqual(t, []tik.Arg{
+		{Index: 0, Type: tik.TokenTypeNumberRange, Kind: tik.FormatKindNumberRange,
+			Hint: "price"},
+		{Index: 1, Type: tik.TokenTypeNumberRange, Kind: tik.FormatKindNumberRange,
+			Hint: "price"},
+		{Index: 2, Type: tik.TokenTypeCardinalPluralStart},
+		{Index: 3, Type: tik.TokenTypeTextWithGender, Kind: tik.FormatKindTextWithGender},
+	}, tk.Args())
+	requireEqual(t, "var3", tk.Args()[3].Name())
+
+	var indexes []int
+	for i := range tk.Placeholders() {
+		indexes = append(indexes, i)
+	}
+	requireDeepEqual(t, []int{0, 2, 3}, indexes)
+
+	requireNoErr(t, tk.CheckArgs(10, 20.5, 3, "Alice"))
+	err = tk.CheckArgs(10, "20", 3, "Alice")
+	requireDeepEqual(t, error(&tik.ArgError{
+		Index: 1, Type: tik.TokenTypeNumberRange,
+		Expect: "integer or float", Received: "string",
+	}), err)
+}
+
+func TestICUTranslatorPhone(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	p := tik.NewParser(tik.DefaultConfig)
+
+	tk, err := p.Parse(`Call {phone # support hotline} or {number}`)
+	requireNoErr(t, err)
+	requireEqual(t, "Call {var0} or {var1, number}", translator.TIK2ICU(tk))
+	requireDeepEqual(t, []tik.Arg{
+		{Index: 0, Type: tik.TokenTypePhone, Kind: tik.FormatKindText,
+			Hint: "support hotline"},
+		{Index: 1, Type: tik.TokenTypeNumber, Kind: tik.FormatKindNumber},
+	}, tk.Args())
+
+	requireNoErr(t, tk.CheckArgs("+1 555 0100", 42))
+	err = tk.CheckArgs(15550100, 42)
+	requireDeepEqual(t, error(&tik.ArgError{
+		Index: 0, Type: tik.TokenTypePhone, Expect: "string", Received: "int",
+	}), err)
+}
+
+func TestICUTranslatorEmailURL(t *testing.T) {
+	t.Parallel()
+
+	const input = `Sent to {email}, visit {url} or {# |=0 nobody | incl. {email}}`
+
+	tk, err := tik.NewParser(tik.DefaultConfig).Parse(input)
+	requireNoErr(t, err)
+	requireEqual(t, "Sent to {var0}, visit {var1} or "+
+		"{var2, plural, =0 {nobody} other {# incl. {var3}}}",
+		tik.NewICUTranslator(tik.DefaultConfig).TIK2ICU(tk))
+	requireDeepEqual(t, []tik.Arg{
+		{Index: 0, Type: tik.TokenTypeEmail, Kind: tik.FormatKindEmail},
+		{Index: 1, Type: tik.TokenTypeURL, Kind: tik.FormatKindURL},
+		{Index: 2, Type: tik.TokenTypeCardinalPluralStart},
+		{Index: 3, Type: tik.TokenTypeEmail, Kind: tik.FormatKindEmail},
+	}, tk.Args())
+	requireNoErr(t, tk.CheckArgs("a@example.com", "https://example.com", 1, "b@example.com"))
+	err = tk.CheckArgs("a@example.com", 42, 1, "b@example.com")
+	requireDeepEqual(t, error(&tik.ArgError{
+		Index: 1, Type: tik.TokenTypeURL, Expect: "string", Received: "int",
+	}), err)
+
+	conf := tik.DefaultConfig
+	conf.BidiIsolateAddresses = true
+	requireEqual(t, "Sent to \u2066{var0}\u2069, visit \u2066{var1}\u2069 or "+
+		"{var2, plural, =0 {nobody} other {# incl. \u2066{var3}\u2069}}",
+		tik.NewICUTranslator(conf).TIK2ICU(tk))
+
+	// Other placeholders aren't isolated.
+	tk, err = tik.NewParser(conf).Parse(`{text} {phone}`)
+	requireNoErr(t, err)
+	requireEqual(t, "{var0} {var1}", tik.NewICUTranslator(conf).TIK2ICU(tk))
+}
+
+func TestICUTranslatorUnit(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expect, translator.TIK2ICU(tk))
+	}
+
+	// Length.
+	f(t, "Drive {var0, number, ::unit/kilometer}",
+		`Drive {unit:kilometer}`)
+	// Temperature.
+	f(t, "It is {var0, number, ::unit/celsius} outside",
+		`It is {unit:celsius} outside`)
+	// Mass.
+	f(t, "{var0, number, ::unit/kilogram} of {var1}",
+		`{unit:kilogram} of {text}`)
+	// Compound units.
+	f(t, "{var0, number, ::unit/kilometer-per-hour}",
+		`{unit:kilometer-per-hour}`)
+
+	tk, err := p.Parse(`{unit:kilogram} of {text}`)
+	requireNoErr(t, err)
+	requireDeepEqual(t, []tik.Arg{
+		{Index: 0, Type: tik.TokenTypeUnit, Kind: tik.FormatKindUnit},
+		{Index: 1, Type: tik.TokenTypeText, Kind: tik.FormatKindText},
+	}, tk.Args())
+	requireNoErr(t, tk.CheckArgs(2.5, "flour"))
+	requireNoErr(t, tk.CheckArgs(2, "flour"))
+	err = tk.CheckArgs("2.5", "flour")
+	requireDeepEqual(t, error(&tik.ArgError{
+		Index: 0, Type: tik.TokenTypeUnit, Expect: "integer or float", Received: "string",
+	}), err)
+}
+
+func TestICUTranslatorNumberSkeleton(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expect, translator.TIK2ICU(tk))
+	}
+
+	// Fraction digits.
+	f(t, "Total: {var0, number, ::.00}", `Total: {number::.00}`)
+	f(t, "{var0, number, ::.0#} km", `{number::.0#} km`)
+	// The hint of a skeleton containing `#` must be preceded by whitespace.
+	f(t, "{var0, number, ::.0#} km", `{number::.0# # the distance} km`)
+	f(t, "{var0, number, ::@@#}", "{number::@@#\t# precise}")
+	// Grouping.
+	f(t, "ID {var0, number, ::group-off}", `ID {number::group-off}`)
+	f(t, "{var0, number, ::group-min2 .00} by {var1}",
+		`{number::group-min2 .00} by {text}`)
+	f(t, "{var0, plural, other {# items at {var1, number, ::.00}}}",
+		`{# items at {number::.00}}`)
+
+	tk, err := p.Parse(`{number::.0# # the distance} km`)
+	requireNoErr(t, err)
+	requireEqual(t, ".0#", tk.Tokens[0].Value)
+	requireEqual(t, "the distance", tk.Tokens[0].Hint)
+
+	tk, err = p.Parse(`{number::.00} by {text}`)
+	requireNoErr(t, err)
+	requireDeepEqual(t, []tik.Arg{
+		{Index: 0, Type: tik.TokenTypeNumberSkeleton, Kind: tik.FormatKindNumber},
+		{Index: 1, Type: tik.TokenTypeText, Kind: tik.FormatKindText},
+	}, tk.Args())
+	requireNoErr(t, tk.CheckArgs(2.5, "Alice"))
+	err = tk.CheckArgs("2.5", "Alice")
+	requireDeepEqual(t, error(&tik.ArgError{
+		Index: 0, Type: tik.TokenTypeNumberSkeleton,
+		Expect: "integer or float", Received: "string",
+	}), err)
+}
+
+func TestICUTranslatorPermille(t *testing.T) {
+	t.Parallel()
+
+	tk, err := tik.NewParser(tik.DefaultConfig).Parse(
+		`{permille # alcohol} of {number} liters`)
+	requireNoErr(t, err)
+	requireEqual(t, "{var0, number, ::permille} of {var1, number} liters",
+		tik.NewICUTranslator(tik.DefaultConfig).TIK2ICU(tk))
+	requireDeepEqual(t, []tik.Arg{
+		{Index: 0, Type: tik.TokenTypePermille, Kind: tik.FormatKindPermille, Hint: "alcohol"},
+		{Index: 1, Type: tik.TokenTypeNumber, Kind: tik.FormatKindNumber},
+	}, tk.Args())
+	requireNoErr(t, tk.CheckArgs(0.005, 2))
+	err = tk.CheckArgs("5‰", 2)
+	requireDeepEqual(t, error(&tik.ArgError{
+		Index: 0, Type: tik.TokenTypePermille, Expect: "integer or float", Received: "string",
+	}), err)
+}
+
+func TestICUTranslatorDateTime(t *testing.T) {
+	t.Parallel()
+
+	tk, err := tik.NewParser(tik.DefaultConfig).Parse(
+		`Sent {date-time # sent at} by {name}`)
+	requireNoErr(t, err)
+	requireEqual(t, "Sent {var0, date, ::yMMMdjmm} by {var1}",
+		tik.NewICUTranslator(tik.DefaultConfig).TIK2ICU(tk))
+	requireDeepEqual(t, []tik.Arg{
+		{Index: 0, Type: tik.TokenTypeDateTime, Kind: tik.FormatKindDateTime, Hint: "sent at"},
+		{Index: 1, Type: tik.TokenTypeTextWithGender, Kind: tik.FormatKindTextWithGender},
+	}, tk.Args())
+	requireNoErr(t, tk.CheckArgs(time.Date(1999, 7, 16, 22, 30, 0, 0, time.UTC), "Alice"))
+	requireErrIs(t, tik.ErrArgCount, tk.CheckArgs(time.Now(), time.Now(), "Alice"))
+	err = tk.CheckArgs("1999-07-16", "Alice")
+	requireDeepEqual(t, error(&tik.ArgError{
+		Index: 0, Type: tik.TokenTypeDateTime, Expect: "time.Time", Received: "string",
+	}), err)
+}
+
+func TestICUTranslatorSkip(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expect, translator.TIK2ICU(tk))
+	}
+
+	f(t, "Sent {var1, number} files", `{skip}Sent {number} files`)
+	f(t, "{var0} sent {var2}", `{text} sent {skip # the sender}{text}`)
+	f(t, "{var0} done", `{text} done{skip}`)
+	f(t, "{var1, plural, other {# files of {var3}}}",
+		`{skip}{# files{skip} of {text}}`)
+
+	// Skipped arguments keep their slot in the arguments
+	// and accept any value.
+	tk, err := p.Parse(`{text} sent {skip # the sender}{text}`)
+	requireNoErr(t, err)
+	requireDeepEqual(t, []tik.Arg{
+		{Index: 0, Type: tik.TokenTypeText, Kind: tik.FormatKindText},
+		{Index: 1, Type: tik.TokenTypeSkip, Kind: tik.FormatKindSkip, Hint: "the sender"},
+		{Index: 2, Type: tik.TokenTypeText, Kind: tik.FormatKindText},
+	}, tk.Args())
+	requireNoErr(t, tk.CheckArgs("Alice", 42, "Bob"))
+	requireErrIs(t, tik.ErrArgCount, tk.CheckArgs("Alice", "Bob"))
+	requireEqual(t, "⟦0⟧ sent ⟦2⟧", tk.Surface())
+}
+
+func TestICUTranslatorBidiIsolatePlaceholders(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.BidiIsolatePlaceholders = true
+	translator := tik.NewICUTranslator(conf)
+	p := tik.NewParser(conf)
+
+	f := func(t *testing.T, expect, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expect, translator.TIK2ICU(tk))
+	}
+
+	f(t, "\u2068{var0}\u2069 wrote to \u2068{var1}\u2069", `{name} wrote to {text}`)
+	f(t, "Sent to \u2068{var0}\u2069 via \u2068{var1}\u2069",
+		`Sent to {email} via {url}`)
+	f(t, "Call \u2068{var0}\u2069 or ask \u2068{var1}\u2069",
+		`Call {phone} or ask {"Alice"}`)
+	f(t, "{var0, plural, other {# by \u2068{var1}\u2069}}", `{# by {name}}`)
+
+	// Numbers, dates and other placeholders aren't isolated.
+	f(t, "{var0, number} on {var1, date, short} for {var2, number, ::currency/auto}",
+		`{number} on {date-short} for {currency}`)
+	f(t, "{var0, select, true {on} false {off} other {}}", `{bool:on/off}`)
+
+	// Emails and URLs are isolated left-to-right if BidiIsolateAddresses is enabled.
+	conf.BidiIsolateAddresses = true
+	tk, err := p.Parse(`{text} at {email}`)
+	requireNoErr(t, err)
+	requireEqual(t, "\u2068{var0}\u2069 at \u2066{var1}\u2069",
+		tik.NewICUTranslator(conf).TIK2ICU(tk))
+}
+
+func TestICUTranslatorTimeZone(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	tk, err := p.Parse(`{time-short} ({time-zone})`)
+	requireNoErr(t, err)
+
+	// Long zone such as "Pacific Daylight Time" by default.
+	requireEqual(t, "{var0, time, short} ({var1, date, ::zzzz})",
+		tik.NewICUTranslator(tik.DefaultConfig).TIK2ICU(tk))
+
+	// Short zone such as "PDT".
+	conf := tik.DefaultConfig
+	conf.TimeZoneSkeleton = "z"
+	requireEqual(t, "{var0, time, short} ({var1, date, ::z})",
+		tik.NewICUTranslator(conf).TIK2ICU(tk))
+}
+
+func TestICUTranslatorVarPrefix(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.ICUVarPrefix = "arg"
+	translator := tik.NewICUTranslator(conf)
+	p := tik.NewParser(conf)
+
+	tk, err := p.Parse(`{name} had {# |=0 no messages | messages} on {date-short}`)
+	requireNoErr(t, err)
+	requireEqual(t,
+		"{arg0} had {arg1, plural, =0 {no messages} other {# messages}} "+
+			"on {arg2, date, short}", translator.TIK2ICU(tk))
+}
+
+func TestICUTranslatorWithComment(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expectICU, expectComment, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		icu, comment := translator.TIK2ICUWithComment(tk)
+		requireEqual(t, expectICU, icu)
+		requireEqual(t, expectComment, comment)
+		requireEqual(t, translator.TIK2ICU(tk), icu)
+	}
+
+	f(t, "Order {var0}", "", `Order {text}`)
+	f(t, "Order {var0}", "verb, imperative", `[verb, imperative] Order {text}`)
+	f(t, "Save", "{button} label", `[\{button\} label] Save`)
+	f(t, "Save", "line one line two", "[line one\nline two] Save")
+	f(t, "Save", "a b", "[a\r\nb] Save")
+}
+
+func TestICUTranslatorTo(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	tk, err := tik.NewParser(tik.DefaultConfig).Parse(`{name} had {# messages}`)
+	requireNoErr(t, err)
+
+	var b strings.Builder
+	n, err := translator.TIK2ICUTo(&b, tk)
+	requireNoErr(t, err)
+	requireEqual(t, translator.TIK2ICU(tk), b.String())
+	requireEqual(t, b.Len(), n)
+
+	errWrite := errors.New("write failed")
+	n, err = translator.TIK2ICUTo(failingWriter{n: 4, err: errWrite}, tk)
+	requireErrIs(t, errWrite, err)
+	requireEqual(t, 4, n)
+}
+
+// failingWriter writes at most n bytes and then fails with err.
+type failingWriter struct {
+	n   int
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		return w.n, w.err
+	}
+	return len(p), nil
+}
+
+func TestICUTranslatorTIK2ICUWithPluralCategories(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect string, categories []string, tikInput string) {
+		t.Helper()
+		tk, err := p.Parse(tikInput)
+		requireNoErr(t, err)
+		actual := translator.TIK2ICUWithPluralCategories(tk, categories)
+		requireEqual(t, expect, actual)
+	}
+
+	f(t, "You have {var0, plural, one {} other {# messages}}",
+		[]string{"one", "other"}, `You have {# messages}`)
+	f(t, "You have {var0, plural, one {} few {} many {} other {# messages}}",
+		[]string{"one", "few", "many", "other"}, `You have {# messages}`)
+	f(t, "{var0, plural, other {#}}件のメッセージがあります。",
+		[]string{"other"}, `{#}件のメッセージがあります。`)
+	f(t, "{var0, plural, other {#}}", nil, `{#}`)
+	f(t, "{var0} {var1, plural, one {} other {# files}} "+
+		"{var2, plural, one {} other {# folders}}",
+		[]string{"one", "other"}, `{text} {# files} {# folders}`)
+
+	// TIK2ICU remains other-only.
+	tk, err := p.Parse(`You have {# messages}`)
+	requireNoErr(t, err)
+	requireEqual(t, "You have {var0, plural, other {# messages}}",
+		translator.TIK2ICU(tk))
+}
+
+// mf1Visitor reconstructs an ICU MessageFormat 1 message.
+type mf1Visitor struct {
+	b       strings.Builder
+	armOpen []bool
+}
+
+func (v *mf1Visitor) Literal(s string) error {
+	v.b.WriteString(strings.ReplaceAll(s, "'", "''"))
+	return nil
+}
+
+func (v *mf1Visitor) Currency(index int, code string) error {
+	v.b.WriteString("{var" + strconv.Itoa(index) + ", number, ::currency/" + code + "}")
+	return nil
+}
+
+func (v *mf1Visitor) Ordinal(index int, suffix string) error {
+	v.b.WriteString("{var" + strconv.Itoa(index) + ", selectordinal, other {#" + suffix + "}}")
+	return nil
+}
+
+func (v *mf1Visitor) Unit(index int, unit string) error {
+	v.b.WriteString("{var" + strconv.Itoa(index) + ", number, ::unit/" + unit + "}")
+	return nil
+}
+
+func (v *mf1Visitor) NumberSkeleton(index int, skeleton string) error {
+	v.b.WriteString("{var" + strconv.Itoa(index) + ", number, ::" + skeleton + "}")
+	return nil
+}
+
+func (v *mf1Visitor) Argument(index int, kind tik.FormatKind) error {
+	v.b.WriteString("{var" + strconv.Itoa(index))
+	switch kind {
+	case tik.FormatKindInteger:
+		v.b.WriteString(", number, integer")
+	case tik.FormatKindNumber:
+		v.b.WriteString(", number")
+	case tik.FormatKindCurrency:
+		v.b.WriteString(", number, ::currency/auto")
+	case tik.FormatKindOrdinal:
+		v.b.WriteString(", selectordinal, other {#th}")
+	case tik.FormatKindDateFull:
+		v.b.WriteString(", date, full")
+	case tik.FormatKindDateLong:
+		v.b.WriteString(", date, long")
+	case tik.FormatKindDateMedium:
+		v.b.WriteString(", date, medium")
+	case tik.FormatKindDateShort:
+		v.b.WriteString(", date, short")
+	case tik.FormatKindTimeFull:
+		v.b.WriteString(", time, full")
+	case tik.FormatKindTimeLong:
+		v.b.WriteString(", time, long")
+	case tik.FormatKindTimeMedium:
+		v.b.WriteString(", time, medium")
+	case tik.FormatKindTimeShort:
+		v.b.WriteString(", time, short")
+	case tik.FormatKindTimeZone:
+		v.b.WriteString(", date, ::zzzz")
+	case tik.FormatKindRelativeTime:
+		v.b.WriteString(", relativetime")
+	case tik.FormatKindSpellout:
+		v.b.WriteString(", spellout")
+	case tik.FormatKindWeekOfYear:
+		v.b.WriteString(", date, ::w")
+	case tik.FormatKindQuarter:
+		v.b.WriteString(", date, ::qqq")
+	case tik.FormatKindNumberRange:
+		v.b.WriteString(", number}–{var" + strconv.Itoa(index+1) + ", number")
+	}
+	v.b.WriteString("}")
+	return nil
+}
+
+func (v *mf1Visitor) PluralStart(index int) error {
+	v.b.WriteString("{var" + strconv.Itoa(index) + ", plural, ")
+	v.armOpen = append(v.armOpen, false)
+	return nil
+}
+
+func (v *mf1Visitor) PluralOffset(offset int) error {
+	v.b.WriteString("offset:" + strconv.Itoa(offset) + " ")
+	return nil
+}
+
+func (v *mf1Visitor) openArm() {
+	if v.armOpen[len(v.armOpen)-1] {
+		v.b.WriteString("} ")
+	}
+	v.armOpen[len(v.armOpen)-1] = true
+}
+
+func (v *mf1Visitor) PluralExactMatch(value int) error {
+	v.openArm()
+	v.b.WriteString("=" + strconv.Itoa(value) + " {")
+	return nil
+}
+
+func (v *mf1Visitor) PluralOther() error {
+	v.openArm()
+	v.b.WriteString("other {#")
+	return nil
+}
+
+func (v *mf1Visitor) PluralEnd() error {
+	v.armOpen = v.armOpen[:len(v.armOpen)-1]
+	v.b.WriteString("}}")
+	return nil
+}
+
+func (v *mf1Visitor) Tag(index int, _ string, tag tik.TokenType) error {
+	switch n := strconv.Itoa(index); tag {
+	case tik.TokenTypeTagOpen:
+		v.b.WriteString("<" + n + ">")
+	case tik.TokenTypeTagClose:
+		v.b.WriteString("</" + n + ">")
+	default:
+		v.b.WriteString("<" + n + "/>")
+	}
+	return nil
+}
+
+func (v *mf1Visitor) RawICU(index int, icu string) error {
+	name := "{var" + strconv.Itoa(index)
+	icu = strings.ReplaceAll(icu, "{var,", name+",")
+	v.b.WriteString(strings.ReplaceAll(icu, "{var}", name+"}"))
+	return nil
+}
+
+func (v *mf1Visitor) MessageRef(key string) error {
+	v.b.WriteString("{@" + key + "}")
+	return nil
+}
+
+func (v *mf1Visitor) Select(index int, name string, cases []tik.SelectCase) error {
+	if name == "" {
+		name = "var" + strconv.Itoa(index)
+	}
+	v.b.WriteString("{" + name + ", select, ")
+	for _, c := range cases {
+		v.b.WriteString(c.Key + " {" + strings.ReplaceAll(c.Text, "'", "''") + "} ")
+	}
+	v.b.WriteString("other {}}")
+	return nil
+}
+
+func TestICUTranslatorVisit(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, tikInput string) {
+		t.Helper()
+		tk, err := p.Parse(tikInput)
+		requireNoErr(t, err)
+		var v mf1Visitor
+		requireNoErr(t, translator.Visit(tk, &v))
+		requireEqual(t, translator.TIK2ICU(tk), v.b.String())
+	}
+
+	f(t, `hello world`)
+	f(t, `[context] it's {text} and {name}`)
+	f(t, `{integer} {number} {currency} {ordinal}`)
+	f(t, `{date-full}{date-long}{date-medium}{date-short}`)
+	f(t, `{time-full}{time-long}{time-medium}{time-short}`)
+	f(t, `{name} had {# messages from {text}} on {date-medium}`)
+	f(t, `{text} {@help.intro} {# items in {@folder}}`)
+	f(t, `{# |=1 one message from {name} |=0 none | messages} at {time-short}`)
+	f(t, `{# offset:1 |=0 nobody |=1 {name} | others and {name}}`)
+	f(t, `あなたには{#}件のメッセージがあります。`)
+	f(t, `Wi-Fi is {bool:on/off} for {# devices}`)
+	f(t, `{currency:EUR} and {currency}`)
+	f(t, `{time-short} ({time-zone})`)
+	f(t, `updated {relative-time} on {date-long}`)
+	f(t, `{spellout} ({integer}) dollars`)
+	f(t, `Week {week-of-year}, {quarter}`)
+	f(t, `{number-range} of {# items} by {name}`)
+	f(t, `{ordinal:º} {ordinal} {# times {ordinal:.}}`)
+	f(t, `{text} {icu:{var, number, ::percent}} {icu:'{'}`)
+}
+
+func TestICUTranslatorMapped(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.MarkupTags = []string{"b"}
+	translator := tik.NewICUTranslator(conf)
+	p := tik.NewParser(conf)
+
+	type span struct{ ICU, TIK string }
+
+	f := func(t *testing.T, expect []span, tikInput string) {
+		t.Helper()
+		tk, err := p.Parse(tikInput)
+		requireNoErr(t, err)
+		icu, spans := translator.TIK2ICUMapped(tk)
+		requireEqual(t, translator.TIK2ICU(tk), icu)
+		var actual []span
+		for _, s := range spans {
+			actual = append(actual, span{
+				ICU: icu[s.Start:s.End],
+				TIK: tk.Raw[s.Token.IndexStart:s.Token.IndexEnd],
+			})
+		}
+		requireDeepEqual(t, expect, actual)
+	}
+
+	f(t, []span{{"hello", "hello"}}, `[ctx] hello`)
+	// Apostrophe escaping shifts the offsets of the following spans.
+	f(t, []span{
+		{"it''s ", "it's "},
+		{"{var0}", "{text}"},
+		{" and '{'", ` and \{`},
+		{"{var1, date, short}", "{date-short}"},
+	}, `[ctx] it's {text} and \{{date-short}`)
+	f(t, []span{
+		{"{var0}", "{name}"},
+		{" won''t ", " won't "},
+		{"{var1, plural, =0 {none} other {# o''s by {var2}}}", "{#"},
+		{"none", "none"},
+		{" o''s by ", " o's by "},
+		{"{var2}", "{text}"},
+		{" ", " "},
+		{"<0>", "{<b>}"},
+		{"{@help}", "{@help}"},
+		{"</0>", "{</b>}"},
+	}, `{name} won't {# |=0 none | o's by {text}} {<b>}{@help}{</b>}`)
+}
+
+func TestICUTranslatorPluralNumberSpacing(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	conf := tik.DefaultConfig
+	conf.ScaffoldEnglishPlural = true
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	scaffolding := tik.NewICUTranslator(conf)
 
-	f := func(t *testing.T, input string, expectErrMsg string) {
+	// The space following # is taken from the TIK, never added.
+	f := func(t *testing.T, expect, expectScaffolded, input string) {
 		t.Helper()
-		tk, err := parser.Parse(input)
-		requireEqual(t, expectErrMsg, err.Error())
-		requireDeepEqual(t, tik.TIK{}, tk)
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expect, translator.TIK2ICU(tk))
+		requireEqual(t, expectScaffolded, scaffolding.TIK2ICU(tk))
 	}
 
-	// String literal only.
-	f(t, "hello world {", "at index 12: unclosed placeholder")
-	f(t, "{unknown}", "at index 0: unknown placeholder")
-	f(t, "{# messages in {# folders}}", "at index 15: nested pluralization")
+	f(t, "{var0, plural, other {# messages}}",
+		"{var0, plural, one {# messages} other {# messages}}",
+		`{# messages}`)
+	f(t, "{var0, plural, other {#messages}}",
+		"{var0, plural, one {#messages} other {#messages}}",
+		`{#messages}`)
+	f(t, "{var0, plural, other {#件}}",
+		"{var0, plural, one {#件} other {#件}}",
+		`{#件}`)
+	f(t, "{var0, plural, other {#}}件",
+		"{var0, plural, one {#} other {#}}件",
+		`{#}件`)
+	f(t, "{var0, plural, =0 {なし} other {#件}}",
+		"{var0, plural, =0 {なし} one {#件} other {#件}}",
+		`{# |=0 なし |件}`)
+	f(t, "{var0, plural, =0 {none} other {# files}}",
+		"{var0, plural, =0 {none} one {# files} other {# files}}",
+		`{# |=0 none | files}`)
 }
 
-func TestTIKPlaceholdersIter(t *testing.T) {
+func TestICUTranslatorScaffoldEnglishPlural(t *testing.T) {
 	t.Parallel()
 
-	p := tik.NewParser(tik.DefaultConfig)
+	conf := tik.DefaultConfig
+	conf.ScaffoldEnglishPlural = true
+	conf.AllowNestedPlural = true
+	translator := tik.NewICUTranslator(conf)
+	p := tik.NewParser(conf)
 
-	tk, err := p.Parse(`[context]
-		{date-full}
-		{date-long}
-		{date-medium}
-		{date-short}
-		{time-short}
-		{time-medium}
-		{time-long}
-		{time-full}
-		{currency}
-		{# messages}{ordinal}`)
-	requireNoErr(t, err)
-	expect := []tik.Token{
-		// 0 is a context.
-		tk.Tokens[1],
-		// 2 is a string literal.
-		tk.Tokens[3],
-		// 4 is a string literal.
-		tk.Tokens[5],
-		// 6 is a string literal.
-		tk.Tokens[7],
-		// 8 is a string literal.
-		tk.Tokens[9],
-		// 10 is a string literal.
-		tk.Tokens[11],
-		// 12 is a string literal.
-		tk.Tokens[13],
-		// 14 is a string literal.
-		tk.Tokens[15],
-		// 16 is a string literal.
-		tk.Tokens[17],
-		// 18 is a string literal.
-		tk.Tokens[19],
-		// 20 is a string literal.
-		// 21 is a cardinal plural end.
-		tk.Tokens[22],
+	f := func(t *testing.T, expect, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expect, translator.TIK2ICU(tk))
 	}
 
-	var actual []tik.Token
-	for i, tok := range tk.Placeholders() {
-		requireEqual(t, i, len(actual))
-		actual = append(actual, tok)
-	}
+	f(t, "You have {var0, plural, one {# messages} other {# messages}}",
+		`You have {# messages}`)
+	f(t, "{var0, plural, =0 {no files} one {# files by {var1}} other {# files by {var1}}}",
+		`{# |=0 no files | files by {text}}`)
+	f(t, "{var0, plural, one {# files in {var1, plural, one {# folders} other {# folders}}} "+
+		"other {# files in {var1, plural, one {# folders} other {# folders}}}}",
+		`{# files in {# folders}}`)
+	f(t, "Hello {var0}", `Hello {text}`)
 
-	requireDeepEqual(t, expect, actual)
+	// The one arm isn't scaffolded twice for plural categories.
+	tk, err := p.Parse(`You have {# messages}`)
+	requireNoErr(t, err)
+	requireEqual(t,
+		"You have {var0, plural, one {# messages} few {} many {} other {# messages}}",
+		translator.TIK2ICUWithPluralCategories(tk,
+			[]string{"one", "few", "many", "other"}))
 
-	// Test break
-	{
-		counter := 0
-		for range tk.Placeholders() {
-			counter++
-			break
-		}
-		requireEqual(t, 1, counter)
+	// Spans of the other arm follow the one arm.
+	tk, err = p.Parse(`{# files by {text}}!`)
+	requireNoErr(t, err)
+	icu, spans := translator.TIK2ICUMapped(tk)
+	requireEqual(t, translator.TIK2ICU(tk), icu)
+	var actual []string
+	for _, s := range spans {
+		actual = append(actual, icu[s.Start:s.End])
 	}
+	requireDeepEqual(t, []string{
+		"{var0, plural, one {# files by {var1}} other {# files by {var1}}}",
+		" files by ", "{var1}", "!",
+	}, actual)
 }
 
-func TestTokenType_String(t *testing.T) {
-	f := func(t *testing.T, expect string, value tik.TokenType) {
+func TestICUTranslatorGenderSelect(t *testing.T) {
+	t.Parallel()
+
+	f := func(t *testing.T, expect string, modify func(c *tik.Config), input string) {
 		t.Helper()
-		requireDeepEqual(t, expect, value.String())
+		conf := tik.DefaultConfig
+		modify(&conf)
+		tk, err := tik.NewParser(conf).Parse(input)
+		requireNoErr(t, err)
+		requireEqual(t, expect, tik.NewICUTranslator(conf).TIK2ICU(tk))
 	}
 
-	f(t, `unknown`, 0)
-	f(t, `unknown`, 255)
-	f(t, `context`, tik.TokenTypeContext)
-	f(t, `literal`, tik.TokenTypeLiteral)
-	f(t, `text`, tik.TokenTypeText)
-	f(t, `text with gender`, tik.TokenTypeTextWithGender)
-	f(t, `integer`, tik.TokenTypeInteger)
-	f(t, `number`, tik.TokenTypeNumber)
-	f(t, `pluralization`, tik.TokenTypeCardinalPluralStart)
-	f(t, `pluralization block end`, tik.TokenTypeCardinalPluralEnd)
-	f(t, `ordinal plural`, tik.TokenTypeOrdinalPlural)
-	f(t, `date full`, tik.TokenTypeDateFull)
-	f(t, `date long`, tik.TokenTypeDateLong)
-	f(t, `date medium`, tik.TokenTypeDateMedium)
-	f(t, `date short`, tik.TokenTypeDateShort)
-	f(t, `time full`, tik.TokenTypeTimeFull)
-	f(t, `time long`, tik.TokenTypeTimeLong)
-	f(t, `time medium`, tik.TokenTypeTimeMedium)
-	f(t, `time short`, tik.TokenTypeTimeShort)
-	f(t, `currency`, tik.TokenTypeCurrency)
+	// Disabled by default.
+	f(t, "{var0} arrived", func(c *tik.Config) {}, `{name} arrived`)
+	f(t, "{var0_gender, select, female {{var0}} male {{var0}} other {{var0}}} arrived",
+		func(c *tik.Config) { c.GenderSelect = true }, `{name} arrived`)
+	f(t, "{var0_gender, select, female {{var0}} male {{var0}} other {{var0}}} arrived",
+		func(c *tik.Config) {
+			c.GenderSelect, c.GenderCategories = true, nil
+		}, `{name} arrived`)
+	f(t, "{var0_gender, select, animate {{var0}} inanimate {{var0}} "+
+		"other {{var0}}} arrived",
+		func(c *tik.Config) {
+			c.GenderSelect = true
+			c.GenderCategories = []string{"animate", "inanimate", "other"}
+		}, `{name} arrived`)
+	f(t, "{var0_gender, select, feminine {{var0}} masculine {{var0}} "+
+		"neuter {{var0}} other {{var0}}} arrived",
+		func(c *tik.Config) {
+			c.GenderSelect = true
+			c.GenderCategories = []string{"masculine", "feminine", "neuter", "other"}
+		}, `{name} arrived`)
+
+	// Only text with gender is affected.
+	f(t, "{arg0} met {arg1_gender, select, female {{arg1}} male {{arg1}} other {{arg1}}} "+
+		"{arg2, plural, other {# times}}",
+		func(c *tik.Config) { c.GenderSelect, c.ICUVarPrefix = true, "arg" },
+		`{text} met {name} {# times}`)
+	f(t, "{var0, plural, other {# messages from "+
+		"{var1_gender, select, female {\u2068{var1}\u2069} male {\u2068{var1}\u2069} "+
+		"other {\u2068{var1}\u2069}}}}",
+		func(c *tik.Config) { c.GenderSelect, c.BidiIsolatePlaceholders = true, true },
+		`{# messages from {name}}`)
+
+	// Arms are in canonical order and the other arm is always last.
+	f(t, "{var0_gender, select, female {{var0}} male {{var0}} other {{var0}}} arrived",
+		func(c *tik.Config) {
+			c.GenderSelect = true
+			c.GenderCategories = []string{"male", "other", "female"}
+		}, `{name} arrived`)
 }
 
-func TestICUTranslator(t *testing.T) {
+func TestCustomResolver(t *testing.T) {
 	t.Parallel()
 
-	translator := tik.NewICUTranslator(tik.DefaultConfig)
-	p := tik.NewParser(tik.DefaultConfig)
+	const TokenTypeSKU = tik.TokenTypeCustom + 1
+	conf := tik.DefaultConfig
+	conf.CustomResolver = func(directive string) (tik.TokenType, bool) {
+		switch directive {
+		case "sku":
+			return TokenTypeSKU, true
+		case "productName":
+			return tik.TokenTypeText, true
+		case "weight":
+			return tik.TokenTypeUnit, true // Requires a value.
+		}
+		return 0, false
+	}
 
-	f := func(t *testing.T, expect, tikInput string) {
-		t.Helper()
-		tk, err := p.Parse(tikInput)
-		requireNoErr(t, err)
-		actual := translator.TIK2ICU(tk)
-		requireEqual(t, expect, actual)
+	tk, err := tik.NewParser(conf).Parse(`{productName} {sku # e.g. X-1} costs {currency}`)
+	requireNoErr(t, err)
+	requireDeepEqual(t, []tik.Token{
+		{IndexStart: 0, IndexEnd: 13, Type: tik.TokenTypeText},
+		{IndexStart: 13, IndexEnd: 14, Type: tik.TokenTypeLiteral},
+		{IndexStart: 14, IndexEnd: 30, Type: TokenTypeSKU, Value: "sku", Hint: "e.g. X-1"},
+		{IndexStart: 30, IndexEnd: 37, Type: tik.TokenTypeLiteral},
+		{IndexStart: 37, IndexEnd: 47, Type: tik.TokenTypeCurrency},
+	}, []tik.Token(tk.Tokens))
+	requireEqual(t, "custom 1", TokenTypeSKU.String())
+	requireDeepEqual(t, []tik.Arg{
+		{Index: 0, Type: tik.TokenTypeText, Kind: tik.FormatKindText},
+		{Index: 1, Type: TokenTypeSKU, Kind: tik.FormatKindText, Hint: "e.g. X-1"},
+		{Index: 2, Type: tik.TokenTypeCurrency, Kind: tik.FormatKindCurrency},
+	}, tk.Args())
+
+	// Custom placeholders are plain arguments by default.
+	requireEqual(t, "{var0} {var1} costs {var2, number, ::currency/auto}",
+		tik.NewICUTranslator(conf).TIK2ICU(tk))
+
+	conf.CustomICU = func(tok tik.Token) (string, bool) {
+		if tok.Type != TokenTypeSKU {
+			return "", false
+		}
+		return "{var, select, other {SKU {var}}}", true
 	}
+	requireEqual(t, "{var0} {var1, select, other {SKU {var1}}} costs "+
+		"{var2, number, ::currency/auto}",
+		tik.NewICUTranslator(conf).TIK2ICU(tk))
 
-	f(t, "hello world", "hello world")
-	f(t, "hello world", "[context] hello world")
-	f(t, "hello {var0}", `hello {text}`)
-	f(t, "hello {var0}", `[more context] hello {text}`)
-	f(t, "Привiт, земля", "Привiт, земля")
-	f(t,
-		"today''s lucky number is {var0, number, integer}",
-		`today's lucky number is {integer}`)
-	f(t,
-		"it''s {var0, number} degrees",
-		`it's {number} degrees`)
-	f(t,
-		"your account balance: {var0, number, ::currency/auto}",
-		`your account balance: {currency}`)
-	f(t,
-		`today is {var0, date, full}`,
-		"today is {date-full}")
-	f(t,
-		`today is {var0, date, long}`,
-		"today is {date-long}")
-	f(t,
-		`today is {var0, date, medium}`,
-		"today is {date-medium}")
-	f(t,
-		`today is {var0, date, short}`,
-		"today is {date-short}")
-	f(t,
-		`current time is {var0, time, full}`,
-		"current time is {time-full}")
-	f(t,
-		`current time is {var0, time, long}`,
-		"current time is {time-long}")
-	f(t,
-		`current time is {var0, time, medium}`,
-		"current time is {time-medium}")
-	f(t,
-		`current time is {var0, time, short}`,
-		"current time is {time-short}")
-	f(t,
-		"You''re {var0, selectordinal, other {#th}}",
-		`You're {ordinal}`)
-	f(t,
-		"hello {var0} and {var1}",
-		`hello {text} and {text}`)
-	f(t,
-		"{var0} received the message",
-		`{name} received the message`)
-	f(t,
-		"it''s {var0, date, long}, {var1, time, short}",
-		`it's {date-long}, {time-short}`)
-	f(t,
-		"You have {var0, plural, other {# messages}}",
-		`You have {# messages}`)
-	f(t,
-		"You have {var0, plural, other {# messages}} "+
-			"in {var1, plural, other {# folders}}.",
-		`You have {# messages} in {# folders}.`)
+	// Custom placeholders are resolved in cardinal pluralizations too.
+	tk, err = tik.NewParser(conf).Parse(`{# items with {sku}}`)
+	requireNoErr(t, err)
+	requireEqual(t, "{var0, plural, other {# items with "+
+		"{var1, select, other {SKU {var1}}}}}",
+		tik.NewICUTranslator(conf).TIK2ICU(tk))
 
-	// Blank pluralization.
-	f(t,
-		"{var0, plural, other {#}}",
-		`{#}`)
-	f(t,
-		"{var0, plural, other {#}}{var1, plural, other {#}}",
-		`{#}{#}`)
-	f(t,
-		"あなたには{var0, plural, other {#}}件のメッセージがあります。",
-		`あなたには{#}件のメッセージがあります。`)
+	// Built-in keywords take precedence.
+	_, err = tik.NewParser(conf).Parse(`{text}`)
+	requireNoErr(t, err)
 
-	// Context
-	f(t, `Message`, `[context] Message`)
-	// Context
-	f(t, `Текст сообщения.`, `[текст контекста] Текст сообщения.`)
+	_, err = tik.NewParser(conf).Parse(`{weight}`)
+	requireErrIs(t, tik.ErrUnknownPlaceholder, err)
+	_, err = tik.NewParser(conf).Parse(`{unknown}`)
+	requireErrIs(t, tik.ErrUnknownPlaceholder, err)
+	_, err = tik.NewParser(tik.DefaultConfig).Parse(`{sku}`)
+	requireErrIs(t, tik.ErrUnknownPlaceholder, err)
+}
+
+type errVisitor struct{ mf1Visitor }
+
+var errVisitorAbort = errors.New("abort")
+
+func (v *errVisitor) PluralStart(int) error { return errVisitorAbort }
+
+func TestICUTranslatorVisitErr(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	tk, err := tik.NewParser(tik.DefaultConfig).Parse(`{text} has {# messages}`)
+	requireNoErr(t, err)
+
+	var v errVisitor
+	requireErrIs(t, errVisitorAbort, translator.Visit(tk, &v))
+	requireEqual(t, "{var0} has ", v.b.String())
 }
 
 func FuzzTokenize(f *testing.F) {
@@ -509,6 +3553,17 @@ func FuzzTokenize(f *testing.F) {
 	f.Add(`\\\\text after`)
 	f.Add("You're {4th} out of {# contenders}")
 	f.Add("{unknown}")
+	f.Add("[" + strings.Repeat("a", 1<<16) + "] text")
+	f.Add("[" + strings.Repeat("a", 1<<16) + " text")
+	f.Add("[" + strings.Repeat(`\]`, 1<<12) + "] text")
+	f.Add("[" + strings.Repeat(`\`, 1<<12) + "] text")
+	f.Add("[" + strings.Repeat("[", 1<<12) + "] text")
+	f.Add("[a:b/c\\] text")
+	f.Add("\xff")
+	f.Add("hello \xff world")
+	f.Add("{text}\xc3")                // Truncated sequence.
+	f.Add("\xed\xa0\x80 {text}")       // Lone surrogate.
+	f.Add("[\xff] {# \xc0\xaf files}") // Overlong encoding.
 	f.Add(`
 		{text}
 		{name}
@@ -530,6 +3585,9 @@ func FuzzTokenize(f *testing.F) {
 	f.Fuzz(func(t *testing.T, input string) {
 		parser := tik.NewParser(tik.DefaultConfig)
 		tk, err := parser.Parse(input)
+		if !utf8.ValidString(input) {
+			requireErrIs(t, tik.ErrInvalidUTF8, err)
+		}
 		// If an error occurs, ensure it's one of the expected error types.
 		if err != nil {
 			_ = err.Error()
@@ -538,6 +3596,13 @@ func FuzzTokenize(f *testing.F) {
 		for range tk.Placeholders() {
 			// Just iterate to ensure it doesn't panic.
 		}
+		// The generated ICU message must be valid unless it contains raw ICU.
+		if !slices.ContainsFunc(tk.Tokens, func(t tik.Token) bool {
+			return t.Type == tik.TokenTypeRawICU || t.Type == tik.TokenTypeRawICUArgument
+		}) {
+			icu := tik.NewICUTranslator(tik.DefaultConfig).TIK2ICU(tk)
+			requireNoErr(t, tik.ValidateICU(icu))
+		}
 	})
 }
 
@@ -554,6 +3619,52 @@ func BenchmarkParseFnPlaceholdersOnly(b *testing.B) {
 	}
 }
 
+func BenchmarkParseFnPlaceholdersOnlyCaseInsensitive(b *testing.B) {
+	conf := tik.DefaultConfig
+	conf.CaseInsensitiveKeywords = true
+	parser := tik.NewParser(conf)
+	for b.Loop() {
+		err := parser.ParseFn(`{Date-Full}{date-long}{DATE-MEDIUM}{date-short}`+
+			`{time-short}{Time-Medium}{time-long}{time-full}`+
+			`{Integer}{number}{text}{Name}{currency:eur}{ordinal}`+
+			`{Unit:meter}{select:s(a)}{bool:on/off}{"x"}{@ref}`,
+			func(_ tik.TIK) {})
+		if err.Err != nil {
+			panic(err)
+		}
+	}
+}
+
+func BenchmarkParseFnPrefixedPlaceholders(b *testing.B) {
+	parser := tik.NewParser(tik.DefaultConfig)
+	for b.Loop() {
+		err := parser.ParseFn(`{unit:meter}{select:s(a)}{bool:on/off}{"x"}{@ref}`+
+			`{currency:EUR}{ordinal:st}{number::.00}{# items}`,
+			func(_ tik.TIK) {})
+		if err.Err != nil {
+			panic(err)
+		}
+	}
+}
+
+func BenchmarkParseFnEachPlaceholder(b *testing.B) {
+	parser := tik.NewParser(tik.DefaultConfig)
+	inputs := []string{
+		`{date-full}`, `{date-long}`, `{date-medium}`, `{date-short}`,
+		`{time-short}`, `{time-medium}`, `{time-long}`, `{time-full}`,
+		`{integer}`, `{number}`, `{text}`, `{name}`, `{currency}`, `{ordinal}`,
+		`{# messages}`,
+	}
+	for b.Loop() {
+		for _, input := range inputs {
+			err := parser.ParseFn(input, func(_ tik.TIK) {})
+			if err.Err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
 func BenchmarkParseFnFewPlaceholders(b *testing.B) {
 	parser := tik.NewParser(tik.DefaultConfig)
 
@@ -597,6 +3708,49 @@ func BenchmarkParseFnNoPlaceholdersShort(b *testing.B) {
 	}
 }
 
+var benchBatchInputs = []string{
+	`hello world`,
+	`[context] {name} had {# messages} on {date-medium}`,
+	`{text} {text}, {text}{text}`,
+	`You're {ordinal} out of {# contenders}`,
+	`{# offset:1 |=0 nobody | you and # others} by {name}`,
+}
+
+func BenchmarkParseLoop(b *testing.B) {
+	parser := tik.NewParser(tik.DefaultConfig)
+	for b.Loop() {
+		for _, input := range benchBatchInputs {
+			if _, err := parser.Parse(input); err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+func BenchmarkParseBatch(b *testing.B) {
+	parser := tik.NewParser(tik.DefaultConfig)
+	for b.Loop() {
+		parser.ParseBatch(benchBatchInputs, func(_ int, _ tik.TIK, err tik.ParseError) bool {
+			if err.Err != nil {
+				panic(err)
+			}
+			return true
+		})
+	}
+}
+
+// BenchmarkValidateLoop is the counterpart of BenchmarkParseLoop.
+func BenchmarkValidateLoop(b *testing.B) {
+	parser := tik.NewParser(tik.DefaultConfig)
+	for b.Loop() {
+		for _, input := range benchBatchInputs {
+			if err := parser.Validate(input); err.Err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
 func BenchmarkTIK2ICUBuf(b *testing.B) {
 	parser := tik.NewParser(tik.DefaultConfig)
 	translator := tik.NewICUTranslator(tik.DefaultConfig)
@@ -613,6 +3767,45 @@ func BenchmarkTIK2ICUBuf(b *testing.B) {
 	}
 }
 
+func benchBatchTIKs(b *testing.B) []tik.TIK {
+	parser := tik.NewParser(tik.DefaultConfig)
+	tiks := make([]tik.TIK, 0, len(benchBatchInputs)*200)
+	for range 200 {
+		for _, input := range benchBatchInputs {
+			tk, err := parser.Parse(input)
+			requireNoErr(b, err)
+			tiks = append(tiks, tk)
+		}
+	}
+	return tiks
+}
+
+func BenchmarkTIK2ICUBatch(b *testing.B) {
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	tiks := benchBatchTIKs(b)
+	w := bufio.NewWriter(io.Discard)
+
+	for b.Loop() {
+		for _, tk := range tiks {
+			_, _ = w.WriteString(translator.TIK2ICU(tk))
+		}
+	}
+}
+
+func BenchmarkTIK2ICUToBatch(b *testing.B) {
+	translator := tik.NewICUTranslator(tik.DefaultConfig)
+	tiks := benchBatchTIKs(b)
+	w := bufio.NewWriter(io.Discard)
+
+	for b.Loop() {
+		for _, tk := range tiks {
+			if _, err := translator.TIK2ICUTo(w, tk); err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
 func requireDeepEqual[T any](tb testing.TB, expect, actual T) {
 	tb.Helper()
 	if !reflect.DeepEqual(expect, actual) {
@@ -0,0 +1,68 @@
+package tik
+
+// Node is a node of the syntax tree of a TIK.
+// Literals, placeholders, the context, message references, markup tags
+// and plural offsets are leaf nodes.
+type Node struct {
+	Token
+
+	// Children are set for cardinal pluralizations and their arms.
+	// The children of a cardinal pluralization are the nodes of its body,
+	// or its offset followed by its arms if it has exact match arms.
+	// The children of an arm are the nodes of its content.
+	// Plural end tokens aren't part of the tree.
+	Children []Node
+}
+
+// Tree returns the syntax tree of t grouping the content
+// of cardinal pluralizations and their arms, which unlike t.Tokens
+// doesn't require tracking plural start and end tokens.
+// For example, `{# messages from {text}} on {date-short}` yields a
+// pluralization node with the children ` messages from ` and `{text}`,
+// followed by the literal ` on ` and the placeholder `{date-short}`.
+func (t TIK) Tree() []Node { return treeNodes(t.Tokens) }
+
+func treeNodes(tokens Tokens) []Node {
+	var nodes []Node
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.Type != TokenTypeCardinalPluralStart {
+			nodes = append(nodes, Node{Token: tok})
+			continue
+		}
+		end := pluralEnd(tokens, i)
+		nodes = append(nodes, Node{
+			Token:    tok,
+			Children: pluralNodes(tokens[i+1 : end]),
+		})
+		i = end
+	}
+	return nodes
+}
+
+// pluralNodes returns the nodes of the body of a cardinal pluralization
+// grouping the content of its arms.
+func pluralNodes(body Tokens) []Node {
+	var nodes []Node
+	arm := -1    // Index of the current arm token.
+	content := 0 // Index of the first content token.
+	flush := func(end int) {
+		children := treeNodes(body[content:end])
+		if arm == -1 {
+			nodes = append(nodes, children...)
+			return
+		}
+		nodes = append(nodes, Node{Token: body[arm], Children: children})
+	}
+	for i := 0; i < len(body); i++ {
+		switch body[i].Type {
+		case TokenTypeCardinalPluralStart:
+			i = pluralEnd(body, i)
+		case TokenTypePluralExactMatch, TokenTypePluralOther:
+			flush(i)
+			arm, content = i, i+1
+		}
+	}
+	flush(len(body))
+	return nodes
+}
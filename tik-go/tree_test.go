@@ -0,0 +1,100 @@
+package tik_test
+
+import (
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+type Node struct {
+	Str      string
+	Type     tik.TokenType
+	Children []Node
+}
+
+func toTestNodes(input string, nodes []tik.Node) []Node {
+	var r []Node
+	for _, n := range nodes {
+		r = append(r, Node{
+			Str:      n.String(input),
+			Type:     n.Type,
+			Children: toTestNodes(input, n.Children),
+		})
+	}
+	return r
+}
+
+func TestTIKTree(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.AllowNestedPlural = true
+	conf.MarkupTags = []string{"b"}
+	p := tik.NewParser(conf)
+
+	f := func(t *testing.T, input string, expect ...Node) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		requireDeepEqual(t, expect, toTestNodes(input, tk.Tree()))
+	}
+
+	f(t, `{# messages from {text}} on {date-short}`,
+		Node{"{#", tik.TokenTypeCardinalPluralStart, []Node{
+			{" messages from ", tik.TokenTypeLiteral, nil},
+			{"{text}", tik.TokenTypeText, nil},
+		}},
+		Node{" on ", tik.TokenTypeLiteral, nil},
+		Node{"{date-short}", tik.TokenTypeDateShort, nil},
+	)
+	f(t, `[inbox] Hello {<b>}{name}{</b>}`,
+		Node{"[inbox]", tik.TokenTypeContext, nil},
+		Node{"Hello ", tik.TokenTypeLiteral, nil},
+		Node{"{<b>}", tik.TokenTypeTagOpen, nil},
+		Node{"{name}", tik.TokenTypeTextWithGender, nil},
+		Node{"{</b>}", tik.TokenTypeTagClose, nil},
+	)
+	f(t, `{# offset:1 |=0 nobody |=1 {name} | others and {name}}!`,
+		Node{"{#", tik.TokenTypeCardinalPluralStart, []Node{
+			{"offset:1", tik.TokenTypePluralOffset, nil},
+			{"|=0", tik.TokenTypePluralExactMatch, []Node{
+				{"nobody", tik.TokenTypeLiteral, nil},
+			}},
+			{"|=1", tik.TokenTypePluralExactMatch, []Node{
+				{"{name}", tik.TokenTypeTextWithGender, nil},
+			}},
+			{"|", tik.TokenTypePluralOther, []Node{
+				{" others and ", tik.TokenTypeLiteral, nil},
+				{"{name}", tik.TokenTypeTextWithGender, nil},
+			}},
+		}},
+		Node{"!", tik.TokenTypeLiteral, nil},
+	)
+	f(t, `{# |=0 nothing | files in {# |=1 one folder | folders}} total`,
+		Node{"{#", tik.TokenTypeCardinalPluralStart, []Node{
+			{"|=0", tik.TokenTypePluralExactMatch, []Node{
+				{"nothing", tik.TokenTypeLiteral, nil},
+			}},
+			{"|", tik.TokenTypePluralOther, []Node{
+				{" files in ", tik.TokenTypeLiteral, nil},
+				{"{#", tik.TokenTypeCardinalPluralStart, []Node{
+					{"|=1", tik.TokenTypePluralExactMatch, []Node{
+						{"one folder", tik.TokenTypeLiteral, nil},
+					}},
+					{"|", tik.TokenTypePluralOther, []Node{
+						{" folders", tik.TokenTypeLiteral, nil},
+					}},
+				}},
+			}},
+		}},
+		Node{" total", tik.TokenTypeLiteral, nil},
+	)
+	f(t, `{# files in {# folders}}`,
+		Node{"{#", tik.TokenTypeCardinalPluralStart, []Node{
+			{" files in ", tik.TokenTypeLiteral, nil},
+			{"{#", tik.TokenTypeCardinalPluralStart, []Node{
+				{" folders", tik.TokenTypeLiteral, nil},
+			}},
+		}},
+	)
+}
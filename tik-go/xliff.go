@@ -0,0 +1,136 @@
+package tik
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var ErrXLIFFInvalidID = errors.New("invalid XLIFF unit identifier")
+
+// XLIFFTranslator is a reusable TIK to XLIFF 2.0 `<unit>` translator.
+//
+// The structure of the TIK is preserved in the unit's source: placeholders
+// and message references become undeletable `<ph>` elements and cardinal
+// pluralizations become undeletable `<pc>` elements spanning their content.
+// Pluralization offsets and arm selectors become `<ph>` elements
+// inside of the `<pc>`. Markup tags become `<pc>` elements of type "fmt"
+// and self-closing tags `<ph>` elements with ids `tag0`, `tag1`, ...
+// in order of occurrence. Placeholder ids are derived from positional
+// argument names (`var0`, `var1`, ...) as assigned by ICUTranslator.Visit
+// using Config.ICUVarPrefix, which keeps them stable across exports
+// of the same TIK. The context becomes a note of category "context".
+type XLIFFTranslator struct {
+	conf Config
+	b    strings.Builder
+}
+
+func NewXLIFFTranslator(conf Config) *XLIFFTranslator {
+	return &XLIFFTranslator{conf: conf}
+}
+
+// TIK2XLIFF translates tik into an XLIFF 2.0 unit with the given id.
+// Returns ErrXLIFFInvalidID if id isn't a valid XML NMTOKEN.
+func (x *XLIFFTranslator) TIK2XLIFF(tik TIK, id string) (string, error) {
+	if !isNMTOKEN(id) {
+		return "", ErrXLIFFInvalidID
+	}
+	x.b.Reset()
+	x.b.WriteString(`<unit id="` + id + `">` + "\n")
+	if c := tik.Context(); c != "" {
+		x.b.WriteString("  <notes>\n")
+		x.b.WriteString(`    <note category="context">`)
+		x.b.WriteString(replacerEscapeXLIFF.Replace(c))
+		x.b.WriteString("</note>\n")
+		x.b.WriteString("  </notes>\n")
+	}
+	x.b.WriteString("  <segment>\n    <source>")
+
+	pos, refs, tags, raws := 0, 0, 0, 0
+	// plurals are the ids of the enclosing pc elements
+	// and the number of arms written.
+	type plural struct {
+		id   string
+		arms int
+	}
+	var plurals []plural
+	for _, t := range tik.Tokens {
+		switch t.Type {
+		case TokenTypeContext:
+		case TokenTypeLiteral:
+			x.b.WriteString(replacerEscapeXLIFF.Replace(t.String(tik.Raw)))
+		case TokenTypeCardinalPluralStart:
+			plurals = append(plurals, plural{id: x.conf.argName(pos)})
+			x.b.WriteString(`<pc id="` + x.conf.argName(pos) + `" dispStart="`)
+			pos++
+			x.b.WriteString(replacerEscapeXLIFF.Replace(tik.Raw[t.IndexStart:t.IndexEnd]))
+			x.b.WriteString(`" dispEnd="}" canDelete="no" type="ui" subType="xlf:var">`)
+		case TokenTypeCardinalPluralEnd:
+			plurals = plurals[:len(plurals)-1]
+			x.b.WriteString("</pc>")
+		case TokenTypePluralOffset:
+			p := plurals[len(plurals)-1]
+			x.writePH(p.id+"-offset", tik.Raw[t.IndexStart:t.IndexEnd], "")
+		case TokenTypePluralExactMatch, TokenTypePluralOther:
+			p := &plurals[len(plurals)-1]
+			x.writePH(p.id+"-arm"+strconv.Itoa(p.arms),
+				tik.Raw[t.IndexStart:t.IndexEnd], "")
+			p.arms++
+		case TokenTypeTagOpen:
+			x.b.WriteString(`<pc id="tag` + strconv.Itoa(tags) + `" dispStart="`)
+			x.b.WriteString(replacerEscapeXLIFF.Replace(tik.Raw[t.IndexStart:t.IndexEnd]))
+			x.b.WriteString(`" dispEnd="`)
+			x.b.WriteString(replacerEscapeXLIFF.Replace("{</" + t.Value + ">}"))
+			x.b.WriteString(`" canDelete="no" type="fmt">`)
+			tags++
+		case TokenTypeTagClose:
+			x.b.WriteString("</pc>")
+		case TokenTypeTagSelfClosing:
+			x.writePH("tag"+strconv.Itoa(tags), tik.Raw[t.IndexStart:t.IndexEnd], "")
+			tags++
+		case TokenTypeMessageRef:
+			x.writePH("ref"+strconv.Itoa(refs), tik.Raw[t.IndexStart:t.IndexEnd], "")
+			refs++
+		case TokenTypeRawICU:
+			x.writePH("icu"+strconv.Itoa(raws), tik.Raw[t.IndexStart:t.IndexEnd], "")
+			raws++
+		default:
+			x.writePH(x.conf.argName(pos), tik.Raw[t.IndexStart:t.IndexEnd], "xlf:var")
+			pos += t.Type.argCount()
+		}
+	}
+
+	x.b.WriteString("</source>\n  </segment>\n</unit>")
+	return x.b.String(), nil
+}
+
+func (x *XLIFFTranslator) writePH(id, disp, subType string) {
+	x.b.WriteString(`<ph id="` + id + `" disp="`)
+	x.b.WriteString(replacerEscapeXLIFF.Replace(disp))
+	x.b.WriteString(`" canDelete="no"`)
+	if subType != "" {
+		x.b.WriteString(` type="ui" subType="` + subType + `"`)
+	}
+	x.b.WriteString("/>")
+}
+
+var replacerEscapeXLIFF = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;",
+)
+
+// isNMTOKEN returns true if s is a valid XML name token.
+func isNMTOKEN(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r),
+			r == '.', r == '-', r == '_', r == ':', r == '·':
+		default:
+			return false
+		}
+	}
+	return true
+}
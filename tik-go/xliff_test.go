@@ -0,0 +1,241 @@
+package tik_test
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	tik "github.com/romshark/tik/tik-go"
+)
+
+func TestXLIFFTranslator(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+	translator := tik.NewXLIFFTranslator(tik.DefaultConfig)
+
+	f := func(t *testing.T, expect, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		actual, err := translator.TIK2XLIFF(tk, "msg")
+		requireNoErr(t, err)
+		requireEqual(t, expect, actual)
+	}
+
+	f(t, `<unit id="msg">
+  <segment>
+    <source>a &lt;b&gt; &amp; &quot;c&quot;</source>
+  </segment>
+</unit>`, `a <b> & "c"`)
+
+	f(t, `<unit id="msg">
+  <notes>
+    <note category="context">greeting &amp; farewell</note>
+  </notes>
+  <segment>
+    <source>Hello <ph id="var0" disp="{name}" canDelete="no" type="ui" subType="xlf:var"/>!</source>
+  </segment>
+</unit>`, `[greeting & farewell] Hello {name}!`)
+
+	f(t, `<unit id="msg">
+  <segment>
+    <source><ph id="var0" disp="{text}" canDelete="no" type="ui" subType="xlf:var"/> `+
+		`had <pc id="var1" dispStart="{#" dispEnd="}" canDelete="no" type="ui" subType="xlf:var">`+
+		`<ph id="var1-offset" disp="offset:1" canDelete="no"/>`+
+		`<ph id="var1-arm0" disp="|=0" canDelete="no"/>nothing`+
+		`<ph id="var1-arm1" disp="|" canDelete="no"/> messages from `+
+		`<ph id="var2" disp="{date-short}" canDelete="no" type="ui" subType="xlf:var"/></pc>, `+
+		`see <ph id="ref0" disp="{@help}" canDelete="no"/></source>
+  </segment>
+</unit>`, `{text} had {# offset:1 |=0 nothing | messages from {date-short}}, see {@help}`)
+}
+
+func TestXLIFFTranslatorICUVarPrefix(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.ICUVarPrefix = "arg"
+	tk, err := tik.NewParser(conf).Parse(`{text} had {# messages}`)
+	requireNoErr(t, err)
+	actual, err := tik.NewXLIFFTranslator(conf).TIK2XLIFF(tk, "msg")
+	requireNoErr(t, err)
+	requireEqual(t, `<unit id="msg">
+  <segment>
+    <source><ph id="arg0" disp="{text}" canDelete="no" type="ui" subType="xlf:var"/> `+
+		`had <pc id="arg1" dispStart="{#" dispEnd="}" canDelete="no" type="ui" subType="xlf:var">`+
+		` messages</pc></source>
+  </segment>
+</unit>`, actual)
+}
+
+func TestXLIFFTranslatorErr(t *testing.T) {
+	t.Parallel()
+
+	translator := tik.NewXLIFFTranslator(tik.DefaultConfig)
+	tk, err := tik.NewParser(tik.DefaultConfig).Parse(`text`)
+	requireNoErr(t, err)
+
+	for _, id := range []string{"", "my msg", "a<b", `"msg"`} {
+		actual, err := translator.TIK2XLIFF(tk, id)
+		requireErrIs(t, tik.ErrXLIFFInvalidID, err)
+		requireEqual(t, "", actual)
+	}
+}
+
+// xliffDoc mirrors the subset of the XLIFF 2.0 core schema used by units.
+type xliffDoc struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:xliff:document:2.0 xliff"`
+	Version string   `xml:"version,attr"`
+	SrcLang string   `xml:"srcLang,attr"`
+	File    struct {
+		ID   string `xml:"id,attr"`
+		Unit struct {
+			ID    string `xml:"id,attr"`
+			Notes *struct {
+				Note []struct {
+					Category string `xml:"category,attr"`
+					Text     string `xml:",chardata"`
+				} `xml:"note"`
+			} `xml:"notes"`
+			Segment []struct {
+				Source xliffInline `xml:"source"`
+			} `xml:"segment"`
+		} `xml:"unit"`
+	} `xml:"file"`
+}
+
+type xliffInline struct {
+	Text string     `xml:",chardata"`
+	PH   []xliffPH  `xml:"ph"`
+	PC   []xliffPC  `xml:"pc"`
+	Any  []xml.Name `xml:",any"`
+}
+
+type xliffPH struct {
+	ID        string `xml:"id,attr"`
+	Disp      string `xml:"disp,attr"`
+	CanDelete string `xml:"canDelete,attr"`
+}
+
+type xliffPC struct {
+	xliffInline
+	ID        string `xml:"id,attr"`
+	DispStart string `xml:"dispStart,attr"`
+	DispEnd   string `xml:"dispEnd,attr"`
+	CanDelete string `xml:"canDelete,attr"`
+}
+
+// collectIDs collects the ids of all inline elements and
+// reports inline elements that aren't allowed by the schema.
+func (in xliffInline) collectIDs(t *testing.T, ids []string) []string {
+	t.Helper()
+	for _, n := range in.Any {
+		t.Errorf("unexpected inline element: %q", n.Local)
+	}
+	for _, ph := range in.PH {
+		requireEqual(t, "no", ph.CanDelete)
+		ids = append(ids, ph.ID)
+	}
+	for _, pc := range in.PC {
+		requireEqual(t, "no", pc.CanDelete)
+		ids = append(ids, pc.ID)
+		ids = pc.collectIDs(t, ids)
+	}
+	return ids
+}
+
+func TestXLIFFTranslatorMarkupTags(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.MarkupTags = []string{"a", "br"}
+	tk, err := tik.NewParser(conf).Parse(`Click {<a>}here{</a>}{<br/>}`)
+	requireNoErr(t, err)
+	actual, err := tik.NewXLIFFTranslator(tik.DefaultConfig).TIK2XLIFF(tk, "msg")
+	requireNoErr(t, err)
+	requireEqual(t, `<unit id="msg">
+  <segment>
+    <source>Click `+
+		`<pc id="tag0" dispStart="{&lt;a&gt;}" dispEnd="{&lt;/a&gt;}" canDelete="no" type="fmt">`+
+		`here</pc><ph id="tag1" disp="{&lt;br/&gt;}" canDelete="no"/></source>
+  </segment>
+</unit>`, actual)
+}
+
+func TestXLIFFTranslatorNestedPlural(t *testing.T) {
+	t.Parallel()
+
+	conf := tik.DefaultConfig
+	conf.AllowNestedPlural = true
+	tk, err := tik.NewParser(conf).Parse(`{# |=0 none |=1 one {# x} | more}`)
+	requireNoErr(t, err)
+	actual, err := tik.NewXLIFFTranslator(tik.DefaultConfig).TIK2XLIFF(tk, "msg")
+	requireNoErr(t, err)
+	requireEqual(t, `<unit id="msg">
+  <segment>
+    <source>`+
+		`<pc id="var0" dispStart="{#" dispEnd="}" canDelete="no" type="ui" subType="xlf:var">`+
+		`<ph id="var0-arm0" disp="|=0" canDelete="no"/>none`+
+		`<ph id="var0-arm1" disp="|=1" canDelete="no"/>one `+
+		`<pc id="var1" dispStart="{#" dispEnd="}" canDelete="no" type="ui" subType="xlf:var">`+
+		` x</pc>`+
+		`<ph id="var0-arm2" disp="|" canDelete="no"/> more</pc></source>
+  </segment>
+</unit>`, actual)
+}
+
+func TestXLIFFTranslatorSchema(t *testing.T) {
+	t.Parallel()
+
+	p := tik.NewParser(tik.DefaultConfig)
+
+	f := func(t *testing.T, expectIDs []string, input string) {
+		t.Helper()
+		tk, err := p.Parse(input)
+		requireNoErr(t, err)
+		unit, err := tik.NewXLIFFTranslator(tik.DefaultConfig).TIK2XLIFF(tk, "msg")
+		requireNoErr(t, err)
+
+		// Exporting again must produce the same ids.
+		again, err := tik.NewXLIFFTranslator(tik.DefaultConfig).TIK2XLIFF(tk, "msg")
+		requireNoErr(t, err)
+		requireEqual(t, unit, again)
+
+		doc := `<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" ` +
+			`version="2.0" srcLang="en"><file id="f1">` + unit + `</file></xliff>`
+		d := xml.NewDecoder(strings.NewReader(doc))
+		d.Strict = true
+		var x xliffDoc
+		requireNoErr(t, d.Decode(&x))
+		requireEqual(t, "2.0", x.Version)
+		requireEqual(t, "msg", x.File.Unit.ID)
+		requireEqual(t, 1, len(x.File.Unit.Segment))
+		if c := tk.Context(); c != "" {
+			requireEqual(t, "context", x.File.Unit.Notes.Note[0].Category)
+			requireEqual(t, c, x.File.Unit.Notes.Note[0].Text)
+		}
+
+		ids := x.File.Unit.Segment[0].Source.collectIDs(t, nil)
+		seen := map[string]bool{}
+		for _, id := range ids {
+			if id == "" || seen[id] {
+				t.Errorf("missing or duplicate id: %q", id)
+			}
+			seen[id] = true
+		}
+		requireEqual(t, len(expectIDs), len(seen))
+		for _, id := range expectIDs {
+			if !seen[id] {
+				t.Errorf("missing id %q", id)
+			}
+		}
+	}
+
+	f(t, nil, `just text`)
+	f(t, []string{"var0", "var1"}, `[ctx] {name} and {date-full}`)
+	f(t, []string{"var0", "var1", "var1-offset", "var1-arm0", "var1-arm1", "var2", "ref0"},
+		`{text} had {# offset:1 |=0 nothing | messages from {date-short}}, see {@help}`)
+	f(t, []string{"var0", "var0-arm0", "var0-arm1", "var1", "var2"},
+		`{# |=1 one | many} and {integer} {# items}`)
+}